@@ -0,0 +1,84 @@
+// Package age decrypts inline age encrypted config values for configwise.
+// It is a separate package so that only applications decrypting values link
+// the age library:
+//
+//	c, err := configwise.NewConfigurer(age.WithIdentity(identity))
+//
+// NewScheme returns the scheme for use with configwise.WithValueScheme.
+package age
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+
+	"github.com/gowool/configwise"
+)
+
+const (
+	prefix = "enc["
+	suffix = "]"
+)
+
+// WithIdentity decrypts the values written as enc[<ciphertext>] with the
+// identity, see NewScheme. An invalid identity fails NewConfigurer.
+func WithIdentity(identity string) configwise.Option {
+	scheme, err := NewScheme(identity)
+	if err != nil {
+		return configwise.WithOptionError(err)
+	}
+	return configwise.WithValueScheme(scheme)
+}
+
+// NewScheme returns the scheme of values written as enc[<ciphertext>], where
+// the ciphertext is an age file either base64 encoded or ASCII armored. The
+// identity holds one or more age secret keys (AGE-SECRET-KEY-1...) in the
+// format of an age identity file. Decrypted values are secrets.
+func NewScheme(identity string) (configwise.ValueScheme, error) {
+	identities, err := age.ParseIdentities(strings.NewReader(identity))
+	if err != nil {
+		return configwise.ValueScheme{}, fmt.Errorf("age identity: %w", err)
+	}
+
+	return configwise.ValueScheme{
+		Name:   "age",
+		Secret: true,
+		Match: func(s string) bool {
+			return strings.HasPrefix(s, prefix) && strings.HasSuffix(s, suffix)
+		},
+		Resolve: func(s string) (interface{}, error) {
+			return decrypt(strings.TrimSpace(s[len(prefix):len(s)-len(suffix)]), identities)
+		},
+	}, nil
+}
+
+// decrypt decrypts the base64 encoded or armored ciphertext.
+func decrypt(payload string, identities []age.Identity) (string, error) {
+	var src io.Reader
+	if strings.HasPrefix(payload, armor.Header) {
+		src = armor.NewReader(strings.NewReader(payload))
+	} else {
+		data, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return "", errors.New("ciphertext is neither base64 encoded nor armored")
+		}
+		src = bytes.NewReader(data)
+	}
+
+	r, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return "", err
+	}
+
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,122 @@
+package age
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+
+	"github.com/gowool/configwise"
+)
+
+// encrypt returns the plaintext encrypted to the recipient, armored or
+// base64 encoded.
+func encrypt(t *testing.T, recipient age.Recipient, plain string, armored bool) string {
+	t.Helper()
+	var buf bytes.Buffer
+	var dst io.Writer = &buf
+	var a io.WriteCloser
+	if armored {
+		a = armor.NewWriter(&buf)
+		dst = a
+	}
+	w, err := age.Encrypt(dst, recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = io.WriteString(w, plain); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if armored {
+		if err = a.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestNewScheme(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scheme, err := NewScheme("# key\n" + identity.String() + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		want  string
+		err   string
+	}{
+		{name: "base64", value: "enc[" + encrypt(t, identity.Recipient(), "s3cret", false) + "]", want: "s3cret"},
+		{name: "armored", value: "enc[" + encrypt(t, identity.Recipient(), "s3cret", true) + "]", want: "s3cret"},
+		{name: "plain", value: "s3cret", want: "s3cret"},
+		{name: "other recipient", value: "enc[" + encrypt(t, other.Recipient(), "s3cret", false) + "]", err: "db.password: age: no identity matched"},
+		{name: "malformed", value: "enc[not base64!]", err: "db.password: age: ciphertext is neither base64 encoded nor armored"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := configwise.NewConfigurer(
+				configwise.WithValueScheme(scheme),
+				configwise.WithType("json"),
+				configwise.WithReadInConfig([]byte(`{"db": {"password": `+strconv.Quote(tt.value)+`}}`)),
+			)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error is %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.GetString("db.password"); got != tt.want {
+				t.Errorf("db.password is %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewScheme("AGE-SECRET-KEY-1INVALID"); err == nil || !strings.HasPrefix(err.Error(), "age identity: ") {
+		t.Errorf("error is %v, want an invalid identity", err)
+	}
+}
+
+func TestWithIdentity(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := configwise.NewConfigurer(
+		WithIdentity(identity.String()),
+		configwise.WithConfigMap(map[string]interface{}{"token": "enc[" + encrypt(t, identity.Recipient(), "t0ken", false) + "]"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.GetString("token"); got != "t0ken" {
+		t.Errorf("token is %q, want t0ken", got)
+	}
+
+	_, err = configwise.NewConfigurer(WithIdentity("AGE-SECRET-KEY-1INVALID"))
+	if err == nil || !strings.Contains(err.Error(), "new -> age identity: ") {
+		t.Errorf("error is %v, want an invalid identity", err)
+	}
+}
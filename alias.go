@@ -0,0 +1,57 @@
+package configwise
+
+import "sync"
+
+// aliases maps alternative keys to canonical keys.
+type aliases struct {
+	mu   sync.RWMutex
+	keys map[string]string
+}
+
+// RegisterAlias makes the section or value of the canonical key addressable
+// under alias as well, e.g. during the rename of a section. Reads of the
+// alias, or of keys below it, return the values of the canonical key, and
+// Overwrite of the alias changes the canonical key. Aliases creating a cycle
+// are ignored.
+func (cfg *configurer) RegisterAlias(alias, canonical string) {
+	alias = cfg.renameDeprecated(cfg.normalizeKey(alias))
+	canonical = cfg.canonicalKey(canonical)
+	if alias == "" || canonical == "" || covers(alias, canonical) {
+		return
+	}
+
+	a := &cfg.aliases
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.keys == nil {
+		a.keys = make(map[string]string)
+	}
+	a.keys[alias] = canonical
+}
+
+// resolveAlias returns the key with an alias, or an aliased parent, replaced
+// by the canonical key.
+func (cfg *configurer) resolveAlias(key string) string {
+	a := &cfg.aliases
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if len(a.keys) == 0 {
+		return key
+	}
+	// aliases may refer to keys which became aliases later
+	for range len(a.keys) {
+		resolved := false
+		for k := key; k != ""; k = parentKey(k) {
+			if canonical, ok := a.keys[k]; ok {
+				key, resolved = canonical+key[len(k):], true
+				break
+			}
+		}
+		if !resolved {
+			break
+		}
+	}
+	return key
+}
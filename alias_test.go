@@ -0,0 +1,45 @@
+package configwise
+
+import "testing"
+
+func TestRegisterAlias(t *testing.T) {
+	c, err := NewConfigurer(WithType("yaml"), WithReadInConfig([]byte("http: {address: ':80', timeout: 5s}\nname: app")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.RegisterAlias("Server", "http")
+	c.RegisterAlias("listen", "server.address")
+	c.RegisterAlias("title", "name")
+	// cycles are ignored
+	c.RegisterAlias("http", "server")
+	c.RegisterAlias("name.first", "name")
+
+	tests := []struct {
+		name string
+		key  string
+		want interface{}
+	}{
+		{name: "canonical key", key: "http.address", want: ":80"},
+		{name: "alias of a value", key: "title", want: "app"},
+		{name: "key below an alias", key: "server.timeout", want: "5s"},
+		{name: "alias of an alias", key: "listen", want: ":80"},
+		{name: "missing key below an alias", key: "server.missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Get(tt.key); got != tt.want {
+				t.Errorf("%s is %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+
+	if err := c.Overwrite(map[string]interface{}{"server.address": ":8080"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.GetString("http.address"); got != ":8080" {
+		t.Errorf("http.address is %q after an overwrite of the alias, want :8080", got)
+	}
+	if _, ok := c.Settings()["server"]; ok {
+		t.Errorf("settings are %v, want no section of the alias", c.Settings())
+	}
+}
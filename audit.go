@@ -0,0 +1,162 @@
+package configwise
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+type (
+	actorKey     struct{}
+	requestIDKey struct{}
+)
+
+// ContextWithActor returns a context carrying the identity of the caller
+// which is recorded in audit entries of runtime changes.
+func ContextWithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor stored with ContextWithActor.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// ContextWithRequestID returns a context carrying the ID of the request
+// which is recorded in audit entries of runtime changes.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored with ContextWithRequestID.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// AuditEntry records a runtime change of the configuration.
+type AuditEntry struct {
+	Time time.Time
+	// Op is the operation, e.g. "overwrite".
+	Op string
+	// Keys lists the affected keys. Values are not recorded as they may be secrets.
+	Keys      []string
+	Actor     string
+	RequestID string
+}
+
+// WithAuditLog passes an entry for every runtime change to fn. Actor and
+// request ID are taken from the context of context-aware calls such as
+// OverwriteContext.
+func WithAuditLog(fn func(AuditEntry)) Option {
+	return func(c *configurer) {
+		c.audit = append(c.audit, fn)
+	}
+}
+
+func (cfg *configurer) auditChange(ctx context.Context, op string, values map[string]interface{}) {
+	if len(cfg.audit) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, strings.ToLower(key))
+	}
+	sort.Strings(keys)
+
+	entry := AuditEntry{
+		Time:      time.Now(),
+		Op:        op,
+		Keys:      keys,
+		Actor:     ActorFromContext(ctx),
+		RequestID: RequestIDFromContext(ctx),
+	}
+	for _, fn := range cfg.audit {
+		fn(entry)
+	}
+}
+
+// AccessEntry records a read of the configuration.
+type AccessEntry struct {
+	Time time.Time
+	// Op is the operation, "get", "unmarshal_key", "unmarshal", "values" or "settings".
+	Op string
+	// Key is the key read, empty for the whole configuration.
+	Key string
+	// Secret reports whether the value read is or contains a secret.
+	Secret bool
+	// Caller is the function and location of the call, e.g. "main.run (main.go:42)".
+	Caller string
+}
+
+// WithAccessLog passes an entry for every call of Get, UnmarshalKey,
+// Unmarshal, Values and Settings to fn, so security reviews can see which secrets a service
+// actually touches. Access auditing is opt-in as it costs a stack lookup per read.
+func WithAccessLog(fn func(AccessEntry)) Option {
+	return func(c *configurer) {
+		c.access = append(c.access, fn)
+	}
+}
+
+// auditAccess must be called directly by the exported method, so that the
+// caller of the method is recorded.
+func (cfg *configurer) auditAccess(op, key string, value interface{}) {
+	if len(cfg.access) == 0 {
+		return
+	}
+
+	entry := AccessEntry{
+		Time:   time.Now(),
+		Op:     op,
+		Key:    key,
+		Secret: cfg.containsSecret(key, value),
+	}
+	if pc, file, line, ok := runtime.Caller(2); ok {
+		entry.Caller = fmt.Sprintf("%s:%d", filepath.Base(file), line)
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			entry.Caller = fmt.Sprintf("%s (%s)", fn.Name(), entry.Caller)
+		}
+	}
+
+	for _, fn := range cfg.access {
+		fn(entry)
+	}
+}
+
+// containsSecret reports whether the key or one of the keys of the value below it is a secret.
+func (cfg *configurer) containsSecret(key string, value interface{}) bool {
+	cfg.mu.RLock()
+	secrets := cfg.secrets
+	cfg.mu.RUnlock()
+
+	if key != "" && cfg.isSecretKey(key, secrets) {
+		return true
+	}
+
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for _, leaf := range leafKeys(m) {
+		if key != "" {
+			leaf = key + keyDelimiter + leaf
+		}
+		if cfg.isSecretKey(leaf, secrets) {
+			return true
+		}
+	}
+
+	prefix := key + keyDelimiter
+	for k := range secrets {
+		if key == "" || strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,258 @@
+package configwise
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Probe inspects the environment and proposes configuration values.
+type Probe interface {
+	Probe(ctx context.Context) (map[string]interface{}, error)
+}
+
+// ProbeFunc adapts a function to the Probe interface.
+type ProbeFunc func(ctx context.Context) (map[string]interface{}, error)
+
+func (fn ProbeFunc) Probe(ctx context.Context) (map[string]interface{}, error) {
+	return fn(ctx)
+}
+
+// probeTimeout limits the duration of all probes of a bootstrap.
+const probeTimeout = 5 * time.Second
+
+// Bootstrap proposes a starter config as YAML document. It starts with the
+// values of the sample struct and overlays the values proposed by the probes,
+// which are applied in order. Probes reporting an error are skipped, as a
+// starter config is useful even when parts of the environment are unknown.
+func Bootstrap(sample interface{}, probes ...Probe) ([]byte, error) {
+	tree := make(map[string]interface{})
+	if sample != nil {
+		m, ok := structToMap(reflect.ValueOf(sample)).(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("bootstrap: sample must be a struct, got %T", sample)
+		}
+		tree = m
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	for _, probe := range probes {
+		values, err := probe.Probe(ctx)
+		if err != nil {
+			continue
+		}
+		for key, value := range values {
+			setPath(tree, strings.ToLower(key), value)
+		}
+	}
+
+	return yaml.Marshal(tree)
+}
+
+// WriteBootstrap writes the config proposed by Bootstrap to path. An
+// existing file is never overwritten.
+func WriteBootstrap(path string, sample interface{}, probes ...Probe) error {
+	data, err := Bootstrap(sample, probes...)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("bootstrap: %w", err)
+	}
+
+	_, err = f.Write(data)
+	return errors.Join(err, f.Close())
+}
+
+// PortProbe proposes the preferred port for key when it is free, otherwise
+// a free port chosen by the operating system.
+func PortProbe(key string, preferred int) Probe {
+	return ProbeFunc(func(context.Context) (map[string]interface{}, error) {
+		l, err := net.Listen("tcp", ":"+strconv.Itoa(preferred))
+		if err != nil {
+			if l, err = net.Listen("tcp", ":0"); err != nil {
+				return nil, err
+			}
+		}
+		defer l.Close()
+
+		return map[string]interface{}{key: l.Addr().(*net.TCPAddr).Port}, nil
+	})
+}
+
+// ResourcesProbe proposes the number of CPUs as <prefix>.cpus and the
+// hostname as <prefix>.hostname.
+func ResourcesProbe(prefix string) Probe {
+	return ProbeFunc(func(context.Context) (map[string]interface{}, error) {
+		values := map[string]interface{}{prefix + ".cpus": runtime.NumCPU()}
+		if hostname, err := os.Hostname(); err == nil {
+			values[prefix+".hostname"] = hostname
+		}
+		return values, nil
+	})
+}
+
+// CloudProbe queries the instance metadata services of AWS and Google Cloud and
+// proposes <prefix>.provider and <prefix>.region when running on one of them.
+func CloudProbe(prefix string) Probe {
+	return ProbeFunc(func(ctx context.Context) (map[string]interface{}, error) {
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+
+		if region, err := awsRegion(ctx); err == nil {
+			return map[string]interface{}{prefix + ".provider": "aws", prefix + ".region": region}, nil
+		}
+		if zone, err := metadataGet(ctx, "http://metadata.google.internal/computeMetadata/v1/instance/zone", http.Header{"Metadata-Flavor": {"Google"}}); err == nil {
+			// projects/<number>/zones/<region>-<zone>
+			zone = zone[strings.LastIndex(zone, "/")+1:]
+			region := zone
+			if idx := strings.LastIndex(zone, "-"); idx != -1 {
+				region = zone[:idx]
+			}
+			return map[string]interface{}{prefix + ".provider": "gcp", prefix + ".region": region}, nil
+		}
+		return nil, errors.New("no cloud metadata service found")
+	})
+}
+
+func awsRegion(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, "http://169.254.169.254/latest/api/token", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "60")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	token, err := readBody(resp)
+	if err != nil {
+		return "", err
+	}
+
+	doc, err := metadataGet(ctx, "http://169.254.169.254/latest/dynamic/instance-identity/document", http.Header{"X-aws-ec2-metadata-token": {token}})
+	if err != nil {
+		return "", err
+	}
+
+	var identity struct {
+		Region string `json:"region"`
+	}
+	if err = json.Unmarshal([]byte(doc), &identity); err != nil {
+		return "", err
+	}
+	return identity.Region, nil
+}
+
+func metadataGet(ctx context.Context, url string, header http.Header) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header = header
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	return readBody(resp)
+}
+
+func readBody(resp *http.Response) (string, error) {
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// structToMap converts a config struct into a tree using the config keys of
+// its fields. Values which marshal to text, like durations and UUIDs, are
+// represented as strings. Nil pointers to structs are expanded to their zero value.
+func structToMap(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			if v.Kind() == reflect.Pointer && isStruct(v.Type()) {
+				v = reflect.New(v.Type().Elem()).Elem()
+				continue
+			}
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		return v.Interface().(time.Duration).String()
+	}
+	if v.CanInterface() {
+		if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+			if text, err := m.MarshalText(); err == nil {
+				return string(text)
+			}
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		tree := make(map[string]interface{})
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, squash := fieldName(field)
+			if name == "" {
+				continue
+			}
+			value := structToMap(v.Field(i))
+			if nested, ok := value.(map[string]interface{}); ok && squash {
+				deepMerge(tree, nested)
+				continue
+			}
+			tree[name] = value
+		}
+		return tree
+	case reflect.Map:
+		tree := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			tree[strings.ToLower(fmt.Sprint(iter.Key().Interface()))] = structToMap(iter.Value())
+		}
+		return tree
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return []interface{}{}
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = structToMap(v.Index(i))
+		}
+		return items
+	default:
+		return v.Interface()
+	}
+}
@@ -0,0 +1,87 @@
+package configwise
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func init() {
+	gob.Register(map[string]interface{}{})
+	gob.Register(map[interface{}]interface{}{})
+	gob.Register([]interface{}{})
+	gob.Register(time.Time{})
+}
+
+// WithCompiledCache stores the parsed trees of config documents in a compact
+// binary form in dir, keyed by the checksum of format and content. Unchanged
+// documents are loaded from the cache on subsequent startups instead of being
+// parsed, which cuts the cold-start time of very large configs. SOPS encrypted
+// documents are never cached, so no decrypted values are written to disk.
+// Failures to use the cache are ignored; delete the directory to clear it.
+func WithCompiledCache(dir string) Option {
+	return func(c *configurer) {
+		c.cacheDir = dir
+	}
+}
+
+// decodeCached parses the document, using the compiled cache if configured.
+func (cfg *configurer) decodeCached(format string, data []byte) (map[string]interface{}, error) {
+	if cfg.cacheDir == "" {
+		return decodeConfig(cfg.decoders, format, data)
+	}
+
+	// trees of registered decoders are not mixed up with built-in ones
+	key := format
+	if _, ok := lookupDecoder(cfg.decoders, format); ok {
+		key = "decoder:" + format
+	}
+	sum := sha256.Sum256(append([]byte(key+"\x00"), data...))
+	file := filepath.Join(cfg.cacheDir, hex.EncodeToString(sum[:])+".gob")
+
+	if cached, err := os.ReadFile(file); err == nil {
+		var tree map[string]interface{}
+		if err = gob.NewDecoder(bytes.NewReader(cached)).Decode(&tree); err == nil {
+			return tree, nil
+		}
+	}
+
+	tree, err := decodeConfig(cfg.decoders, format, data)
+	if err != nil || isSOPS(tree) {
+		return tree, err
+	}
+
+	writeCache(file, tree)
+	return tree, nil
+}
+
+// writeCache writes the tree atomically, so concurrent startups never read
+// a partial cache file.
+func writeCache(file string, tree map[string]interface{}) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(tree); err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".cache-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(buf.Bytes())
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), file)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
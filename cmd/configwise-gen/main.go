@@ -0,0 +1,452 @@
+// Command configwise-gen generates typed accessors for config structs.
+//
+// For a config struct it generates a snapshot type, which decodes the
+// configuration once and is refreshed on reloads and changes, and accessor
+// methods for every field, so values are read without reflection or
+// allocations at request time:
+//
+//	snapshot, err := NewConfigSnapshot(ctx, cfg)
+//	port := snapshot.HTTP().Port()
+//
+// Structs are selected with the -type flag or annotated with a
+// //configwise:accessors comment. Nested structs of the same package get
+// accessors of their own. Run it with go:generate in the package of the structs:
+//
+//	//go:generate go run github.com/gowool/configwise/cmd/configwise-gen -type Config
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// annotation marks a struct for which accessors are generated.
+const annotation = "//configwise:accessors"
+
+// reserved are the methods of the snapshot which fields must not shadow.
+var reserved = map[string]bool{"Load": true, "Refresh": true}
+
+func main() {
+	var (
+		typeNames = flag.String("type", "", "comma-separated list of config struct names; defaults to the annotated structs")
+		output    = flag.String("output", "", "output file name; defaults to <type>_accessors.go")
+	)
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: configwise-gen [flags] [directory]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	var names []string
+	if *typeNames != "" {
+		names = strings.Split(*typeNames, ",")
+	}
+	if err := run(dir, names, *output); err != nil {
+		fmt.Fprintln(os.Stderr, "configwise-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir string, names []string, output string) error {
+	pkg, err := parsePackage(dir)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		names = pkg.annotated
+	}
+	if len(names) == 0 {
+		return errors.New("no -type given and no struct annotated with " + annotation)
+	}
+
+	g := &generator{pkg: pkg, done: make(map[string]bool), imports: make(map[string]string)}
+	for _, name := range names {
+		if err = g.root(strings.TrimSpace(name)); err != nil {
+			return err
+		}
+	}
+
+	src, err := g.source()
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		output = strings.ToLower(strings.TrimSpace(names[0])) + "_accessors.go"
+	}
+	return os.WriteFile(filepath.Join(dir, output), src, 0o644)
+}
+
+// typeSpec is a struct type declared in the package.
+type typeSpec struct {
+	spec *ast.TypeSpec
+	// imports maps the names of the imports of the declaring file to their paths
+	imports map[string]string
+}
+
+type goPackage struct {
+	name      string
+	types     map[string]typeSpec
+	annotated []string
+}
+
+// parsePackage parses the Go files of the directory, except tests and
+// generated accessors.
+func parsePackage(dir string) (*goPackage, error) {
+	fset := token.NewFileSet()
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &goPackage{types: make(map[string]typeSpec)}
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") || strings.HasSuffix(file, "_accessors.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		if pkg.name == "" {
+			pkg.name = f.Name.Name
+		}
+
+		imports := make(map[string]string)
+		for _, spec := range f.Imports {
+			p, _ := strconv.Unquote(spec.Path.Value)
+			name := importName(p)
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			imports[name] = p
+		}
+
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if _, ok := ts.Type.(*ast.StructType); !ok || ts.TypeParams != nil {
+					continue
+				}
+				pkg.types[ts.Name.Name] = typeSpec{spec: ts, imports: imports}
+
+				doc := ts.Doc
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+				if doc != nil {
+					for _, c := range doc.List {
+						if strings.TrimSpace(c.Text) == annotation {
+							pkg.annotated = append(pkg.annotated, ts.Name.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	if pkg.name == "" {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+	return pkg, nil
+}
+
+// importName guesses the name of the imported package from its path, e.g.
+// "yaml" for "gopkg.in/yaml.v3" and "chi" for "github.com/go-chi/chi/v5".
+func importName(p string) string {
+	base := path.Base(p)
+	if len(base) > 1 && base[0] == 'v' && strings.Trim(base[1:], "0123456789") == "" {
+		base = path.Base(path.Dir(p))
+	}
+	base, _, _ = strings.Cut(base, ".")
+	return strings.ReplaceAll(base, "-", "_")
+}
+
+// field is a field of a struct for which an accessor is generated.
+type field struct {
+	name string
+	typ  ast.Expr
+	// owner is the struct declaring the field
+	owner string
+	// accessor is the accessor type of nested structs, empty for values
+	accessor string
+	pointer  bool
+}
+
+type generator struct {
+	pkg     *goPackage
+	buf     bytes.Buffer
+	done    map[string]bool
+	imports map[string]string
+}
+
+// root generates the snapshot of the named struct and its accessors.
+func (g *generator) root(name string) error {
+	if _, ok := g.pkg.types[name]; !ok {
+		return fmt.Errorf("struct %s not found in package %s", name, g.pkg.name)
+	}
+	if err := g.accessor(name); err != nil {
+		return err
+	}
+
+	fields, err := g.fields(name)
+	if err != nil {
+		return err
+	}
+	snapshot, accessor := name+"Snapshot", name+"Accessor"
+	g.imports["context"] = "context"
+	g.imports["atomic"] = "sync/atomic"
+	g.imports["configwise"] = "github.com/gowool/configwise"
+
+	fmt.Fprintf(&g.buf, `
+// %[1]s holds a decoded %[2]s which is refreshed whenever the
+// configuration is reloaded or changed. It is safe for concurrent use.
+type %[1]s struct {
+	v atomic.Pointer[%[2]s]
+}
+
+// New%[1]s decodes the configuration into a snapshot and keeps it up to date
+// until the context is done.
+func New%[1]s(ctx context.Context, c configwise.Configurer) (*%[1]s, error) {
+	s := new(%[1]s)
+	events := c.Subscribe(ctx, configwise.EventReloaded, configwise.EventKeyChanged, configwise.EventSecretRotated)
+	if err := s.Refresh(c); err != nil {
+		return nil, err
+	}
+	go func() {
+		for range events {
+			// a failed decode keeps the last valid snapshot
+			_ = s.Refresh(c)
+		}
+	}()
+	return s, nil
+}
+
+// Refresh decodes the configuration and replaces the snapshot.
+func (s *%[1]s) Refresh(c configwise.Configurer) error {
+	v := new(%[2]s)
+	if err := c.Unmarshal(v); err != nil {
+		return err
+	}
+	s.v.Store(v)
+	return nil
+}
+
+// Load returns the accessor of the current snapshot. Values read from one
+// accessor are consistent, even if the snapshot is refreshed meanwhile.
+func (s *%[1]s) Load() %[3]s {
+	return %[3]s{v: s.v.Load()}
+}
+`, snapshot, name, accessor)
+
+	for _, f := range fields {
+		if reserved[f.name] {
+			return fmt.Errorf("%s.%s: field shadows %s.%s", name, f.name, snapshot, f.name)
+		}
+		result := f.accessor
+		if result == "" {
+			result = g.typeString(f.owner, f.typ)
+		}
+		fmt.Fprintf(&g.buf, `
+// %[2]s returns %[2]s of the current snapshot.
+func (s *%[1]s) %[2]s() %[3]s {
+	return s.Load().%[2]s()
+}
+`, snapshot, f.name, result)
+	}
+	return nil
+}
+
+// accessor generates the accessor of the named struct and of the structs
+// nested in it.
+func (g *generator) accessor(name string) error {
+	if g.done[name] {
+		return nil
+	}
+	g.done[name] = true
+
+	fields, err := g.fields(name)
+	if err != nil {
+		return err
+	}
+	accessor := name + "Accessor"
+
+	fmt.Fprintf(&g.buf, `
+// %[1]s provides read-only access to a %[2]s of a snapshot. The zero
+// value reads zero values.
+type %[1]s struct {
+	v *%[2]s
+}
+`, accessor, name)
+
+	for _, f := range fields {
+		if f.accessor != "" {
+			ref := "&a.v." + f.name
+			if f.pointer {
+				ref = "a.v." + f.name
+			}
+			fmt.Fprintf(&g.buf, `
+// %[2]s returns the accessor of %[2]s.
+func (a %[1]s) %[2]s() %[3]s {
+	if a.v == nil {
+		return %[3]s{}
+	}
+	return %[3]s{v: %[4]s}
+}
+`, accessor, f.name, f.accessor, ref)
+			continue
+		}
+
+		doc := "// %[2]s returns the value of %[2]s.\n"
+		switch f.typ.(type) {
+		case *ast.ArrayType, *ast.MapType:
+			doc = "// %[2]s returns the value of %[2]s, which is shared with the snapshot\n// and must not be modified.\n"
+		}
+		fmt.Fprintf(&g.buf, "\n"+doc+`func (a %[1]s) %[2]s() %[3]s {
+	if a.v == nil {
+		var zero %[3]s
+		return zero
+	}
+	return a.v.%[2]s
+}
+`, accessor, f.name, g.typeString(f.owner, f.typ))
+	}
+
+	for _, f := range fields {
+		if f.accessor != "" {
+			if err = g.accessor(strings.TrimSuffix(f.accessor, "Accessor")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fields returns the exported fields of the named struct which are decoded,
+// including the fields promoted from embedded structs of the package.
+func (g *generator) fields(name string) ([]field, error) {
+	spec := g.pkg.types[name]
+	var fields []field
+	for _, f := range spec.spec.Type.(*ast.StructType).Fields.List {
+		if f.Tag != nil {
+			tag, _ := strconv.Unquote(f.Tag.Value)
+			if n, _, _ := strings.Cut(reflect.StructTag(tag).Get("cfg"), ","); n == "-" {
+				continue
+			}
+		}
+
+		typ, pointer := f.Type, false
+		if star, ok := typ.(*ast.StarExpr); ok {
+			typ, pointer = star.X, true
+		}
+		ident, _ := typ.(*ast.Ident)
+		_, local := g.pkg.types[nameOf(ident)]
+
+		if len(f.Names) == 0 {
+			if local && !pointer {
+				promoted, err := g.fields(ident.Name)
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, promoted...)
+			}
+			continue
+		}
+
+		for _, n := range f.Names {
+			if !n.IsExported() {
+				continue
+			}
+			fl := field{name: n.Name, typ: f.Type, owner: name, pointer: pointer}
+			if local {
+				fl.accessor = ident.Name + "Accessor"
+			}
+			fields = append(fields, fl)
+		}
+	}
+	return fields, nil
+}
+
+func nameOf(ident *ast.Ident) string {
+	if ident == nil {
+		return ""
+	}
+	return ident.Name
+}
+
+// typeString returns the type expression and records the imports it needs.
+func (g *generator) typeString(owner string, expr ast.Expr) string {
+	imports := g.pkg.types[owner].imports
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok {
+				if p, ok := imports[x.Name]; ok {
+					g.imports[x.Name] = p
+				}
+			}
+			return false
+		}
+		return true
+	})
+	return types.ExprString(expr)
+}
+
+// source returns the formatted source of the generated file.
+func (g *generator) source() ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by configwise-gen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", g.pkg.name)
+
+	names := make([]string, 0, len(g.imports))
+	for name := range g.imports {
+		names = append(names, name)
+	}
+	// standard packages first, like goimports
+	std := func(p string) bool { return !strings.Contains(strings.Split(p, "/")[0], ".") }
+	sort.Slice(names, func(i, j int) bool {
+		pi, pj := g.imports[names[i]], g.imports[names[j]]
+		if std(pi) != std(pj) {
+			return std(pi)
+		}
+		return pi < pj
+	})
+	for i, name := range names {
+		p := g.imports[name]
+		if i > 0 && std(g.imports[names[i-1]]) && !std(p) {
+			buf.WriteString("\n")
+		}
+		if name == importName(p) {
+			fmt.Fprintf(&buf, "\t%q\n", p)
+		} else {
+			fmt.Fprintf(&buf, "\t%s %q\n", name, p)
+		}
+	}
+	buf.WriteString(")\n")
+	buf.Write(g.buf.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated source: %w", err)
+	}
+	return src, nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	src, err := os.ReadFile(filepath.Join("testdata", "config.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "config.go"), src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = run(dir, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "config_accessors.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", "config_accessors.go.golden")
+	if *update {
+		if err = os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("generated source differs from %s, run go test -update to update it:\n%s", golden, got)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		types []string
+		err   string
+	}{
+		{
+			name: "no annotated struct",
+			src:  "package app\n\ntype Config struct{ Port int }\n",
+			err:  "no -type given and no struct annotated with //configwise:accessors",
+		},
+		{
+			name:  "unknown struct",
+			src:   "package app\n\ntype Config struct{ Port int }\n",
+			types: []string{"Settings"},
+			err:   "struct Settings not found in package app",
+		},
+		{
+			name:  "reserved field",
+			src:   "package app\n\ntype Config struct{ Load int }\n",
+			types: []string{"Config"},
+			err:   "Config.Load: field shadows ConfigSnapshot.Load",
+		},
+		{
+			name: "syntax error",
+			src:  "package app\n\ntype Config struct{\n",
+			err:  "config.go:3:21: expected '}', found 'EOF'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "config.go"), []byte(tt.src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			err := run(dir, tt.types, "")
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error is %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/gowool/configwise"
+)
+
+//configwise:accessors
+type Config struct {
+	Name  string
+	HTTP  HTTP
+	DB    *DB
+	Hooks []configwise.Option
+	Tags  map[string]string `cfg:"tags"`
+	Debug bool              `cfg:"-"`
+	Limits
+	internal int
+}
+
+type HTTP struct {
+	Port    int
+	Timeout time.Duration
+	Base    url.URL
+}
+
+type DB struct {
+	DSN, Driver string
+}
+
+type Limits struct {
+	RPS int
+}
@@ -0,0 +1,142 @@
+package configwise
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigType is the type of config documents of unknown type.
+const defaultConfigType = "yaml"
+
+// configTypes are the types of config files detected by extension, in the
+// order config files are looked up when no type is configured.
+var configTypes = []string{"yaml", "yml", "json", "json5", "toml", "hcl", "ini", "properties", "xml", "hocon", "conf", "jsonnet"}
+
+// typeAliases are the config types which share the decoder of another type.
+var typeAliases = map[string]string{"yml": "yaml", "props": "properties", "prop": "properties"}
+
+// Decoder parses a config document into a configuration tree of nested
+// map[string]interface{} sections.
+type Decoder func(data []byte) (map[string]interface{}, error)
+
+// WithDecoder registers the decoder of config documents of the type, which
+// replaces the built-in decoder of the type, if any. Config files are
+// detected by the extensions yaml, yml, json, json5, toml, hcl, ini,
+// properties, xml, hocon, conf and jsonnet; files of other types are read
+// when their type is set with WithType or WithName. The decoder of yaml also
+// decodes yml, the one of properties props and prop. Decoders of formats which
+// need larger libraries are provided by subpackages, so that applications
+// only link the formats they read:
+//
+//	configwise.NewConfigurer(hcl.Option(), properties.Option())
+//
+// Without their decoders, documents of these types fail to decode.
+func WithDecoder(configType string, decoder Decoder) Option {
+	return func(c *configurer) {
+		if c.decoders == nil {
+			c.decoders = make(map[string]Decoder)
+		}
+		c.decoders[configType] = decoder
+	}
+}
+
+// decodeConfig parses data of the given format (yaml, json, toml, xml,
+// hocon, ...) into a configuration tree, with the registered decoder
+// of the format, if any. These formats keep the case of their keys, so key
+// normalizers can split camelCase keys; other formats need a registered
+// decoder. Includes of HOCON documents are relative to the working
+// directory. Arrays of TOML tables become lists of sections, like YAML lists
+// of mappings.
+func decodeConfig(decoders map[string]Decoder, format string, data []byte) (map[string]interface{}, error) {
+	if decode, ok := lookupDecoder(decoders, format); ok {
+		tree, err := decode(data)
+		if err != nil {
+			return nil, err
+		}
+		if tree == nil {
+			tree = make(map[string]interface{})
+		}
+		return tree, nil
+	}
+
+	tree := make(map[string]interface{})
+	switch format {
+	case "yaml", "yml":
+		var err error
+		if tree, err = decodeYAML(data); err != nil {
+			return nil, err
+		}
+	case "json":
+		if len(bytes.TrimSpace(data)) == 0 {
+			return tree, nil
+		}
+		if err := json.Unmarshal(data, &tree); err != nil {
+			return nil, err
+		}
+	case "toml":
+		if err := toml.Unmarshal(data, &tree); err != nil {
+			return nil, err
+		}
+	case "xml":
+		var err error
+		if tree, err = decodeXML(data); err != nil {
+			return nil, err
+		}
+	case "hocon", "conf":
+		var err error
+		if tree, err = decodeHOCON(data, ""); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config type %q, register a decoder with WithDecoder", format)
+	}
+	if tree == nil {
+		tree = make(map[string]interface{})
+	}
+	return tree, nil
+}
+
+// lookupDecoder returns the registered decoder of the format or of the type
+// it is an alias of.
+func lookupDecoder(decoders map[string]Decoder, format string) (Decoder, bool) {
+	decode, ok := decoders[format]
+	if alias, isAlias := typeAliases[format]; isAlias && !ok {
+		decode, ok = decoders[alias]
+	}
+	return decode, ok
+}
+
+// decodeYAML parses a stream of YAML documents separated by ---, deep
+// merging them in order, so later documents override values of earlier ones.
+// Keys defined twice in a mapping take the last value; providers report them.
+func decodeYAML(data []byte) (map[string]interface{}, error) {
+	tree := make(map[string]interface{})
+	d := yaml.NewDecoder(bytes.NewReader(data))
+	for i := 1; ; i++ {
+		var (
+			node yaml.Node
+			doc  map[string]interface{}
+		)
+		err := d.Decode(&node)
+		if err == nil {
+			dropDuplicates(&node)
+			err = node.Decode(&doc)
+		}
+		if errors.Is(err, io.EOF) {
+			return tree, nil
+		}
+		if err != nil {
+			if i > 1 {
+				err = fmt.Errorf("document %d: %w", i, err)
+			}
+			return nil, err
+		}
+		deepMerge(tree, doc)
+	}
+}
@@ -0,0 +1,99 @@
+package configwise
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// lines decodes documents of key=value lines.
+func lines(data []byte) (map[string]interface{}, error) {
+	tree := make(map[string]interface{})
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, errors.New("missing =")
+		}
+		setPath(tree, key, value)
+	}
+	return tree, nil
+}
+
+func TestWithDecoder(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.lines"), []byte("Server.Port=8080\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		options []Option
+		want    string
+		err     string
+	}{
+		{
+			name:    "read in config",
+			options: []Option{WithDecoder("lines", lines), WithType("lines"), WithReadInConfig([]byte("server.port=8080"))},
+			want:    "8080",
+		},
+		{
+			name:    "file",
+			options: []Option{WithDecoder("lines", lines), WithPath(dir), WithName("app.lines")},
+			want:    "8080",
+		},
+		{
+			name:    "built-in type",
+			options: []Option{WithDecoder("yaml", lines), WithType("yaml"), WithReadInConfig([]byte("server.port=8080"))},
+			want:    "8080",
+		},
+		{
+			name:    "alias",
+			options: []Option{WithDecoder("yaml", lines), WithType("yml"), WithReadInConfig([]byte("server.port=8080"))},
+			want:    "8080",
+		},
+		{
+			name:    "hocon",
+			options: []Option{WithDecoder("conf", lines), WithType("conf"), WithReadInConfig([]byte("server.port=8080"))},
+			want:    "8080",
+		},
+		{
+			name:    "jsonnet",
+			options: []Option{WithDecoder("jsonnet", lines), WithType("jsonnet"), WithReadInConfig([]byte("server.port=8080"))},
+			want:    "8080",
+		},
+		{
+			name:    "error",
+			options: []Option{WithDecoder("lines", lines), WithType("lines"), WithReadInConfig([]byte("server.port"))},
+			err:     "missing =",
+		},
+		{
+			name:    "unregistered type",
+			options: []Option{WithDecoder("other", lines), WithType("lines"), WithReadInConfig([]byte("server.port=8080"))},
+			err:     `unsupported config type "lines"`,
+		},
+		{
+			name:    "format of a subpackage",
+			options: []Option{WithType("ini"), WithReadInConfig([]byte("[server]\nport = 8080"))},
+			err:     `unsupported config type "ini", register a decoder with WithDecoder`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewConfigurer(tt.options...)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error is %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.GetString("server.port"); got != tt.want {
+				t.Errorf("server.port is %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -23,18 +23,21 @@
 package configwise
 
 import (
-	"bytes"
+	"cmp"
+	"context"
 	"errors"
 	"fmt"
-	"os"
+	"io"
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/mitchellh/mapstructure"
-	"github.com/spf13/viper"
 )
 
 var (
@@ -49,47 +52,344 @@ const (
 	OpUnmarshal    = "configurer: unmarshal ->"
 	OpOverwrite    = "configurer: overwrite ->"
 	OpParseFlag    = "configurer: parse flag ->"
+	OpWatch        = "configurer: watch ->"
+	OpReload       = "configurer: reload ->"
+	OpWriteConfig  = "configurer: write config ->"
+	OpUnset        = "configurer: unset ->"
+	OpApplyPatch   = "configurer: apply patch ->"
+	OpRollback     = "configurer: rollback ->"
 )
 
+// Configurer is safe for concurrent use by multiple goroutines. Reads never
+// observe a partially applied change: every change, whether a reload, an
+// overwrite, a patch or a rollback, replaces the settings as a whole, and
+// changes are applied one at a time in the order they acquire the configurer.
+// Values returned by reads are copies which the caller may modify.
 type Configurer interface {
-	// UnmarshalKey takes a single key and unmarshal it into a Struct.
-	UnmarshalKey(name string, out interface{}) error
-
-	// Unmarshal the config into a Struct. Make sure that the tags
-	// on the fields of the structure are properly set.
-	Unmarshal(out interface{}) error
+	ReadOnlyConfig
 
 	// Overwrite used to overwrite particular values in the unmarshalled config
 	Overwrite(values map[string]interface{}) error
 
-	// Get used to get config section
-	Get(name string) interface{}
+	// OverwriteContext is like Overwrite, recording actor and request ID
+	// of the context in the audit log.
+	OverwriteContext(ctx context.Context, values map[string]interface{}) error
+
+	// OverwriteMerge is like Overwrite, but merges sections into the current
+	// sections instead of replacing them.
+	OverwriteMerge(values map[string]interface{}) error
+
+	// Unset reverts overwritten values of the key and below it to the values
+	// of the providers, or removes the key if it was not overwritten.
+	Unset(name string) error
+
+	// UnsetContext is like Unset, recording actor and request ID of the
+	// context in the audit log.
+	UnsetContext(ctx context.Context, name string) error
+
+	// ApplyPatch applies a JSON Merge Patch or a JSON Patch to the configuration.
+	ApplyPatch(patch []byte, kind PatchKind) error
+
+	// ApplyPatchContext is like ApplyPatch, recording actor and request ID
+	// of the context in the audit log.
+	ApplyPatchContext(ctx context.Context, patch []byte, kind PatchKind) error
+
+	// Sub returns a view of the section stored under the key, in which all
+	// keys are relative to the section.
+	Sub(name string) Configurer
+
+	// Clone returns an independent copy of the configurer with its current state.
+	Clone() Configurer
+
+	// Freeze makes the configuration immutable: later changes and reloads
+	// fail with ErrFrozen.
+	Freeze()
+
+	// Frozen reports whether the configuration was frozen.
+	Frozen() bool
+
+	// Snapshot returns an immutable view of the current configuration.
+	Snapshot() ReadOnlyConfig
+
+	// Generation returns the generation of the current configuration, which
+	// is incremented by every change.
+	Generation() uint64
+
+	// History returns the revisions of the configuration kept for Rollback.
+	History() []Revision
+
+	// Rollback restores the configuration of a generation in the history.
+	Rollback(generation uint64) error
+
+	// RollbackContext is like Rollback, recording actor and request ID of
+	// the context in the audit log.
+	RollbackContext(ctx context.Context, generation uint64) error
+
+	// RegisterAlias makes the canonical key addressable under alias as well.
+	// Overwrite of the alias changes the canonical key.
+	RegisterAlias(alias, canonical string)
 
-	// Has checks if config section exists.
-	Has(name string) bool
+	// Watch watches all providers and reloads the config when one of them changes.
+	// Remote providers are polled when a refresh interval is configured.
+	// It blocks until ctx is done.
+	Watch(ctx context.Context) error
+
+	// Events returns the channel of all lifecycle events.
+	Events() <-chan Event
+
+	// Subscribe returns a channel of lifecycle events of the given types,
+	// which is closed when ctx is done.
+	Subscribe(ctx context.Context, types ...EventType) <-chan Event
+
+	// Degraded returns the registered features which cannot work with the
+	// current configuration or providers.
+	Degraded() []Feature
+
+	// Health reports critical keys served from stale sources and providers which are down.
+	Health() Health
+
+	// UnusedKeys returns the keys never consumed by Get, UnmarshalKey or
+	// Unmarshal, with WithUsageTracking.
+	UnusedKeys() []string
+
+	// Warnings returns the problems found by the last load, like undefined
+	// variables and unresolved references.
+	Warnings() []Warning
+
+	// WriteConfig writes the effective configuration to the file as YAML,
+	// JSON or TOML, without the plaintext of secrets.
+	WriteConfig(path, format string) error
+
+	// ExportEnv flattens the effective configuration into environment
+	// variables, e.g. PREFIX_HTTP_PORT, without the plaintext of secrets
+	// unless WithExportedSecrets is passed.
+	ExportEnv(prefix string, options ...ExportOption) map[string]string
+
+	// WriteEnv writes the variables of ExportEnv in the .env format.
+	WriteEnv(w io.Writer, prefix string, options ...ExportOption) error
 }
 
 type Option func(*configurer)
 
+// changeEvent describes a change of the effective settings.
+// Both trees are immutable and must not be modified by hooks.
+type changeEvent struct {
+	// trigger is the kind of change, e.g. "reload" or "overwrite"
+	trigger string
+	old     map[string]interface{}
+	new     map[string]interface{}
+	changes []keyChange
+}
+
+type override struct {
+	key   string
+	value interface{}
+	// unset removes the key instead of setting it
+	unset bool
+}
+
 type configurer struct {
-	viper        *viper.Viper
-	configName   string
-	configType   string
-	readInConfig []byte
-	configMap    map[string]interface{}
+	configuration
+
+	// mu guards the state below, which is copy-on-write: it is only held to
+	// read or swap references, never while decoding, copying or doing I/O, so
+	// readers of unrelated sections never wait for a slow Unmarshal or a write.
+	mu sync.RWMutex
+	// writeMu serializes mutations of the state
+	writeMu sync.Mutex
+	// frozen rejects mutations, it is set while holding writeMu
+	frozen atomic.Bool
+	// settings is replaced on every change and never modified in place
+	settings map[string]interface{}
+	// base holds the settings read from the providers, before overrides
+	base map[string]interface{}
+	// keys of the settings resolved from secrets, with the reference they
+	// were resolved from, if any
+	secrets map[string]string
+	// providers which produced the leaf keys of the settings
+	origins map[string]Provider
+	// positions of the values of Positioner providers by canonical key
+	positions map[Provider]map[string]Position
+	// problems found by the last load
+	warnings  []Warning
+	overrides []override
+	// generation counts the changes of the settings, history keeps the last revisions
+	generation uint64
+	history    []revision
+
+	bus     eventBus
+	events  *subscription
+	stateMu sync.Mutex
+	usage   *usage
+	decoded memo
+	// deprecated keys already reported
+	deprecations deprecations
+	aliases      aliases
+	down         map[string]struct{}
+	refreshed    map[string]time.Time
+	stale        map[string]struct{}
+
+	// channels of the events of Sub views by section, guarded by stateMu
+	sectionEvents map[string]<-chan Event
+}
+
+// configuration holds the settings of a configurer made by the options. It is
+// not modified after NewConfigurer returns.
+type configuration struct {
+	// patterns of keys which are secrets regardless of their source
+	secretPatterns []string
+	secretPolicy   SecretPolicy
+	providers      []Provider
+	custom         []Provider
+	precedence     []Source
+	// interval of re-fetching remote providers while watching
+	refreshInterval time.Duration
+	loadRetry       RetryPolicy
+	onChange        []func()
+	changeHooks     []func(changeEvent)
+	critical        []criticalKey
+	onStale         []func(StaleKey)
+	sops            SOPSDecrypter
+	audit           []func(AuditEntry)
+	access          []func(AccessEntry)
+	schemes         []valueScheme
+	journal         *journal
+	tracer          Tracer
+	features        []Feature
+	eventBuffer     int
+	overflow        OverflowPolicy
+	historySize     int
+	dotEnv          *dotEnv
+	jsonnet         JsonnetVM
+	stdinType       string
+	fsDocs          []fsDoc
+	// the config file must exist
+	fileConfigured bool
+	optionalFile   bool
+	defaultDocs    []defaultDoc
+	// duplicate keys of YAML documents are warnings rather than errors
+	lenientDuplicates bool
+	// registered Go types by key
+	schema map[string]reflect.Type
+	// errors of options which are reported by NewConfigurer
+	optionErrs []error
+	// decoders of WithDecoder by config type
+	decoders map[string]Decoder
+	// migrations of WithMigration by the version they upgrade
+	migrations map[int]migration
+	versionKey string
+
+	strict bool
+	// warnings of the initial load fail NewConfigurer
+	failOnWarnings  bool
+	freeForm        []string
+	schemaValidator SchemaValidator
+	cue             CUESchema
+	resolvers       []Resolver
+	// strings are expanded on access instead of on load
+	lazyExpansion  bool
+	unmarshalCache bool
+	usageTracking  bool
+	hookPolicies   []hookPolicy
+	// decode hooks of WithDecodeHook, applied after the built-in ones
+	customHooks []mapstructure.DecodeHookFunc
+	// new keys of deprecated keys
+	deprecated    map[string]string
+	keyNormalizer KeyNormalizer
+	cacheDir      string
+	validator     *validator.Validate
+	vars          map[string]string
+	lang          string
+	appName       string
+	configName    string
+	configType    string
+	paths         []string
+	envPrefix     string
+	readInConfig  []byte
+	configMap     map[string]interface{}
 	// user defined Flags in the form of <option>.<key> = <value>
 	// which overwrites initial config key
 	flags []string
 }
 
+// WithOptionError makes NewConfigurer fail with the error, which lets
+// options of other packages report invalid arguments, e.g.
+// jsonschema.WithJSONSchema for a schema which does not compile. A nil error
+// is ignored.
+func WithOptionError(err error) Option {
+	return func(c *configurer) {
+		if err != nil {
+			c.optionErrs = append(c.optionErrs, err)
+		}
+	}
+}
+
+// WithPath adds a directory the config file is looked up in. Environment
+// variables and a leading ~ are expanded, e.g. "~/.myapp" or
+// "${XDG_CONFIG_HOME}/myapp". A path to a file with the extension of a
+// config type, e.g. "~/.myapp/config.yaml", adds its directory and sets the
+// name and type of the config file like WithName.
+//
+// Without paths the working directory, $XDG_CONFIG_HOME/<app>,
+// ~/.config/<app>, the directory of the executable and /etc/<app> are
+// searched, where app is the name set with WithAppName or the name of the
+// executable.
 func WithPath(path string) Option {
 	return func(c *configurer) {
-		c.viper.AddConfigPath(path)
+		expanded, err := expandPath(path)
+		if err != nil {
+			c.optionErrs = append(c.optionErrs, fmt.Errorf("path %q: %w", path, err))
+			return
+		}
+		if isConfigFile(expanded) {
+			WithName(filepath.Base(expanded))(c)
+			expanded = filepath.Dir(expanded)
+		}
+		c.paths = append(c.paths, expanded)
+		c.fileConfigured = true
+	}
+}
+
+// WithOptionalFile lets the configurer proceed with the other sources, like
+// environment variables, flags and defaults, when the config file is missing.
+// A missing config file fails the load with ErrConfigNotFound when its path
+// or name is configured, with WithPath, WithSearchPaths, WithName or
+// WithConfigName; the file looked up in the default directories is always optional.
+func WithOptionalFile() Option {
+	return func(c *configurer) {
+		c.optionalFile = true
+	}
+}
+
+// WithSearchPaths sets the directories the config file is looked up in, in
+// order, replacing the paths added before. The first directory holding the
+// config file wins. Paths are expanded like with WithPath. Without paths the
+// default directories are searched.
+func WithSearchPaths(paths ...string) Option {
+	return func(c *configurer) {
+		c.paths = nil
+		c.fileConfigured = len(paths) > 0
+		for _, path := range paths {
+			expanded, err := expandPath(path)
+			if err != nil {
+				c.optionErrs = append(c.optionErrs, fmt.Errorf("path %q: %w", path, err))
+				continue
+			}
+			c.paths = append(c.paths, expanded)
+		}
 	}
 }
 
+// WithConfigName sets the base name of the config file, "config" by default.
+// The file is looked up with the extensions of the config types, unless the
+// name has an extension, which sets the type, like WithName.
+func WithConfigName(name string) Option {
+	return WithName(name)
+}
+
 func WithName(name string) Option {
 	return func(c *configurer) {
+		c.fileConfigured = true
 		if ext := filepath.Ext(name); ext != "" {
 			c.configName = strings.TrimSuffix(name, ext)
 			c.configType = ext[1:]
@@ -101,10 +401,15 @@ func WithName(name string) Option {
 
 func WithPrefix(prefix string) Option {
 	return func(c *configurer) {
-		c.viper.SetEnvPrefix(prefix)
+		c.envPrefix = prefix
 	}
 }
 
+// WithType sets the type of the config file and of WithReadInConfig, e.g.
+// "yaml", "json", "json5", "toml", "hcl", "ini", "properties", "xml" or
+// "hocon". Without type the config file is looked up with each of these
+// extensions, .yml, .conf and .jsonnet, and decoded according to the
+// extension found; WithReadInConfig defaults to YAML.
 func WithType(configType string) Option {
 	return func(c *configurer) {
 		c.configType = configType
@@ -129,122 +434,701 @@ func WithFlags(flags []string) Option {
 	}
 }
 
+// WithAppName selects the application specific section of a config shared by
+// several binaries. Trees following the convention
+//
+//	common:
+//	  ...
+//	apps:
+//	  <name>:
+//	    ...
+//
+// are flattened into the common section overlaid by the section of the application.
+func WithAppName(name string) Option {
+	return func(c *configurer) {
+		c.appName = strings.ToLower(name)
+	}
+}
+
+const varPrefix = "var:"
+
+// WithVars makes the variables available to interpolation as ${var:<name>},
+// e.g. region: ${var:region:-eu-west-1}, so deployment tooling can inject
+// non-secret variables like region or cluster name without polluting the
+// process environment.
+func WithVars(vars map[string]string) Option {
+	return func(c *configurer) {
+		if c.vars == nil {
+			c.vars = make(map[string]string, len(vars))
+		}
+		for name, value := range vars {
+			c.vars[name] = value
+		}
+	}
+}
+
+// WithStrict makes Unmarshal and UnmarshalKey fail when the config contains
+// keys the target struct does not know about, which catches typos early.
+// Sections declared with WithFreeForm are exempt.
+func WithStrict() Option {
+	return func(c *configurer) {
+		c.strict = true
+	}
+}
+
+// WithOnChange registers a callback invoked after every successful reload.
+func WithOnChange(fn func()) Option {
+	return func(c *configurer) {
+		c.onChange = append(c.onChange, fn)
+	}
+}
+
 func NewConfigurer(options ...Option) (Configurer, error) {
 	c := &configurer{
-		viper:      viper.New(),
-		configName: "config",
-		configType: "yaml",
+		configuration: configuration{
+			configName:  "config",
+			precedence:  defaultPrecedence,
+			tracer:      noopTracer{},
+			historySize: defaultHistorySize,
+		},
+		usage:     new(usage),
+		down:      make(map[string]struct{}),
+		refreshed: make(map[string]time.Time),
+		stale:     make(map[string]struct{}),
 	}
-
-	c.viper.AutomaticEnv()
-	c.viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	c.events = c.bus.subscribe(eventBufferSize, OverflowDropNewest)
 
 	for _, opt := range options {
 		opt(c)
 	}
 
-	c.viper.SetConfigType(c.configType)
-	c.viper.SetConfigFile(c.configName + "." + c.configType)
+	if len(c.optionErrs) > 0 {
+		return nil, &Error{Op: OpNew, Err: errors.Join(c.optionErrs...)}
+	}
 
-	if c.configMap != nil {
-		if err := c.viper.MergeConfigMap(c.configMap); err != nil {
-			return nil, err
+	for _, p := range c.custom {
+		if p, ok := p.(*remoteProvider); ok && p.decoders == nil {
+			p.decoders = c.decoders
 		}
 	}
+	c.providers = append(c.builtinProviders(), c.custom...)
+	sortProviders(c.providers, c.precedence)
 
-	if c.readInConfig != nil {
-		if err := c.viper.ReadConfig(bytes.NewBuffer(c.readInConfig)); err != nil {
-			return nil, err
+	if err := c.load(); err != nil {
+		return nil, &Error{Op: OpNew, Err: err}
+	}
+	c.bus.publish(Event{Type: EventLoaded})
+
+	return c, nil
+}
+
+// load reads the initial settings and replays the journal.
+func (cfg *configurer) load() (err error) {
+	ctx, span := cfg.tracer.Start(context.Background(), "configwise.load", nil)
+	defer func() { span.End(err) }()
+
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		var result *readResult
+		if result, err = cfg.read(ctx); err == nil {
+			cfg.settings, cfg.secrets, cfg.origins, cfg.warnings = result.settings, result.secrets, result.origins, result.warnings
+			cfg.base, cfg.positions = result.settings, result.positions
+			break
+		}
+		if !cfg.loadRetry.retry(attempt, start) {
+			if attempt > 1 {
+				return fmt.Errorf("after %d attempts: %w", attempt, err)
+			}
+			return err
 		}
 	}
 
-	_ = c.viper.ReadInConfig()
+	if cfg.failOnWarnings && len(cfg.warnings) > 0 {
+		return warningsError(cfg.lang, cfg.warnings)
+	}
 
-	// automatically inject ENV variables using ${ENV} pattern
-	for _, key := range c.viper.AllKeys() {
-		val := c.viper.Get(key)
-		switch t := val.(type) {
-		case string:
-			// for string just expand it
-			c.viper.Set(key, parseEnvDefault(t))
-		case []interface{}:
-			// for slice -> check if it's slice of strings
-			strArr := make([]string, 0, len(t))
-			for i := 0; i < len(t); i++ {
-				if valStr, ok := t[i].(string); ok {
-					strArr = append(strArr, parseEnvDefault(valStr))
-					continue
-				}
+	if cfg.journal != nil {
+		if err = cfg.replayJournal(); err != nil {
+			return err
+		}
+	}
+	if err = cfg.validateSchema(cfg.settings); err != nil {
+		return err
+	}
 
-				c.viper.Set(key, val)
+	cfg.mu.Lock()
+	cfg.record("load")
+	cfg.mu.Unlock()
+	return nil
+}
+
+func (cfg *configurer) builtinProviders() []Provider {
+	var providers []Provider
+
+	for _, doc := range cfg.defaultDocs {
+		providers = append(providers, &bytesProvider{
+			name:       "defaults",
+			priority:   PriorityDefaults,
+			source:     SourceDefault,
+			configType: doc.configType,
+			data:       doc.data,
+			decode:     cfg.decodeDocument,
+		})
+	}
+
+	if cfg.configMap != nil {
+		providers = append(providers, &mapProvider{
+			name:     "config map",
+			priority: PriorityConfigMap,
+			source:   SourceDefault,
+			tree:     cfg.configMap,
+		})
+	}
+
+	if cfg.readInConfig != nil {
+		providers = append(providers, &bytesProvider{
+			name:       "read in config",
+			priority:   PriorityReadInConfig,
+			configType: cmp.Or(cfg.configType, defaultConfigType),
+			data:       cfg.readInConfig,
+			decode:     cfg.decodeDocument,
+		})
+	}
+
+	for _, doc := range cfg.fsDocs {
+		providers = append(providers, newFSProvider(doc, cfg.configType, cfg.decodeDocument))
+	}
+
+	if cfg.stdinType != "" {
+		providers = append(providers, newStdinProvider(cfg.stdinType, cfg.decodeDocument))
+	}
+
+	paths := cfg.paths
+	if len(paths) == 0 {
+		paths = defaultSearchPaths(cfg.appName)
+	}
+
+	return append(providers,
+		&fileProvider{
+			configName: cfg.configName,
+			configType: cfg.configType,
+			paths:      paths,
+			required:   cfg.fileConfigured && !cfg.optionalFile,
+			decode:     cfg.decodeDocument,
+		},
+		&envProvider{
+			prefix:   cfg.envPrefix,
+			replacer: envReplacer,
+			dotEnv:   cfg.dotEnv,
+		},
+		&flagsProvider{flags: cfg.flags, lang: cfg.lang},
+	)
+}
+
+// readResult is the outcome of reading all providers.
+type readResult struct {
+	settings map[string]interface{}
+	secrets  map[string]string
+	// providers which produced the leaf keys
+	origins   map[string]Provider
+	positions map[Provider]map[string]Position
+	warnings  []Warning
+}
+
+// read merges the trees of all providers in the order of their priority
+// and resolves the values.
+func (cfg *configurer) read(ctx context.Context) (*readResult, error) {
+	trees := make([]map[string]interface{}, len(cfg.providers))
+	known := make(map[string]interface{})
+	positions := make(map[Provider]map[string]Position)
+	var (
+		secretKeys []string
+		duplicates []Warning
+	)
+	for i, p := range cfg.providers {
+		_, span := cfg.tracer.Start(ctx, "configwise.provider.read", map[string]string{
+			"configwise.provider": p.Name(),
+			"configwise.source":   string(sourceOf(p)),
+		})
+		tree, err := p.Read()
+		span.End(err)
+
+		cfg.providerState(p, err)
+		if err != nil {
+			return nil, fmt.Errorf("provider %s: %w", p.Name(), err)
+		}
+		trees[i] = normalizeTree(normalizeKeys(tree, cfg.normalizerOf(p)))
+		if cfg.appName != "" {
+			trees[i] = selectApp(trees[i], cfg.appName)
+		}
+		if trees[i], err = cfg.migrate(p, trees[i]); err != nil {
+			return nil, fmt.Errorf("provider %s: %w", p.Name(), err)
+		}
+		dups, err := cfg.checkDuplicates(p)
+		if err != nil {
+			return nil, fmt.Errorf("provider %s: %w", p.Name(), err)
+		}
+		duplicates = append(duplicates, dups...)
+		deepMerge(known, trees[i])
+
+		if pp, ok := p.(Positioner); ok {
+			if pos := pp.Positions(); len(pos) > 0 {
+				pos = normalizePositions(pos, cfg.normalizerOf(p))
+				if cfg.appName != "" {
+					pos = selectAppPositions(pos, cfg.appName)
+				}
+				positions[p] = pos
 			}
+		}
+
+		if sp, ok := p.(SecretProvider); ok && sp.Secret() {
+			secretKeys = append(secretKeys, leafKeys(trees[i])...)
+		}
+	}
 
-			// we should set the whole array
-			if len(strArr) > 0 {
-				c.viper.Set(key, strArr)
+	keys := leafKeys(known)
+	settings := make(map[string]interface{})
+	origins := make(map[string]Provider)
+	for i, p := range cfg.providers {
+		leaves := leafKeys(trees[i])
+		replaced := make([]interface{}, len(leaves))
+		for j, key := range leaves {
+			replaced[j], _ = searchPath(settings, key)
+		}
+		deepMerge(settings, trees[i])
+		for j, key := range leaves {
+			setOrigin(origins, key, replaced[j], p)
+		}
+
+		if l, ok := p.(Lookuper); ok {
+			for _, key := range keys {
+				if val, ok := l.Lookup(key); ok {
+					old, _ := searchPath(settings, key)
+					setPath(settings, key, val)
+					setOrigin(origins, key, old, p)
+				}
 			}
-		default:
-			c.viper.Set(key, val)
 		}
 	}
 
-	// override config flags
-	for _, f := range c.flags {
-		key, val, errP := parseFlag(f)
-		if errP != nil {
-			return nil, fmt.Errorf("%s %w", OpNew, errP)
+	deprecated := cfg.moveDeprecated(settings, origins, positions)
+
+	// automatically inject ENV variables using ${ENV} pattern
+	var (
+		warnings []Warning
+		err      error
+	)
+	if !cfg.lazyExpansion {
+		settings, warnings, err = cfg.expandTree(settings)
+		if err != nil {
+			return nil, err
 		}
-		c.viper.Set(key, parseEnvDefault(val))
 	}
+	warnings = append(append(duplicates, deprecated...), warnings...)
 
-	return c, nil
+	settings, err = cfg.evaluateCUE(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	_, span := cfg.tracer.Start(ctx, "configwise.secrets.resolve", nil)
+	settings, secrets, err := cfg.resolveSchemes(settings)
+	span.End(err)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range secretKeys {
+		secrets[key] = ""
+	}
+	return &readResult{settings: settings, secrets: secrets, origins: origins, positions: positions, warnings: warnings}, nil
+}
+
+func (cfg *configurer) reload(ctx context.Context) (err error) {
+	ctx, span := cfg.tracer.Start(ctx, "configwise.reload", nil)
+	defer func() { span.End(err) }()
+
+	if cfg.frozen.Load() {
+		return &Error{Op: OpReload, Err: ErrFrozen}
+	}
+	result, err := cfg.read(ctx)
+	if err != nil {
+		return &Error{Op: OpReload, Err: err}
+	}
+	settings, secrets := result.settings, result.secrets
+
+	cfg.writeMu.Lock()
+	if cfg.frozen.Load() {
+		cfg.writeMu.Unlock()
+		return &Error{Op: OpReload, Err: ErrFrozen}
+	}
+	cfg.mu.RLock()
+	old, oldSecrets, overrides := cfg.settings, cfg.secrets, cfg.overrides
+	cfg.mu.RUnlock()
+
+	base := settings
+	settings = withOverrides(base, overrides)
+	changed := !reflect.DeepEqual(old, settings)
+	if changed {
+		if err = cfg.validateSchema(settings); err != nil {
+			cfg.writeMu.Unlock()
+			return &Error{Op: OpReload, Err: err}
+		}
+	}
+
+	cfg.mu.Lock()
+	cfg.origins, cfg.positions, cfg.warnings = result.origins, result.positions, result.warnings
+	cfg.base = base
+	if changed {
+		cfg.settings, cfg.secrets = settings, secrets
+		cfg.record("reload")
+	}
+	cfg.mu.Unlock()
+	cfg.writeMu.Unlock()
+
+	if !changed {
+		return nil
+	}
+
+	cfg.publishChange("reload", old, settings, oldSecrets, secrets)
+	for _, fn := range cfg.onChange {
+		fn()
+	}
+	return nil
+}
+
+// publishChange passes the change to the hooks and the event bus. Changed
+// secrets are published as EventSecretRotated without their values.
+func (cfg *configurer) publishChange(trigger string, old, new map[string]interface{}, secrets ...map[string]string) {
+	event := changeEvent{trigger: trigger, old: old, new: new, changes: diffSettings(old, new)}
+	for _, hook := range cfg.changeHooks {
+		hook(event)
+	}
+
+	events := make([]Event, 0, len(event.changes)+1)
+	if trigger == "reload" {
+		events = append(events, Event{Type: EventReloaded})
+	}
+	for _, change := range event.changes {
+		if cfg.isSecretKey(change.key, secrets...) {
+			events = append(events, Event{Type: EventSecretRotated, Key: change.key})
+			continue
+		}
+		events = append(events, Event{
+			Type:     EventKeyChanged,
+			Key:      change.key,
+			OldValue: deepCopy(change.oldValue),
+			NewValue: deepCopy(change.newValue),
+		})
+	}
+	cfg.bus.publish(events...)
+}
+
+func (cfg *configurer) Watch(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	changes := make(chan struct{}, 1)
+	errs := make(chan error, len(cfg.providers))
+	notify := func() {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+
+	for _, p := range cfg.providers {
+		go func(p Provider) {
+			if err := p.Watch(ctx, notify); err != nil {
+				errs <- &Error{Op: OpWatch, Err: fmt.Errorf("provider %s: %w", p.Name(), err)}
+			}
+		}(p)
+	}
+
+	if cfg.refreshInterval > 0 && cfg.hasRemote() {
+		go refresh(ctx, cfg.refreshInterval, notify)
+	}
+
+	if interval := cfg.staleCheckInterval(); interval > 0 {
+		go refresh(ctx, interval, cfg.checkStale)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			return err
+		case <-changes:
+			// a broken source must not break the running application,
+			// the previous config stays in place until the next change
+			if err := cfg.reload(ctx); err != nil {
+				cfg.bus.publish(Event{Type: EventReloadFailed, Err: err})
+			}
+		}
+	}
 }
 
 func (cfg *configurer) UnmarshalKey(name string, out interface{}) error {
-	if err := cfg.viper.UnmarshalKey(name, out, decoderConfig); err != nil {
-		return fmt.Errorf("%s %w", OpUnmarshalKey, err)
+	key := cfg.canonicalKey(name)
+	// read before the settings, see storeDecoded
+	generation := cfg.Generation()
+	val, _, err := cfg.find(key)
+	cfg.auditAccess("unmarshal_key", key, val)
+	if err == nil && cfg.unmarshalCache && cfg.loadDecoded(key, generation, out) {
+		return nil
+	}
+
+	var md mapstructure.Metadata
+	if err == nil {
+		val, err = cfg.resolveLazy(val)
+	}
+	if err == nil {
+		callDefaults(out)
+		err = cfg.decode(key, withDefaults(val, out), out, &md)
+		cfg.markUsed(key, md.Unused)
+		if err == nil && cfg.strict {
+			err = cfg.checkUnknown(key, md.Unused)
+		}
+	}
+	if err == nil {
+		err = checkRequired(cfg.lang, key, out)
+	}
+	if err == nil {
+		err = checkEnums(cfg.lang, key, out)
+	}
+	if err == nil {
+		err = cfg.validate(key, out)
+	}
+	if err == nil {
+		err = callValidate(key, out)
+	}
+	if err != nil {
+		return &Error{Op: OpUnmarshalKey, Err: cfg.locate(err)}
+	}
+	if cfg.unmarshalCache {
+		cfg.storeDecoded(key, generation, out, md.Unused)
 	}
 	return nil
 }
 
 func (cfg *configurer) Unmarshal(out interface{}) error {
-	if err := cfg.viper.Unmarshal(out, decoderConfig); err != nil {
-		return fmt.Errorf("%s %w", OpUnmarshal, err)
+	cfg.mu.RLock()
+	settings := cfg.settings
+	cfg.mu.RUnlock()
+	cfg.auditAccess("unmarshal", "", settings)
+
+	val, err := cfg.resolveLazy(settings)
+	if err == nil {
+		var md mapstructure.Metadata
+		callDefaults(out)
+		err = cfg.decode("", withDefaults(val, out), out, &md)
+		cfg.markUsed("", md.Unused)
+		if err == nil && cfg.strict {
+			err = cfg.checkUnknown("", md.Unused)
+		}
+	}
+	if err == nil {
+		err = checkRequired(cfg.lang, "", out)
+	}
+	if err == nil {
+		err = checkEnums(cfg.lang, "", out)
+	}
+	if err == nil {
+		err = cfg.validate("", out)
+	}
+	if err == nil {
+		err = callValidate("", out)
+	}
+	if err != nil {
+		return &Error{Op: OpUnmarshal, Err: cfg.locate(err)}
 	}
 	return nil
 }
 
 func (cfg *configurer) Overwrite(values map[string]interface{}) error {
-	for key, value := range values {
-		cfg.viper.Set(key, value)
+	return cfg.OverwriteContext(context.Background(), values)
+}
+
+func (cfg *configurer) OverwriteContext(ctx context.Context, values map[string]interface{}) error {
+	coerced, err := cfg.coerceValues(values)
+	if err != nil {
+		return &Error{Op: OpOverwrite, Err: err}
+	}
+
+	cfg.writeMu.Lock()
+	if cfg.frozen.Load() {
+		cfg.writeMu.Unlock()
+		return &Error{Op: OpOverwrite, Err: ErrFrozen}
+	}
+	cfg.mu.RLock()
+	old, secrets, overrides := cfg.settings, cfg.secrets, cfg.overrides
+	cfg.mu.RUnlock()
+
+	settings, overrides := applyOverrides(old, overrides, coerced)
+	if err = cfg.validateSchema(settings); err != nil {
+		cfg.writeMu.Unlock()
+		return &Error{Op: OpOverwrite, Err: err}
 	}
+
+	if cfg.journal != nil {
+		err = cfg.journal.append(journalEntry{
+			Time:      time.Now().UTC(),
+			Op:        "overwrite",
+			Values:    cfg.redactValues(coerced, secrets),
+			Actor:     ActorFromContext(ctx),
+			RequestID: RequestIDFromContext(ctx),
+		})
+		if err != nil {
+			cfg.writeMu.Unlock()
+			return &Error{Op: OpOverwrite, Err: fmt.Errorf("journal: %w", err)}
+		}
+	}
+
+	cfg.mu.Lock()
+	cfg.settings, cfg.overrides = settings, overrides
+	cfg.record("overwrite")
+	cfg.mu.Unlock()
+	cfg.writeMu.Unlock()
+
+	cfg.auditChange(ctx, "overwrite", values)
+	cfg.publishChange("overwrite", old, settings, secrets)
 	return nil
 }
 
+// OverwriteMerge is like Overwrite, but merges sections into the current
+// sections instead of replacing them, so overwriting http: {port: 9090} keeps
+// the other keys of http. Lists are replaced.
+func (cfg *configurer) OverwriteMerge(values map[string]interface{}) error {
+	return cfg.OverwriteContext(context.Background(), mergeValues(values))
+}
+
+// mergeValues flattens the sections of the values into their leaf keys, so
+// overwriting them leaves the other keys of the sections untouched. Empty
+// sections change nothing and are dropped.
+func mergeValues(values map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		walkLeaves(key, normalizeValue(value), func(key string, value interface{}) bool {
+			if m, ok := value.(map[string]interface{}); !ok || len(m) > 0 {
+				merged[key] = value
+			}
+			return true
+		})
+	}
+	return merged
+}
+
+// coerceValues normalizes the keys and values and converts them to the registered schema.
+func (cfg *configurer) coerceValues(values map[string]interface{}) (map[string]interface{}, error) {
+	coerced := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		key = cfg.canonicalKey(key)
+		value, err := cfg.coerce(key, normalizeValue(value))
+		if err != nil {
+			return nil, err
+		}
+		coerced[key] = value
+	}
+	return coerced, nil
+}
+
+// applyOverrides returns copies of the settings and the overrides with the
+// values applied. Overrides of the same keys, or of keys below them, are replaced.
+func applyOverrides(settings map[string]interface{}, overrides []override, values map[string]interface{}) (map[string]interface{}, []override) {
+	settings = deepCopy(settings).(map[string]interface{})
+	for key, value := range values {
+		// the caller may keep modifying its values
+		overrides = setOverride(overrides, override{key: key, value: deepCopy(value)})
+		setPath(settings, key, deepCopy(value))
+	}
+	return settings, overrides
+}
+
+// setOverride returns the overrides with o added and the overrides of its
+// key and below it dropped.
+func setOverride(overrides []override, o override) []override {
+	kept := make([]override, 0, len(overrides)+1)
+	for _, existing := range overrides {
+		if existing.key != o.key && !strings.HasPrefix(existing.key, o.key+keyDelimiter) {
+			kept = append(kept, existing)
+		}
+	}
+	return append(kept, o)
+}
+
+// withOverrides returns a copy of the settings with the overrides applied in order.
+func withOverrides(settings map[string]interface{}, overrides []override) map[string]interface{} {
+	settings = deepCopy(settings).(map[string]interface{})
+	for _, o := range overrides {
+		if o.unset {
+			deletePath(settings, o.key)
+			continue
+		}
+		setPath(settings, o.key, deepCopy(o.value))
+	}
+	return settings
+}
+
 func (cfg *configurer) Get(name string) interface{} {
-	return cfg.viper.Get(name)
+	key, val := cfg.lookup(name)
+	cfg.auditAccess("get", key, val)
+	return val
 }
 
 func (cfg *configurer) Has(name string) bool {
-	return cfg.viper.IsSet(name)
+	_, ok, _ := cfg.find(cfg.canonicalKey(name))
+	return ok
 }
 
-func parseFlag(flag string) (string, string, error) {
+// find looks the key up in the merged settings first and falls back to
+// the lookup providers, so that e.g. environment variables are visible
+// even for keys no other provider defines. Values of the lookup providers
+// are resolved by the value schemes like the merged settings on load.
+// The value must not be modified.
+func (cfg *configurer) find(key string) (interface{}, bool, error) {
+	cfg.mu.RLock()
+	settings := cfg.settings
+	cfg.mu.RUnlock()
+
+	if val, ok := searchPath(settings, key); ok {
+		return val, true, nil
+	}
+
+	for i := len(cfg.providers) - 1; i >= 0; i-- {
+		if l, ok := cfg.providers[i].(Lookuper); ok {
+			if val, ok := l.Lookup(key); ok {
+				resolved, err := cfg.resolveValue(key, val, make(map[string]string))
+				if err != nil {
+					return val, true, err
+				}
+				return resolved, true, nil
+			}
+		}
+	}
+	return nil, false, nil
+}
+
+// flagError returns an ErrInvalidFlag with the translated message.
+func flagError(lang string, id MessageID, args ...interface{}) error {
+	return &Error{Op: OpParseFlag, Err: &messageError{msg: translate(lang, id, args...), err: ErrInvalidFlag}}
+}
+
+func parseFlag(flag, lang string) (string, string, error) {
 	if !strings.Contains(flag, "=") {
-		return "", "", fmt.Errorf("%s invalid flag `%s`", OpParseFlag, flag)
+		return "", "", flagError(lang, MsgInvalidFlag, flag)
 	}
 
 	parts := strings.SplitN(strings.TrimLeft(flag, " \"'`"), "=", 2)
 	if len(parts) < 2 {
-		return "", "", errors.New("usage: -o key=value")
+		return "", "", flagError(lang, MsgFlagUsage)
 	}
 
 	if parts[0] == "" {
-		return "", "", errors.New("key should not be empty")
+		return "", "", flagError(lang, MsgEmptyFlagKey)
 	}
 
 	if parts[1] == "" {
-		return "", "", errors.New("value should not be empty")
+		return "", "", flagError(lang, MsgEmptyFlagValue)
 	}
 
 	return strings.Trim(parts[0], " \n\t"), parseValue(strings.Trim(parts[1], " \n\t")), nil
@@ -261,20 +1145,33 @@ func parseValue(value string) string {
 	return value
 }
 
-func parseEnvDefault(val string) string {
-	// tcp://127.0.0.1:${RPC_PORT:-36643}
-	// for envs like this, part would be tcp://127.0.0.1:
-	return ExpandVal(val, os.Getenv)
+// decode decodes the input into out. All values which cannot be decoded are
+// reported as DecodeError with keys below prefix, unless the failing hook is
+// lenient for the key.
+func (cfg *configurer) decode(prefix string, input interface{}, out interface{}, md *mapstructure.Metadata) error {
+	config := &mapstructure.DecoderConfig{
+		Metadata:         md,
+		Result:           out,
+		WeaklyTypedInput: true,
+	}
+	causes := make(map[string]error)
+	cfg.decoderConfig(config, causes)
+
+	decoder, err := mapstructure.NewDecoder(config)
+	if err != nil {
+		return err
+	}
+	if err = decoder.Decode(input); err != nil {
+		return cfg.applyHookPolicies(decodeErrors(prefix, err, causes))
+	}
+	return nil
 }
 
-func decoderConfig(config *mapstructure.DecoderConfig) {
+// decoderConfig sets up the config of a decoder. Errors of decode hooks are
+// recorded in causes, if not nil.
+func (cfg *configurer) decoderConfig(config *mapstructure.DecoderConfig, causes map[string]error) {
 	config.TagName = TagName
-	config.DecodeHook = mapstructure.ComposeDecodeHookFunc(
-		stringToUUID,
-		mapstructure.StringToTimeHookFunc(time.RFC3339),
-		mapstructure.StringToTimeDurationHookFunc(),
-		mapstructure.StringToSliceHookFunc(","),
-	)
+	config.DecodeHook = composeHooks(cfg.hooks(), causes)
 }
 
 func stringToUUID(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
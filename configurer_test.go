@@ -0,0 +1,94 @@
+package configwise
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// contentionConfig has a small section read by the benchmarks and a large
+// one written or unmarshalled in the background.
+func contentionConfig(b *testing.B) Configurer {
+	b.Helper()
+
+	var sb strings.Builder
+	sb.WriteString("http: {port: 8080}\nlarge:\n")
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&sb, "  key%d: value%d\n", i, i)
+	}
+	c, err := NewConfigurer(WithType("yaml"), WithReadInConfig([]byte(sb.String())))
+	if err != nil {
+		b.Fatal(err)
+	}
+	return c
+}
+
+// slowSection is unmarshalled slowly, like a section with an expensive Validate.
+type slowSection map[string]string
+
+func (slowSection) Validate() error {
+	time.Sleep(time.Millisecond)
+	return nil
+}
+
+// background runs fn in a loop until the benchmark stops.
+func background(b *testing.B, fn func(i int)) {
+	b.Helper()
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+				fn(i)
+			}
+		}
+	}()
+	b.Cleanup(func() {
+		close(done)
+		wg.Wait()
+	})
+}
+
+func benchmarkGet(b *testing.B, c Configurer) {
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if c.Get("http.port") == nil {
+				b.Error("http.port is not set")
+			}
+		}
+	})
+}
+
+func BenchmarkGet(b *testing.B) {
+	benchmarkGet(b, contentionConfig(b))
+}
+
+func BenchmarkGetDuringOverwrite(b *testing.B) {
+	c := contentionConfig(b)
+	background(b, func(i int) {
+		if err := c.Overwrite(map[string]interface{}{"large.key0": i}); err != nil {
+			b.Error(err)
+		}
+	})
+	benchmarkGet(b, c)
+}
+
+func BenchmarkGetDuringUnmarshalKey(b *testing.B) {
+	c := contentionConfig(b)
+	background(b, func(int) {
+		var section slowSection
+		if err := c.UnmarshalKey("large", &section); err != nil {
+			b.Error(err)
+		}
+	})
+	benchmarkGet(b, c)
+}
@@ -0,0 +1,48 @@
+package configwise
+
+import (
+	"fmt"
+)
+
+// CUESchema evaluates the config tree with a CUE schema, like the schemas
+// of the cue subpackage, which keeps cuelang.org/go out of applications not
+// using CUE:
+//
+//	c, err := configwise.NewConfigurer(cue.WithSchema(data))
+//
+// Evaluate unifies the tree with the schema, fails when the result is not
+// concrete or violates a constraint, and returns the result, which holds the
+// defaults of the schema for values missing in the tree.
+type CUESchema interface {
+	Evaluate(tree map[string]interface{}) (map[string]interface{}, error)
+}
+
+// CUESchemaFunc is an adapter to allow the use of ordinary functions as CUESchema.
+type CUESchemaFunc func(tree map[string]interface{}) (map[string]interface{}, error)
+
+func (f CUESchemaFunc) Evaluate(tree map[string]interface{}) (map[string]interface{}, error) {
+	return f(tree)
+}
+
+// WithCUE hooks the evaluation of the merged config tree by a CUESchema into
+// load and reload, before values are unmarshalled. The tree is evaluated after
+// interpolation and before secret references are resolved, so the schema sees
+// references rather than secrets. Values passed to Overwrite are not evaluated.
+func WithCUE(schema CUESchema) Option {
+	return func(c *configurer) {
+		c.cue = schema
+	}
+}
+
+// evaluateCUE returns the settings evaluated by the CUE schema, if any.
+func (cfg *configurer) evaluateCUE(settings map[string]interface{}) (map[string]interface{}, error) {
+	if cfg.cue == nil {
+		return settings, nil
+	}
+
+	evaluated, err := cfg.cue.Evaluate(deepCopy(settings).(map[string]interface{}))
+	if err != nil {
+		return nil, fmt.Errorf("cue: %w", err)
+	}
+	return normalizeTree(evaluated), nil
+}
@@ -0,0 +1,88 @@
+// Package cue evaluates configwise config trees with CUE schemas. It is a
+// separate package so that only applications validating with CUE link the
+// CUE evaluator:
+//
+//	c, err := configwise.NewConfigurer(cue.WithSchema(data))
+//
+// Compile returns the schema for use with configwise.WithCUE.
+package cue
+
+import (
+	"errors"
+	"sync"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	cueerrors "cuelang.org/go/cue/errors"
+
+	"github.com/gowool/configwise"
+)
+
+// Schema is a compiled CUE schema, which evaluates config trees as
+// configwise.CUESchema.
+type Schema struct {
+	// the CUE context is not safe for concurrent use
+	mu     sync.Mutex
+	ctx    *cue.Context
+	schema cue.Value
+}
+
+var _ configwise.CUESchema = (*Schema)(nil)
+
+// WithSchema evaluates the config tree with the CUE schema like
+// configwise.WithCUE. A schema which does not compile fails NewConfigurer.
+func WithSchema(schema []byte) configwise.Option {
+	compiled, err := Compile(schema)
+	if err != nil {
+		return configwise.WithOptionError(err)
+	}
+	return configwise.WithCUE(compiled)
+}
+
+// Compile compiles the CUE schema, e.g.
+//
+//	http: {
+//		port:    int & >0 & <=65535 | *8080
+//		timeout: string | *"30s"
+//	}
+func Compile(schema []byte) (*Schema, error) {
+	ctx := cuecontext.New()
+	compiled := ctx.CompileBytes(schema, cue.Filename("config.cue"))
+	if err := compiled.Err(); err != nil {
+		return nil, errors.New("cue schema: " + joinErrors(err).Error())
+	}
+	return &Schema{ctx: ctx, schema: compiled}, nil
+}
+
+// Evaluate unifies the config tree with the schema and returns the result,
+// which holds the defaults of the schema for values missing in the tree.
+// Values which violate a constraint or are not concrete after the
+// unification are reported by their paths, e.g.
+// "http.port: invalid value 70000 (out of bound <=65535)".
+func (s *Schema) Evaluate(tree map[string]interface{}) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value := s.schema.Unify(s.ctx.Encode(tree))
+	if err := value.Validate(cue.Concrete(true)); err != nil {
+		return nil, joinErrors(err)
+	}
+	var out map[string]interface{}
+	if err := value.Decode(&out); err != nil {
+		return nil, joinErrors(err)
+	}
+	return out, nil
+}
+
+// joinErrors joins the errors of the CUE error list, which reports only the
+// first of them otherwise.
+func joinErrors(err error) error {
+	var errs []error
+	for _, e := range cueerrors.Errors(err) {
+		errs = append(errs, e)
+	}
+	if len(errs) == 0 {
+		return err
+	}
+	return errors.Join(errs...)
+}
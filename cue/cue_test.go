@@ -0,0 +1,116 @@
+package cue
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gowool/configwise"
+)
+
+const httpSchema = `
+http: {
+	port:    int & >0 & <=65535 | *8080
+	host:    string | *"localhost"
+	timeout: string
+}
+`
+
+func TestEvaluate(t *testing.T) {
+	schema, err := Compile([]byte(httpSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		tree map[string]interface{}
+		want map[string]interface{}
+		err  string
+	}{
+		{
+			name: "defaults",
+			tree: map[string]interface{}{"http": map[string]interface{}{"timeout": "5s"}},
+			want: map[string]interface{}{"http": map[string]interface{}{"port": int64(8080), "host": "localhost", "timeout": "5s"}},
+		},
+		{
+			name: "values",
+			tree: map[string]interface{}{"http": map[string]interface{}{"port": int64(9090), "host": "example.com", "timeout": "5s"}},
+			want: map[string]interface{}{"http": map[string]interface{}{"port": int64(9090), "host": "example.com", "timeout": "5s"}},
+		},
+		{
+			name: "out of bound",
+			tree: map[string]interface{}{"http": map[string]interface{}{"port": 70000, "timeout": "5s"}},
+			err:  "http.port: invalid value 70000 (out of bound <=65535)",
+		},
+		{
+			name: "wrong type",
+			tree: map[string]interface{}{"http": map[string]interface{}{"host": 1, "timeout": "5s"}},
+			err:  "http.host",
+		},
+		{
+			name: "missing value",
+			tree: map[string]interface{}{"http": map[string]interface{}{}},
+			err:  "http.timeout: incomplete value string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schema.Evaluate(tt.tree)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error is %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tree is %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	if _, err := Compile([]byte("http: {port: int &}")); err == nil || !strings.HasPrefix(err.Error(), "cue schema: ") {
+		t.Fatalf("error is %v, want an invalid schema", err)
+	}
+}
+
+func TestWithSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		config string
+		port   int
+		err    string
+	}{
+		{name: "default", schema: httpSchema, config: "http: {timeout: 5s}", port: 8080},
+		{name: "value", schema: httpSchema, config: "http: {port: 9090, timeout: 5s}", port: 9090},
+		{name: "invalid config", schema: httpSchema, config: "http: {port: 0, timeout: 5s}", err: "http.port: invalid value 0 (out of bound >0)"},
+		{name: "invalid schema", schema: "http: {", err: "new -> cue schema: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := configwise.NewConfigurer(
+				WithSchema([]byte(tt.schema)),
+				configwise.WithType("yaml"),
+				configwise.WithReadInConfig([]byte(tt.config)),
+			)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error is %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.GetInt("http.port"); got != tt.port {
+				t.Errorf("http.port is %d, want %d", got, tt.port)
+			}
+		})
+	}
+}
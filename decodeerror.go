@@ -0,0 +1,141 @@
+package configwise
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// DecodeError is a value which could not be decoded into its field.
+// Unmarshal and UnmarshalKey report all of them at once, joined with
+// errors.Join, ordered by key.
+type DecodeError struct {
+	// Key is the full config key of the value, e.g. "http.port".
+	Key string
+	// Reason describes why the value could not be decoded.
+	Reason string
+	// Hook is the name of the failing decode hook, if any, e.g. HookUUID.
+	Hook string
+	// Cause is the error of the failing hook, e.g. the error of uuid.Parse,
+	// or an error with the reason.
+	Cause error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Key == "" {
+		return e.Reason
+	}
+	return e.Key + ": " + e.Reason
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Cause
+}
+
+// decodeErrors converts the errors of the decoder to a DecodeError per
+// failing value, with keys below prefix, ordered by key. Causes are the
+// errors of hooks by their message.
+func decodeErrors(prefix string, err error, causes map[string]error) []*DecodeError {
+	var messages []string
+	var me *mapstructure.Error
+	if errors.As(err, &me) {
+		messages = me.Errors
+	} else {
+		messages = []string{err.Error()}
+	}
+
+	errs := make([]*DecodeError, len(messages))
+	for i, message := range messages {
+		name, reason := splitDecodeMessage(message)
+		key := prefix
+		if name != "" {
+			key = joinKey(prefix, decodedKey(name))
+		}
+		cause, ok := causes[reason]
+		hook, reason := splitHookError(reason)
+		if !ok {
+			cause = errors.New(reason)
+		}
+		errs[i] = &DecodeError{Key: key, Reason: reason, Hook: hook, Cause: cause}
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Key < errs[j].Key
+	})
+	return errs
+}
+
+// joinDecodeErrors joins the errors, or returns nil if there are none.
+func joinDecodeErrors(errs []*DecodeError) error {
+	joined := make([]error, len(errs))
+	for i, e := range errs {
+		joined[i] = e
+	}
+	return errors.Join(joined...)
+}
+
+// splitDecodeMessage splits a message of the decoder, which quotes the name
+// of the value, e.g. "cannot parse 'HTTP.Port' as int: ...", into the name
+// and the message without it.
+func splitDecodeMessage(message string) (string, string) {
+	if rest, ok := strings.CutPrefix(message, "error decoding '"); ok {
+		if name, reason, ok := strings.Cut(rest, "': "); ok {
+			return name, reason
+		}
+	}
+
+	start := strings.IndexByte(message, '\'')
+	if start == -1 {
+		return "", message
+	}
+	end := strings.IndexByte(message[start+1:], '\'')
+	if end == -1 {
+		return "", message
+	}
+	end += start + 1
+
+	name := message[start+1 : end]
+	reason := strings.TrimSpace(message[:start] + strings.TrimLeft(message[end+1:], ":"))
+	reason = strings.Join(strings.Fields(reason), " ")
+	return name, reason
+}
+
+// decodedKey converts a name of the decoder, e.g. "HTTP.Hosts[1]" or
+// "Labels[env]", to a config key, e.g. "http.hosts[1]" or "labels.env".
+func decodedKey(name string) string {
+	var b strings.Builder
+	for name != "" {
+		open := strings.IndexByte(name, '[')
+		if open == -1 {
+			b.WriteString(name)
+			break
+		}
+		b.WriteString(name[:open])
+		end := strings.IndexByte(name[open:], ']')
+		if end == -1 {
+			b.WriteString(name[open:])
+			break
+		}
+		index := name[open+1 : open+end]
+		if isIndex(index) {
+			b.WriteString(name[open : open+end+1])
+		} else {
+			b.WriteString(keyDelimiter + index)
+		}
+		name = name[open+end+1:]
+	}
+	return strings.ToLower(b.String())
+}
+
+func isIndex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package configwise
+
+import (
+	"reflect"
+)
+
+// WithDefaults loads a default config document of the type, usually embedded
+// in the binary, under all other sources, so the binary ships complete
+// defaults and config files only carry overrides.
+//
+//	//go:embed defaults.yaml
+//	var defaults []byte
+//
+//	configwise.WithDefaults(defaults, "yaml")
+func WithDefaults(data []byte, configType string) Option {
+	return func(c *configurer) {
+		c.defaultDocs = append(c.defaultDocs, defaultDoc{data: data, configType: configType})
+	}
+}
+
+type defaultDoc struct {
+	data       []byte
+	configType string
+}
+
+// defaultTag holds the default value of a field, e.g. `default:"8080"`.
+// Lists are written comma separated.
+const defaultTag = "default"
+
+// structDefaults returns the tree of the default tags of the struct type.
+func structDefaults(t reflect.Type) map[string]interface{} {
+	defaults := make(map[string]interface{})
+	walkStruct(t, "", func(field schemaField) bool {
+		if value, ok := field.Field.Tag.Lookup(defaultTag); ok {
+			setPath(defaults, field.Path, value)
+			return false
+		}
+		return true
+	})
+	return defaults
+}
+
+// withDefaults returns the input overlaid on the defaults of the target
+// struct. Inputs which are not sections are returned as is.
+func withDefaults(input interface{}, out interface{}) interface{} {
+	t := reflect.TypeOf(out)
+	if t == nil || !isStruct(t) {
+		return input
+	}
+
+	defaults := structDefaults(t)
+	if len(defaults) == 0 {
+		return input
+	}
+
+	switch v := input.(type) {
+	case nil:
+		return defaults
+	case map[string]interface{}:
+		deepMerge(defaults, v)
+		return defaults
+	default:
+		return input
+	}
+}
+
+// defaulter is implemented by config structs which set their own defaults.
+type defaulter interface {
+	Defaults()
+}
+
+// callDefaults calls Defaults on the target before decoding, so that values
+// of the config replace the defaults. Default tags take precedence over it.
+func callDefaults(out interface{}) {
+	if d, ok := out.(defaulter); ok {
+		d.Defaults()
+	}
+}
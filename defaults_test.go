@@ -0,0 +1,92 @@
+package configwise
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type defaultedServer struct {
+	Host    string        `default:"localhost"`
+	Port    int           `default:"8080"`
+	Timeout time.Duration `default:"30s"`
+	Debug   bool          `default:"true"`
+	Hosts   []string      `default:"a,b"`
+	TLS     struct {
+		Enabled bool   `default:"false"`
+		Cert    string `default:"/etc/certs/tls.crt"`
+	}
+	Limits *struct {
+		RPS int `default:"100"`
+	}
+}
+
+func TestDefaultTags(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		want   func(s *defaultedServer)
+	}{
+		{
+			name: "missing section",
+		},
+		{
+			name:   "values replace defaults",
+			config: map[string]interface{}{"server": map[string]interface{}{"port": 9090, "hosts": []interface{}{"c"}, "debug": false}},
+			want: func(s *defaultedServer) {
+				s.Port, s.Hosts, s.Debug = 9090, []string{"c"}, false
+			},
+		},
+		{
+			name:   "nested sections are merged",
+			config: map[string]interface{}{"server": map[string]interface{}{"tls": map[string]interface{}{"enabled": true}}},
+			want: func(s *defaultedServer) {
+				s.TLS.Enabled = true
+			},
+		},
+		{
+			name:   "section of a pointer",
+			config: map[string]interface{}{"server": map[string]interface{}{"limits": map[string]interface{}{}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewConfigurer(WithConfigMap(tt.config))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got defaultedServer
+			if err := c.UnmarshalKey("server", &got); err != nil {
+				t.Fatal(err)
+			}
+
+			want := defaultedServer{Host: "localhost", Port: 8080, Timeout: 30 * time.Second, Debug: true, Hosts: []string{"a", "b"}}
+			want.TLS.Cert = "/etc/certs/tls.crt"
+			want.Limits = &struct {
+				RPS int `default:"100"`
+			}{RPS: 100}
+			if tt.want != nil {
+				tt.want(&want)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("server is %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestDefaultTagsOfUnmarshal(t *testing.T) {
+	c, err := NewConfigurer(WithConfigMap(map[string]interface{}{"port": 9090}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got defaultedServer
+	if err := c.Unmarshal(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Host != "localhost" || got.Port != 9090 || got.TLS.Cert != "/etc/certs/tls.crt" {
+		t.Errorf("config is %+v, want the defaults below the values", got)
+	}
+}
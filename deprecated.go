@@ -0,0 +1,113 @@
+package configwise
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// WithDeprecatedKeys renames keys, mapping old keys to new ones, e.g.
+// {"server.addr": "http.address"}. Values set under an old key by any source
+// are moved to the new key, unless the new key is set as well, and reported
+// as WarningDeprecatedKey. Reads of old keys, e.g. Get("server.addr"),
+// resolve to the new key and are reported once.
+func WithDeprecatedKeys(keys map[string]string) Option {
+	return func(c *configurer) {
+		if c.deprecated == nil {
+			c.deprecated = make(map[string]string, len(keys))
+		}
+		for old, key := range keys {
+			c.deprecated[strings.ToLower(old)] = strings.ToLower(key)
+		}
+	}
+}
+
+// deprecations records the deprecated keys read, so each is reported once.
+type deprecations struct {
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+// renamedKey returns the key with a deprecated key, or a deprecated parent,
+// replaced by the new one.
+func (cfg *configurer) renamedKey(key string) (string, string, bool) {
+	for k := key; k != ""; k = parentKey(k) {
+		if renamed, ok := cfg.deprecated[k]; ok {
+			return renamed + key[len(k):], k, true
+		}
+	}
+	return key, "", false
+}
+
+// renameDeprecated returns the new key for reads of a deprecated key and
+// reports the first read of every deprecated key as warning.
+func (cfg *configurer) renameDeprecated(key string) string {
+	renamed, old, ok := cfg.renamedKey(key)
+	if !ok {
+		return key
+	}
+
+	d := &cfg.deprecations
+	d.mu.Lock()
+	_, seen := d.seen[old]
+	if !seen {
+		if d.seen == nil {
+			d.seen = make(map[string]struct{})
+		}
+		d.seen[old] = struct{}{}
+	}
+	d.mu.Unlock()
+
+	if !seen {
+		cfg.addWarnings(Warning{Kind: WarningDeprecatedKey, Key: old, Name: cfg.deprecated[old]})
+	}
+	return renamed
+}
+
+// moveDeprecated moves the values of deprecated keys in the settings to the
+// new keys, along with their origins and positions. The settings must not be shared.
+func (cfg *configurer) moveDeprecated(settings map[string]interface{}, origins map[string]Provider, positions map[Provider]map[string]Position) []Warning {
+	if len(cfg.deprecated) == 0 {
+		return nil
+	}
+
+	olds := make([]string, 0, len(cfg.deprecated))
+	for old := range cfg.deprecated {
+		olds = append(olds, old)
+	}
+	sort.Strings(olds)
+
+	var warnings []Warning
+	for _, old := range olds {
+		value, ok := searchPath(settings, old)
+		if !ok {
+			continue
+		}
+		key := cfg.deprecated[old]
+		warnings = append(warnings, Warning{Kind: WarningDeprecatedKey, Key: old, Name: key})
+
+		deletePath(settings, old)
+		if _, exists := searchPath(settings, key); exists {
+			continue
+		}
+		setPath(settings, key, value)
+
+		for k, p := range origins {
+			if covers(old, k) {
+				delete(origins, k)
+				origins[key+k[len(old):]] = p
+			}
+		}
+		for p, pos := range positions {
+			moved := make(map[string]Position, len(pos))
+			for k, at := range pos {
+				if covers(old, k) {
+					k = key + k[len(old):]
+				}
+				moved[k] = at
+			}
+			positions[p] = moved
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,92 @@
+package configwise
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeprecatedKeys(t *testing.T) {
+	t.Setenv("CONFIGWISE_TEST_LOGGING_LEVEL", "debug")
+
+	tests := []struct {
+		name     string
+		config   string
+		key      string
+		want     interface{}
+		warnings []Warning
+	}{
+		{
+			name:     "renamed value",
+			config:   "server: {addr: ':80'}",
+			key:      "http.address",
+			want:     ":80",
+			warnings: []Warning{{Kind: WarningDeprecatedKey, Key: "server.addr", Name: "http.address"}},
+		},
+		{
+			name:     "new key wins",
+			config:   "server: {addr: ':80'}\nhttp: {address: ':8080'}",
+			key:      "http.address",
+			want:     ":8080",
+			warnings: []Warning{{Kind: WarningDeprecatedKey, Key: "server.addr", Name: "http.address"}},
+		},
+		{
+			name:     "renamed section",
+			config:   "logging: {format: json}",
+			key:      "log.format",
+			want:     "json",
+			warnings: []Warning{{Kind: WarningDeprecatedKey, Key: "logging", Name: "log"}},
+		},
+		{
+			name:     "environment",
+			config:   "logging: {level: info}",
+			key:      "log.level",
+			want:     "debug",
+			warnings: []Warning{{Kind: WarningDeprecatedKey, Key: "logging", Name: "log"}},
+		},
+		{
+			name:   "current keys",
+			config: "http: {address: ':8080'}",
+			key:    "http.address",
+			want:   ":8080",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewConfigurer(
+				WithPrefix("configwise_test"),
+				WithDeprecatedKeys(map[string]string{"Server.Addr": "http.address", "logging": "log"}),
+				WithType("yaml"),
+				WithReadInConfig([]byte(tt.config)),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.Get(tt.key); got != tt.want {
+				t.Errorf("%s is %v, want %v", tt.key, got, tt.want)
+			}
+			if got := c.Warnings(); !reflect.DeepEqual(got, tt.warnings) {
+				t.Errorf("warnings are %v, want %v", got, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestDeprecatedKeyReads(t *testing.T) {
+	c, err := NewConfigurer(
+		WithDeprecatedKeys(map[string]string{"server.addr": "http.address"}),
+		WithConfigMap(map[string]interface{}{"http": map[string]interface{}{"address": ":80"}}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		if got := c.GetString("server.addr"); got != ":80" {
+			t.Errorf("server.addr is %q, want :80", got)
+		}
+	}
+	want := []Warning{{Kind: WarningDeprecatedKey, Key: "server.addr", Name: "http.address"}}
+	if got := c.Warnings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("warnings are %v, want the read reported once", got)
+	}
+}
@@ -0,0 +1,134 @@
+package configwise
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// Change describes a key whose value differs between two configurations.
+type Change struct {
+	Key string
+	// Old and New are the values in the compared configurations, with secrets
+	// redacted. Old is nil for added keys, New for removed keys.
+	Old interface{}
+	New interface{}
+	// Source is the kind of source of the new value, empty for removed keys.
+	Source Source
+}
+
+// diffView is the state of a configuration compared by Diff.
+type diffView struct {
+	settings map[string]interface{}
+	redacted map[string]interface{}
+	origin   func(key string) Source
+}
+
+// Diff returns the changes from the configuration to other, ordered by key,
+// e.g. to review a reload against a snapshot taken before it, or the files of
+// staging against those of production. Only leaves are compared. Secrets are
+// compared by value but reported redacted.
+func (cfg *configurer) Diff(other ReadOnlyConfig) []Change {
+	return diffViews(cfg.diffView(""), viewOf(other))
+}
+
+// diffView returns the state of the section stored under root, with keys
+// relative to the section.
+func (cfg *configurer) diffView(root string) diffView {
+	_, value, _ := cfg.subtree(root)
+	settings, ok := value.(map[string]interface{})
+	if !ok {
+		settings = map[string]interface{}{}
+	}
+	return diffView{
+		settings: settings,
+		redacted: cfg.redactSection(root),
+		origin: func(key string) Source {
+			return cfg.Origin(joinKey(root, key))
+		},
+	}
+}
+
+// viewOf returns the state of a configuration for Diff. Settings of foreign
+// implementations are reported as they are.
+func viewOf(c ReadOnlyConfig) diffView {
+	switch c := c.(type) {
+	case *configurer:
+		return c.diffView("")
+	case *subConfigurer:
+		return c.parent.diffView(c.root)
+	}
+	settings := c.Settings()
+	return diffView{settings: settings, redacted: settings, origin: c.Origin}
+}
+
+// diffViews compares two configurations.
+func diffViews(oldView, newView diffView) []Change {
+	keyChanges := diffSettings(oldView.settings, newView.settings)
+	changes := make([]Change, 0, len(keyChanges))
+	for _, kc := range keyChanges {
+		change := Change{Key: kc.key}
+		if !kc.added {
+			change.Old = redactedLeaf(oldView.redacted, kc.key)
+		}
+		if !kc.removed {
+			change.New = redactedLeaf(newView.redacted, kc.key)
+			change.Source = newView.origin(kc.key)
+		}
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// redactedLeaf returns the value of the key in a redacted tree. Keys below a
+// secret section, which is replaced as a whole, are redacted.
+func redactedLeaf(redacted map[string]interface{}, key string) interface{} {
+	if value, ok := searchPath(redacted, key); ok {
+		return value
+	}
+	return RedactedValue
+}
+
+// keyChange describes a single modified leaf of the settings tree.
+type keyChange struct {
+	key      string
+	oldValue interface{}
+	newValue interface{}
+	added    bool
+	removed  bool
+}
+
+// diffSettings compares the leaves of two settings trees.
+func diffSettings(oldTree, newTree map[string]interface{}) []keyChange {
+	var changes []keyChange
+
+	for _, key := range leafKeys(oldTree) {
+		oldValue, _ := searchPath(oldTree, key)
+		newValue, ok := searchPath(newTree, key)
+		switch {
+		case !ok:
+			changes = append(changes, keyChange{key: key, oldValue: oldValue, removed: true})
+		case !reflect.DeepEqual(oldValue, newValue):
+			changes = append(changes, keyChange{key: key, oldValue: oldValue, newValue: newValue})
+		}
+	}
+
+	for _, key := range leafKeys(newTree) {
+		if _, ok := searchPath(oldTree, key); !ok {
+			newValue, _ := searchPath(newTree, key)
+			changes = append(changes, keyChange{key: key, newValue: newValue, added: true})
+		}
+	}
+	return changes
+}
+
+// fingerprint returns a stable hash of the settings tree.
+func fingerprint(settings map[string]interface{}) string {
+	// json.Marshal sorts map keys which makes the output canonical
+	data, _ := json.Marshal(settings)
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
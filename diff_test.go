@@ -0,0 +1,68 @@
+package configwise
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	t.Setenv("CONFIGWISE_TEST_DB_HOST", "db.internal")
+
+	oldConfig, err := NewConfigurer(
+		WithSecretKeys("db.password"),
+		WithType("yaml"),
+		WithReadInConfig([]byte("db: {host: localhost, port: 5432, password: old}\nname: app\ndebug: true")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newConfig, err := NewConfigurer(
+		WithPrefix("configwise_test"),
+		WithSecretKeys("db.password"),
+		WithType("yaml"),
+		WithReadInConfig([]byte("db: {host: localhost, port: 5432, password: new}\nname: app\nregion: eu")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Change{
+		{Key: "db.host", Old: "localhost", New: "db.internal", Source: SourceEnv},
+		{Key: "db.password", Old: RedactedValue, New: RedactedValue, Source: SourceFile},
+		{Key: "debug", Old: true},
+		{Key: "region", New: "eu", Source: SourceFile},
+	}
+	if got := oldConfig.Diff(newConfig); !reflect.DeepEqual(got, want) {
+		t.Errorf("changes are %+v, want %+v", got, want)
+	}
+	if got := newConfig.Diff(newConfig); len(got) != 0 {
+		t.Errorf("changes of the same configuration are %+v", got)
+	}
+}
+
+func TestDiffOfSnapshot(t *testing.T) {
+	c, err := NewConfigurer(WithConfigMap(map[string]interface{}{"server": map[string]interface{}{"port": 80}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := c.Snapshot()
+	if err := c.Overwrite(map[string]interface{}{"server.port": 8080}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Change{{Key: "server.port", Old: 80, New: 8080, Source: SourceOverride}}
+	if got := before.Diff(c); !reflect.DeepEqual(got, want) {
+		t.Errorf("changes are %+v, want %+v", got, want)
+	}
+
+	// keys of sections are relative to them
+	other, err := NewConfigurer(WithConfigMap(map[string]interface{}{"server": map[string]interface{}{"port": 9090}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []Change{{Key: "port", Old: 8080, New: 9090, Source: SourceDefault}}
+	if got := c.Sub("server").Diff(other.Sub("server")); !reflect.DeepEqual(got, want) {
+		t.Errorf("changes of the section are %+v, want %+v", got, want)
+	}
+}
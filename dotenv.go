@@ -0,0 +1,77 @@
+package configwise
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/subosito/gotenv"
+)
+
+// WithDotEnv loads the variables of dotenv files into the environment layer,
+// like docker-compose does for local development. Later files override
+// earlier ones and variables of the process environment override all of
+// them. Missing files are skipped, so optional files like .env.local may be
+// listed. The variables are also available to ${NAME} references. The files
+// are read again on every reload.
+//
+//	configwise.WithDotEnv(".env", ".env.local")
+func WithDotEnv(files ...string) Option {
+	return func(c *configurer) {
+		if c.dotEnv == nil {
+			c.dotEnv = &dotEnv{}
+		}
+		c.dotEnv.files = append(c.dotEnv.files, files...)
+	}
+}
+
+// dotEnv holds the variables of the dotenv files of the last read.
+type dotEnv struct {
+	files []string
+
+	mu   sync.RWMutex
+	vars map[string]string
+}
+
+func (d *dotEnv) load() error {
+	vars := make(map[string]string)
+	for _, file := range d.files {
+		f, err := os.Open(file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		env, err := gotenv.StrictParse(f)
+		_ = f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		for name, value := range env {
+			vars[name] = value
+		}
+	}
+
+	d.mu.Lock()
+	d.vars = vars
+	d.mu.Unlock()
+	return nil
+}
+
+func (d *dotEnv) lookup(name string) (string, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	value, ok := d.vars[name]
+	return value, ok
+}
+
+// resolver resolves references to environment variables with the dotenv
+// variables, after the process environment.
+func (d *dotEnv) resolver() Resolver {
+	return NewResolver(envResolverPrefix, func(name string) (string, error) {
+		value, _ := d.lookup(name)
+		return value, nil
+	})
+}
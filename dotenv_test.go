@@ -0,0 +1,74 @@
+package configwise
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDotEnv(t *testing.T) {
+	t.Setenv("CONFIGWISE_TEST_DB_USER", "process")
+
+	dir := t.TempDir()
+	env, local := filepath.Join(dir, ".env"), filepath.Join(dir, ".env.local")
+	files := map[string]string{
+		env:   "CONFIGWISE_TEST_DB_HOST=db.internal\nCONFIGWISE_TEST_DB_PORT=5432\nCONFIGWISE_TEST_DB_USER=dotenv\nDB_NAME=orders\n",
+		local: "# local overrides\nCONFIGWISE_TEST_DB_PORT=6432\n",
+	}
+	for file, data := range files {
+		if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c, err := NewConfigurer(
+		WithPrefix("configwise_test"),
+		WithDotEnv(env, local, filepath.Join(dir, ".env.missing")),
+		WithType("yaml"),
+		WithReadInConfig([]byte("db: {host: localhost, port: 0, user: file, dsn: 'postgres:///${DB_NAME}'}")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "db.host", want: "db.internal"},
+		{key: "db.port", want: "6432"},
+		{key: "db.user", want: "process"},
+		{key: "db.dsn", want: "postgres:///orders"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := c.GetString(tt.key); got != tt.want {
+				t.Errorf("%s is %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+
+	// files are read again on reloads
+	if err := os.WriteFile(local, []byte("CONFIGWISE_TEST_DB_PORT=7432\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.(*configurer).reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.GetInt("db.port"); got != 7432 {
+		t.Errorf("db.port is %d after a reload, want 7432", got)
+	}
+}
+
+func TestDotEnvError(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(file, []byte("not a variable\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := NewConfigurer(WithDotEnv(file), WithConfigMap(map[string]interface{}{"a": 1}))
+	if err == nil || !strings.Contains(err.Error(), file+": ") {
+		t.Fatalf("error is %v, want the error of %s", err, file)
+	}
+}
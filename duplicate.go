@@ -0,0 +1,133 @@
+package configwise
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrDuplicateKey is reported for keys defined twice in the same YAML mapping.
+var ErrDuplicateKey = errors.New("duplicate key")
+
+// WithLenientDuplicateKeys reports keys defined twice in the same mapping of
+// a YAML document as warnings, taking the last value, instead of failing the
+// load. Keys are compared case-insensitively, like they are looked up.
+func WithLenientDuplicateKeys() Option {
+	return func(c *configurer) {
+		c.lenientDuplicates = true
+	}
+}
+
+// duplicateKey is a key defined twice in the same mapping.
+type duplicateKey struct {
+	key   string
+	first Position
+	dup   Position
+}
+
+// duplicateReporter is implemented by providers which detect duplicate keys.
+type duplicateReporter interface {
+	// duplicateKeys returns the duplicate keys of the last Read.
+	duplicateKeys() []duplicateKey
+}
+
+// yamlDuplicates returns the keys defined more than once in a mapping of one
+// of the YAML documents.
+func yamlDuplicates(file string, data []byte) []duplicateKey {
+	var dups []duplicateKey
+	var walk func(key string, node *yaml.Node)
+	walk = func(key string, node *yaml.Node) {
+		switch node.Kind {
+		case yaml.MappingNode:
+			seen := make(map[string]*yaml.Node)
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				k, v := node.Content[i], node.Content[i+1]
+				if k.Value == "<<" {
+					continue
+				}
+				child := joinKey(key, strings.ToLower(k.Value))
+				if first, ok := seen[child]; ok {
+					dups = append(dups, duplicateKey{
+						key:   child,
+						first: Position{File: file, Line: first.Line, Column: first.Column},
+						dup:   Position{File: file, Line: k.Line, Column: k.Column},
+					})
+				}
+				seen[child] = k
+				walk(child, v)
+			}
+		case yaml.SequenceNode:
+			for i, item := range node.Content {
+				walk(fmt.Sprintf("%s[%d]", key, i), item)
+			}
+		}
+	}
+
+	d := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		if err := d.Decode(&doc); err != nil {
+			break
+		}
+		if len(doc.Content) > 0 {
+			walk("", doc.Content[0])
+		}
+	}
+	return dups
+}
+
+// dropDuplicates removes all but the last definition of keys defined more
+// than once in a mapping, so decoding takes the last value.
+func dropDuplicates(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			dropDuplicates(child)
+		}
+	case yaml.MappingNode:
+		last := make(map[string]int)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if k := node.Content[i].Value; k != "<<" {
+				last[strings.ToLower(k)] = i
+			}
+		}
+		content := node.Content[:0]
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, v := node.Content[i], node.Content[i+1]
+			if k.Value != "<<" && last[strings.ToLower(k.Value)] != i {
+				continue
+			}
+			dropDuplicates(v)
+			content = append(content, k, v)
+		}
+		node.Content = content
+	}
+}
+
+// checkDuplicates returns the duplicate keys of the provider as warnings
+// when duplicates are lenient, or as error.
+func (cfg *configurer) checkDuplicates(p Provider) ([]Warning, error) {
+	r, ok := p.(duplicateReporter)
+	if !ok {
+		return nil, nil
+	}
+
+	var (
+		warnings []Warning
+		errs     []error
+	)
+	for _, d := range r.duplicateKeys() {
+		if cfg.lenientDuplicates {
+			warnings = append(warnings, Warning{Kind: WarningDuplicateKey, Key: d.key, Name: d.first.String()})
+			continue
+		}
+		errs = append(errs, &PositionError{Position: d.dup, Err: &KeyError{
+			Key: d.key,
+			Err: fmt.Errorf("%w, first defined at %d:%d", ErrDuplicateKey, d.first.Line, d.first.Column),
+		}})
+	}
+	return warnings, errors.Join(errs...)
+}
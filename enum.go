@@ -0,0 +1,73 @@
+package configwise
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// enumTag restricts a string field, or the elements of a string list, to a
+// set of comma separated values, e.g. `enum:"debug,info,warn,error"`.
+const enumTag = "enum"
+
+// ErrEnum is reported for every value which is not in the set of its enum tag.
+var ErrEnum = errors.New("value is not allowed")
+
+// enumValues returns the allowed values of the field, if it has an enum tag.
+func enumValues(field reflect.StructField) ([]string, bool) {
+	tag, ok := field.Tag.Lookup(enumTag)
+	if !ok {
+		return nil, false
+	}
+	values := strings.Split(tag, ",")
+	for i, value := range values {
+		values[i] = strings.TrimSpace(value)
+	}
+	return values, true
+}
+
+// checkEnums returns an error listing every value of the decoded struct which
+// is not allowed by its enum tag, with messages in the language. Empty values
+// are left to required checks.
+func checkEnums(lang, prefix string, out interface{}) error {
+	var errs []error
+	check := func(path string, allowed []string, value reflect.Value) {
+		s := value.String()
+		if s == "" {
+			return
+		}
+		for _, a := range allowed {
+			if s == a {
+				return
+			}
+		}
+		msg := translate(lang, MsgEnum, s, strings.Join(allowed, ", "))
+		errs = append(errs, &KeyError{Key: path, Err: &messageError{msg: msg, err: ErrEnum}})
+	}
+
+	walkValue(reflect.ValueOf(out), prefix, func(path string, field reflect.StructField, value reflect.Value) bool {
+		allowed, ok := enumValues(field)
+		if !ok {
+			return true
+		}
+		for value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				return false
+			}
+			value = value.Elem()
+		}
+		switch value.Kind() {
+		case reflect.String:
+			check(path, allowed, value)
+		case reflect.Slice, reflect.Array:
+			if value.Type().Elem().Kind() == reflect.String {
+				for i := 0; i < value.Len(); i++ {
+					check(path+"["+strconv.Itoa(i)+"]", allowed, value.Index(i))
+				}
+			}
+		}
+		return false
+	})
+	return errors.Join(errs...)
+}
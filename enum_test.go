@@ -0,0 +1,74 @@
+package configwise
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEnum(t *testing.T) {
+	type logging struct {
+		Level   string   `enum:"debug, info, warn, error"`
+		Outputs []string `enum:"stdout,stderr,file"`
+		Format  *string  `enum:"json,text"`
+	}
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		err    []string
+	}{
+		{
+			name:   "allowed values",
+			config: map[string]interface{}{"level": "warn", "outputs": []interface{}{"stdout", "file"}, "format": "json"},
+		},
+		{
+			name:   "empty values",
+			config: map[string]interface{}{"level": ""},
+		},
+		{
+			name:   "value not allowed",
+			config: map[string]interface{}{"level": "verbose"},
+			err:    []string{`logging.level: value is not allowed: "verbose", accepted: debug, info, warn, error`},
+		},
+		{
+			name:   "case sensitive",
+			config: map[string]interface{}{"level": "INFO"},
+			err:    []string{`logging.level: value is not allowed: "INFO"`},
+		},
+		{
+			name:   "list elements",
+			config: map[string]interface{}{"outputs": []interface{}{"stdout", "syslog", "file", "null"}},
+			err:    []string{`logging.outputs[1]: value is not allowed: "syslog"`, `logging.outputs[3]: value is not allowed: "null"`},
+		},
+		{
+			name:   "pointer",
+			config: map[string]interface{}{"format": "xml"},
+			err:    []string{`logging.format: value is not allowed: "xml", accepted: json, text`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewConfigurer(WithConfigMap(map[string]interface{}{"logging": tt.config}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = c.UnmarshalKey("logging", new(logging))
+			if len(tt.err) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrEnum) {
+				t.Fatalf("error is %v, want ErrEnum", err)
+			}
+			for _, want := range tt.err {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error is %v, want %q", err, want)
+				}
+			}
+		})
+	}
+}
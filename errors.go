@@ -0,0 +1,43 @@
+package configwise
+
+import "errors"
+
+var (
+	// ErrConfigNotFound is returned when a config file which must exist is missing.
+	ErrConfigNotFound = errors.New("config file not found")
+	// ErrInvalidFlag is returned for flags which are not of the form <key>=<value>.
+	ErrInvalidFlag = errors.New("invalid flag")
+)
+
+// Error is returned by the operations of the configurer. Op is the failing
+// operation, e.g. OpUnmarshalKey, and Err the cause, which may be matched
+// with errors.Is against the sentinel errors of the package, like
+// ErrInvalidFlag or ErrRequired, and with errors.As against error types like
+// DecodeError, ValidationError or KeyError.
+type Error struct {
+	Op  string
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Op + " " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// messageError is an error with a message of its own, e.g. a translated
+// message, which matches the wrapped error.
+type messageError struct {
+	msg string
+	err error
+}
+
+func (e *messageError) Error() string {
+	return e.msg
+}
+
+func (e *messageError) Unwrap() error {
+	return e.err
+}
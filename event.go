@@ -0,0 +1,266 @@
+package configwise
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// EventType identifies the kind of lifecycle event.
+type EventType string
+
+const (
+	// EventLoaded is published once the initial configuration is loaded.
+	EventLoaded EventType = "loaded"
+	// EventReloaded is published after a reload changed the configuration.
+	EventReloaded EventType = "reloaded"
+	// EventReloadFailed is published when a reload failed, the previous configuration stays active.
+	EventReloadFailed EventType = "reload_failed"
+	// EventKeyChanged is published for every key changed by a reload or Overwrite.
+	EventKeyChanged EventType = "key_changed"
+	// EventSecretRotated is published when a value resolved from a secret reference changed.
+	EventSecretRotated EventType = "secret_rotated"
+	// EventProviderDown is published when a provider starts failing.
+	EventProviderDown EventType = "provider_down"
+	// EventProviderUp is published when a failing provider recovered.
+	EventProviderUp EventType = "provider_up"
+	// EventKeyStale is published when the source of a critical key has not
+	// refreshed within the max staleness of the key.
+	EventKeyStale EventType = "key_stale"
+	// EventLagged is delivered to a subscription in place of the events
+	// dropped because it fell behind. Consumers should resynchronize with a
+	// full snapshot of the config.
+	EventLagged EventType = "lagged"
+)
+
+// eventBufferSize is the default capacity of subscription channels.
+const eventBufferSize = 64
+
+// OverflowPolicy defines what happens to events published to a subscription
+// whose buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowDropNewest drops the published events until the consumer caught up.
+	OverflowDropNewest OverflowPolicy = iota
+	// OverflowDropOldest drops the oldest buffered events to make room for
+	// the published ones, so the consumer always sees the latest changes.
+	OverflowDropOldest
+	// OverflowBlock blocks the publisher, and thereby reloads and
+	// Overwrite, until the consumer received the event or unsubscribed.
+	OverflowBlock
+)
+
+// WithEventBuffer sets the capacity of the channels returned by Subscribe
+// and what happens when a consumer does not keep up. Whenever events are
+// dropped, the consumer receives an EventLagged with the number of dropped
+// events before any later event. The channel returned by Events is shared
+// by all callers and always drops the newest events.
+func WithEventBuffer(size int, policy OverflowPolicy) Option {
+	return func(c *configurer) {
+		c.eventBuffer = size
+		c.overflow = policy
+	}
+}
+
+// Event is a lifecycle event of the configurer.
+type Event struct {
+	Type EventType
+	Time time.Time
+	// Key is set for EventKeyChanged, EventSecretRotated and EventKeyStale.
+	Key      string
+	OldValue interface{}
+	NewValue interface{}
+	// Provider is set for EventProviderDown, EventProviderUp and EventKeyStale.
+	Provider string
+	// Err is set for EventReloadFailed and EventProviderDown.
+	Err error
+	// Dropped is the number of dropped events, set for EventLagged.
+	Dropped int
+}
+
+type subscription struct {
+	ch     chan Event
+	types  map[EventType]struct{}
+	policy OverflowPolicy
+	// done is closed when the subscription ends, to release a blocked publisher
+	done chan struct{}
+	// number of dropped events not yet reported as EventLagged
+	dropped int
+}
+
+func (s *subscription) accepts(t EventType) bool {
+	if len(s.types) == 0 {
+		return true
+	}
+	_, ok := s.types[t]
+	return ok
+}
+
+// deliver passes the event to the subscription according to its policy.
+func (s *subscription) deliver(event Event) {
+	switch s.policy {
+	case OverflowBlock:
+		select {
+		case s.ch <- event:
+		case <-s.done:
+		}
+	case OverflowDropOldest:
+		if len(s.ch) == cap(s.ch) {
+			s.dropOldest()
+		}
+		s.ch <- event
+	default:
+		if s.dropped > 0 {
+			select {
+			case s.ch <- Event{Type: EventLagged, Time: event.Time, Dropped: s.dropped}:
+				s.dropped = 0
+			default:
+				s.dropped++
+				return
+			}
+		}
+		select {
+		case s.ch <- event:
+		default:
+			s.dropped++
+		}
+	}
+}
+
+// dropOldest drops the oldest buffered events, keeping room for a new event,
+// and puts an EventLagged with the number of dropped events in front of the
+// remaining ones.
+func (s *subscription) dropOldest() {
+	var kept []Event
+drain:
+	for {
+		select {
+		case event := <-s.ch:
+			kept = append(kept, event)
+		default:
+			break drain
+		}
+	}
+
+	dropped := 0
+	if len(kept) > 0 && kept[0].Type == EventLagged {
+		dropped = kept[0].Dropped
+		kept = kept[1:]
+	}
+	// room for the lagged event and the new event
+	for len(kept) > cap(s.ch)-2 {
+		kept = kept[1:]
+		dropped++
+	}
+
+	if dropped > 0 {
+		s.ch <- Event{Type: EventLagged, Time: time.Now(), Dropped: dropped}
+	}
+	for _, event := range kept {
+		s.ch <- event
+	}
+}
+
+// eventBus fans events out to subscriptions. Events published to a full
+// subscription are handled according to its OverflowPolicy.
+type eventBus struct {
+	mu   sync.Mutex
+	subs []*subscription
+}
+
+func (b *eventBus) subscribe(size int, policy OverflowPolicy, types ...EventType) *subscription {
+	if size <= 0 {
+		size = eventBufferSize
+	}
+	if policy == OverflowDropOldest && size < 2 {
+		// room for an event and the lagged event preceding it
+		size = 2
+	}
+	sub := &subscription{
+		ch:     make(chan Event, size),
+		policy: policy,
+		done:   make(chan struct{}),
+	}
+	if len(types) > 0 {
+		sub.types = make(map[EventType]struct{}, len(types))
+		for _, t := range types {
+			sub.types[t] = struct{}{}
+		}
+	}
+
+	b.mu.Lock()
+	b.subs = append(b.subs, sub)
+	b.mu.Unlock()
+	return sub
+}
+
+func (b *eventBus) unsubscribe(sub *subscription) {
+	// release a publisher blocked on the subscription before taking the lock
+	close(sub.done)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, s := range b.subs {
+		if s == sub {
+			b.subs = append(b.subs[:i], b.subs[i+1:]...)
+			close(sub.ch)
+			return
+		}
+	}
+}
+
+func (b *eventBus) publish(events ...Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	for _, event := range events {
+		if event.Time.IsZero() {
+			event.Time = now
+		}
+		for _, sub := range b.subs {
+			if sub.accepts(event.Type) {
+				sub.deliver(event)
+			}
+		}
+	}
+}
+
+// Events returns the channel receiving all lifecycle events. The channel is
+// shared by all callers and exists from the start, so it also holds EventLoaded.
+func (cfg *configurer) Events() <-chan Event {
+	return cfg.events.ch
+}
+
+// Subscribe returns a channel receiving events of the given types, or all
+// events when no type is given. The channel is closed when ctx is done.
+// Buffering and slow consumers are handled as set with WithEventBuffer.
+func (cfg *configurer) Subscribe(ctx context.Context, types ...EventType) <-chan Event {
+	sub := cfg.bus.subscribe(cfg.eventBuffer, cfg.overflow, types...)
+	go func() {
+		<-ctx.Done()
+		cfg.bus.unsubscribe(sub)
+	}()
+	return sub.ch
+}
+
+// providerState tracks failing providers and publishes transitions.
+func (cfg *configurer) providerState(p Provider, err error) {
+	cfg.stateMu.Lock()
+	defer cfg.stateMu.Unlock()
+
+	name := p.Name()
+	switch _, down := cfg.down[name]; {
+	case err != nil && !down:
+		cfg.down[name] = struct{}{}
+		cfg.bus.publish(Event{Type: EventProviderDown, Provider: name, Err: err})
+	case err == nil && down:
+		delete(cfg.down, name)
+		cfg.bus.publish(Event{Type: EventProviderUp, Provider: name})
+	}
+	if err == nil {
+		cfg.refreshed[name] = time.Now()
+	}
+}
@@ -0,0 +1,154 @@
+package configwise
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// keyEvents returns EventKeyChanged events of the keys k<from> to k<to>.
+func keyEvents(from, to int) []Event {
+	var events []Event
+	for i := from; i <= to; i++ {
+		events = append(events, Event{Type: EventKeyChanged, Key: fmt.Sprintf("k%d", i)})
+	}
+	return events
+}
+
+// drain returns the buffered events as their keys, or lagged(n) for EventLagged.
+func drain(ch <-chan Event) []string {
+	var got []string
+	for {
+		select {
+		case event := <-ch:
+			if event.Type == EventLagged {
+				got = append(got, fmt.Sprintf("lagged(%d)", event.Dropped))
+			} else {
+				got = append(got, event.Key)
+			}
+		default:
+			return got
+		}
+	}
+}
+
+func TestOverflowPolicies(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy OverflowPolicy
+		// events buffered after publishing k1 to k5 and after publishing k6
+		want      []string
+		wantAfter []string
+	}{
+		{
+			name:      "drop newest",
+			policy:    OverflowDropNewest,
+			want:      []string{"k1", "k2", "k3"},
+			wantAfter: []string{"lagged(2)", "k6"},
+		},
+		{
+			name:      "drop oldest",
+			policy:    OverflowDropOldest,
+			want:      []string{"lagged(3)", "k4", "k5"},
+			wantAfter: []string{"k6"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bus eventBus
+			sub := bus.subscribe(3, tt.policy)
+
+			bus.publish(keyEvents(1, 5)...)
+			if got := drain(sub.ch); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events are %v, want %v", got, tt.want)
+			}
+			bus.publish(keyEvents(6, 6)...)
+			if got := drain(sub.ch); !reflect.DeepEqual(got, tt.wantAfter) {
+				t.Errorf("events after catching up are %v, want %v", got, tt.wantAfter)
+			}
+		})
+	}
+}
+
+func TestOverflowDropNewestWhileLagging(t *testing.T) {
+	var bus eventBus
+	sub := bus.subscribe(1, OverflowDropNewest)
+
+	bus.publish(keyEvents(1, 3)...)
+	<-sub.ch
+	// the lagged event takes the free slot, k4 is dropped as well
+	bus.publish(keyEvents(4, 4)...)
+	if got := drain(sub.ch); !reflect.DeepEqual(got, []string{"lagged(2)"}) {
+		t.Errorf("events are %v, want lagged(2)", got)
+	}
+	bus.publish(keyEvents(5, 5)...)
+	if got := drain(sub.ch); !reflect.DeepEqual(got, []string{"lagged(1)"}) {
+		t.Errorf("events are %v, want lagged(1)", got)
+	}
+}
+
+func TestOverflowBlock(t *testing.T) {
+	var bus eventBus
+	sub := bus.subscribe(1, OverflowBlock)
+
+	published := make(chan struct{})
+	go func() {
+		bus.publish(keyEvents(1, 3)...)
+		close(published)
+	}()
+
+	select {
+	case <-published:
+		t.Fatal("publish did not block on the full subscription")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	var got []string
+	for len(got) < 3 {
+		got = append(got, (<-sub.ch).Key)
+	}
+	<-published
+	if want := []string{"k1", "k2", "k3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("events are %v, want %v", got, want)
+	}
+
+	// ending the subscription releases a blocked publisher
+	bus.publish(keyEvents(4, 4)...)
+	go bus.publish(keyEvents(5, 5)...)
+	time.Sleep(10 * time.Millisecond)
+	done := make(chan struct{})
+	go func() {
+		bus.unsubscribe(sub)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("unsubscribe did not release the blocked publisher")
+	}
+}
+
+func TestSubscribeTypes(t *testing.T) {
+	c, err := NewConfigurer(
+		WithEventBuffer(2, OverflowDropOldest),
+		WithConfigMap(map[string]interface{}{"a": 1, "b": 1, "c": 1}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := c.Subscribe(ctx, EventKeyChanged)
+
+	for _, key := range []string{"a", "b", "c"} {
+		if err := c.Overwrite(map[string]interface{}{key: 2}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := drain(events), []string{"lagged(2)", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("events are %v, want %v", got, want)
+	}
+}
@@ -29,6 +29,14 @@ const envDefault = ":-"
 // ExpandVal replaces ${var} or $var in the string based on the mapping function.
 // For example, os.ExpandEnv(s) is equivalent to os.Expand(s, os.Getenv).
 func ExpandVal(s string, mapping func(string) string) string {
+	return expandVal(s, func(name string, _ bool) string {
+		return mapping(name)
+	})
+}
+
+// expandVal is ExpandVal passing to the mapping whether the reference has
+// a default, like ${var:-val}.
+func expandVal(s string, mapping func(name string, hasDefault bool) string) string {
 	var buf []byte
 	// ${} is all ASCII, so bytes are fine for this operation.
 	i := 0
@@ -47,23 +55,16 @@ func ExpandVal(s string, mapping func(string) string) string {
 				// name. Leave the dollar character untouched.
 				buf = append(buf, s[j])
 				// parse default syntax
-			} else if idx := strings.Index(s, envDefault); idx != -1 {
+			} else if key, defaultVal, ok := strings.Cut(name, envDefault); ok {
 				// ${key:-val}
-				substr := strings.Split(name, envDefault)
-				if len(substr) != 2 {
-					return ""
-				}
-
-				key := substr[0]
-				defaultVal := substr[1]
 
-				res := mapping(key)
+				res := mapping(key, true)
 				if res == "" {
 					res = defaultVal
 				}
 				buf = append(buf, res...)
 			} else {
-				buf = append(buf, mapping(name)...)
+				buf = append(buf, mapping(name, false)...)
 			}
 			j += w
 			i = j + 1
@@ -0,0 +1,125 @@
+package configwise
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// envReplacer maps keys to the names of environment variables.
+var envReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+// ExportOption configures ExportEnv and WriteEnv.
+type ExportOption func(*exportOptions)
+
+type exportOptions struct {
+	secrets bool
+}
+
+// WithExportedSecrets exports the plaintext of secrets instead of storing
+// them according to the secret policy, for child processes which need them,
+// e.g. the password of a database. Only hand the variables to trusted processes.
+func WithExportedSecrets() ExportOption {
+	return func(o *exportOptions) {
+		o.secrets = true
+	}
+}
+
+// ExportEnv flattens the effective configuration into environment variables
+// named like the variables read with WithPrefix, e.g. PREFIX_HTTP_PORT, to
+// hand it to child processes. Lists of values are joined by commas, lists of
+// sections are encoded as JSON. Secrets are stored according to the secret
+// policy, unless WithExportedSecrets is passed, and nil values are omitted.
+func (cfg *configurer) ExportEnv(prefix string, options ...ExportOption) map[string]string {
+	return cfg.exportEnv("", prefix, options)
+}
+
+// exportEnv exports the section stored under root, or all settings for an
+// empty root, with variables named relative to root.
+func (cfg *configurer) exportEnv(root, prefix string, options []ExportOption) map[string]string {
+	var o exportOptions
+	for _, opt := range options {
+		opt(&o)
+	}
+
+	section := cfg.redactSection(root)
+	if o.secrets {
+		_, value, _ := cfg.subtree(root)
+		section = cfg.resolveSection(value)
+	}
+
+	env := &envProvider{prefix: prefix, replacer: envReplacer}
+	vars := make(map[string]string)
+	var walk func(key string, value interface{})
+	walk = func(key string, value interface{}) {
+		switch v := value.(type) {
+		case nil:
+		case map[string]interface{}:
+			for k, val := range v {
+				walk(joinKey(key, k), val)
+			}
+		case []interface{}:
+			vars[env.variable(key)] = envList(v)
+		case []byte:
+			vars[env.variable(key)] = string(v)
+		default:
+			vars[env.variable(key)] = fmt.Sprint(v)
+		}
+	}
+	walk("", section)
+	return vars
+}
+
+// envList renders a list as the value of an environment variable.
+func envList(list []interface{}) string {
+	items := make([]string, len(list))
+	for i, item := range list {
+		switch item.(type) {
+		case map[string]interface{}, []interface{}:
+			data, err := json.Marshal(list)
+			if err != nil {
+				return ""
+			}
+			return string(data)
+		default:
+			items[i] = fmt.Sprint(item)
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+// WriteEnv writes the variables of ExportEnv in the .env format, sorted by
+// name, quoting values where needed.
+func (cfg *configurer) WriteEnv(w io.Writer, prefix string, options ...ExportOption) error {
+	return writeEnv(w, cfg.exportEnv("", prefix, options))
+}
+
+// writeEnv writes the variables in the .env format.
+func writeEnv(w io.Writer, vars map[string]string) error {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+	for _, name := range names {
+		value := vars[name]
+		switch {
+		case !strings.ContainsAny(value, " \t\r\n\"'#$\\=`"):
+		case !strings.ContainsAny(value, "'\r\n"):
+			// single quoted values are taken literally
+			value = "'" + value + "'"
+		default:
+			value = strconv.Quote(value)
+		}
+		if _, err := fmt.Fprintf(bw, "%s=%s\n", name, value); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
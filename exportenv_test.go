@@ -0,0 +1,128 @@
+package configwise
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const exportConfig = `
+http:
+  port: 8080
+  hosts: [a, b]
+  routes:
+    - {path: /, backend: web}
+db:
+  password: s3cret
+  dsn: file:/run/secrets/dsn
+feature-flags:
+  beta: true
+empty: null
+`
+
+func TestExportEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []Option
+		prefix  string
+		export  []ExportOption
+		want    map[string]string
+	}{
+		{
+			name:   "masked secrets",
+			prefix: "app",
+			want: map[string]string{
+				"APP_HTTP_PORT":          "8080",
+				"APP_HTTP_HOSTS":         "a,b",
+				"APP_HTTP_ROUTES":        `[{"backend":"web","path":"/"}]`,
+				"APP_DB_PASSWORD":        RedactedValue,
+				"APP_DB_DSN":             "file:/run/secrets/dsn",
+				"APP_FEATURE_FLAGS_BETA": "true",
+			},
+		},
+		{
+			name:    "secret references",
+			options: []Option{WithSecretPolicy(SecretReference)},
+			want: map[string]string{
+				"HTTP_PORT":          "8080",
+				"HTTP_HOSTS":         "a,b",
+				"HTTP_ROUTES":        `[{"backend":"web","path":"/"}]`,
+				"DB_PASSWORD":        RedactedValue,
+				"DB_DSN":             "file:/run/secrets/dsn",
+				"FEATURE_FLAGS_BETA": "true",
+			},
+		},
+		{
+			name:   "exported secrets",
+			export: []ExportOption{WithExportedSecrets()},
+			want: map[string]string{
+				"HTTP_PORT":          "8080",
+				"HTTP_HOSTS":         "a,b",
+				"HTTP_ROUTES":        `[{"backend":"web","path":"/"}]`,
+				"DB_PASSWORD":        "s3cret",
+				"DB_DSN":             "file:/run/secrets/dsn",
+				"FEATURE_FLAGS_BETA": "true",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]Option{
+				WithSecretKeys("db.password"),
+				WithType("yaml"),
+				WithReadInConfig([]byte(exportConfig)),
+			}, tt.options...)
+			c, err := NewConfigurer(options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.ExportEnv(tt.prefix, tt.export...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("variables are %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportEnvOfSub(t *testing.T) {
+	c, err := NewConfigurer(WithSecretKeys("db.password"), WithType("yaml"), WithReadInConfig([]byte(exportConfig)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := c.Sub("db")
+	want := map[string]string{"DB_PASSWORD": RedactedValue, "DB_DSN": "file:/run/secrets/dsn"}
+	if got := db.ExportEnv("db"); !reflect.DeepEqual(got, want) {
+		t.Errorf("variables are %v, want %v", got, want)
+	}
+	want["DB_PASSWORD"] = "s3cret"
+	if got := db.ExportEnv("db", WithExportedSecrets()); !reflect.DeepEqual(got, want) {
+		t.Errorf("variables with secrets are %v, want %v", got, want)
+	}
+}
+
+func TestWriteEnv(t *testing.T) {
+	c, err := NewConfigurer(WithConfigMap(map[string]interface{}{
+		"plain":  "value",
+		"spaced": "a b",
+		"quoted": "it's",
+		"multi":  "a\nb",
+		"hash":   "a#b",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	if err := c.WriteEnv(&b, "app"); err != nil {
+		t.Fatal(err)
+	}
+	const want = `APP_HASH='a#b'
+APP_MULTI="a\nb"
+APP_PLAIN=value
+APP_QUOTED="it's"
+APP_SPACED='a b'
+`
+	if got := b.String(); got != want {
+		t.Errorf("env file is\n%s\nwant\n%s", got, want)
+	}
+}
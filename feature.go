@@ -0,0 +1,56 @@
+package configwise
+
+import (
+	"strings"
+)
+
+// Feature declares the configuration an application feature depends on,
+// so that the application can shed only the affected functionality when
+// a provider is down or the configuration became invalid.
+type Feature struct {
+	Name string
+	// Keys which must be set.
+	Keys []string
+	// Providers, by name, which must be up.
+	Providers []string
+	// Check optionally validates the configuration of the feature.
+	Check func(c Configurer) error
+}
+
+// WithFeatures registers the features reported by Degraded.
+func WithFeatures(features ...Feature) Option {
+	return func(c *configurer) {
+		c.features = append(c.features, features...)
+	}
+}
+
+// Degraded returns the registered features whose providers are down or
+// whose configuration is missing or invalid.
+func (cfg *configurer) Degraded() []Feature {
+	var degraded []Feature
+	for _, feature := range cfg.features {
+		if !cfg.featureHealthy(feature) {
+			degraded = append(degraded, feature)
+		}
+	}
+	return degraded
+}
+
+func (cfg *configurer) featureHealthy(feature Feature) bool {
+	cfg.stateMu.Lock()
+	for _, name := range feature.Providers {
+		if _, down := cfg.down[name]; down {
+			cfg.stateMu.Unlock()
+			return false
+		}
+	}
+	cfg.stateMu.Unlock()
+
+	for _, key := range feature.Keys {
+		if !cfg.Has(strings.ToLower(key)) {
+			return false
+		}
+	}
+
+	return feature.Check == nil || feature.Check(cfg) == nil
+}
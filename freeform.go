@@ -0,0 +1,88 @@
+package configwise
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// ErrUnknownKey is reported in strict mode for every key the target struct
+// does not know about.
+var ErrUnknownKey = errors.New("unknown key")
+
+// WithFreeForm declares the sections under the keys as free-form passthrough
+// data, e.g. WithFreeForm("extra", "plugins.options"). The remaining config
+// stays enforced: free-form sections are excluded from the schema
+// validation, their keys are never reported as unknown in strict mode and
+// never listed by UnusedKeys. Their values are accessible as usual.
+func WithFreeForm(keys ...string) Option {
+	return func(c *configurer) {
+		for _, key := range keys {
+			c.freeForm = append(c.freeForm, strings.ToLower(key))
+		}
+	}
+}
+
+// isFreeForm reports whether the key is within a free-form section.
+func (cfg *configurer) isFreeForm(key string) bool {
+	for _, zone := range cfg.freeForm {
+		if covers(zone, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutFreeForm returns the settings without the free-form sections.
+// Maps along the removed paths are copied, the settings are not modified.
+func (cfg *configurer) withoutFreeForm(settings map[string]interface{}) map[string]interface{} {
+	var remove func(tree map[string]interface{}, path []string) map[string]interface{}
+	remove = func(tree map[string]interface{}, path []string) map[string]interface{} {
+		value, ok := tree[path[0]]
+		if !ok {
+			return tree
+		}
+
+		m := make(map[string]interface{}, len(tree))
+		for k, v := range tree {
+			m[k] = v
+		}
+		if len(path) == 1 {
+			delete(m, path[0])
+			return m
+		}
+		child, ok := value.(map[string]interface{})
+		if !ok {
+			return tree
+		}
+		m[path[0]] = remove(child, path[1:])
+		return m
+	}
+
+	for _, zone := range cfg.freeForm {
+		if zone == "" {
+			return map[string]interface{}{}
+		}
+		settings = remove(settings, strings.Split(zone, keyDelimiter))
+	}
+	return settings
+}
+
+// checkUnknown returns an error listing the keys below prefix which the
+// decoder did not use, except those within free-form sections.
+func (cfg *configurer) checkUnknown(prefix string, unused []string) error {
+	unused = append([]string(nil), unused...)
+	sort.Strings(unused)
+
+	var errs []error
+	for _, key := range unused {
+		key = strings.ToLower(key)
+		if prefix != "" {
+			key = prefix + keyDelimiter + key
+		}
+		if !cfg.isFreeForm(key) {
+			errs = append(errs, &KeyError{Key: key, Err: &messageError{msg: translate(cfg.lang, MsgUnknownKey), err: ErrUnknownKey}})
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,23 @@
+package configwise
+
+import "errors"
+
+// ErrFrozen is returned for changes of a frozen configuration.
+var ErrFrozen = errors.New("configuration is frozen")
+
+// Freeze makes the configuration immutable, e.g. once the service has
+// finished bootstrapping: afterwards Overwrite, Unset, ApplyPatch and reloads
+// fail with ErrFrozen, and Watch publishes the rejected reloads as
+// EventReloadFailed. Changes in progress complete before Freeze returns. A
+// configuration cannot be unfrozen, but clones of it are not frozen.
+func (cfg *configurer) Freeze() {
+	cfg.writeMu.Lock()
+	defer cfg.writeMu.Unlock()
+
+	cfg.frozen.Store(true)
+}
+
+// Frozen reports whether the configuration was frozen with Freeze.
+func (cfg *configurer) Frozen() bool {
+	return cfg.frozen.Load()
+}
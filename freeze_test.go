@@ -0,0 +1,78 @@
+package configwise
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	c, err := NewConfigurer(WithConfigMap(map[string]interface{}{"name": "app", "port": 80}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Frozen() {
+		t.Fatal("a new configuration is frozen")
+	}
+	if err := c.Overwrite(map[string]interface{}{"port": 8080}); err != nil {
+		t.Fatal(err)
+	}
+	c.Freeze()
+	if !c.Frozen() {
+		t.Fatal("the configuration is not frozen after Freeze")
+	}
+
+	tests := []struct {
+		name   string
+		change func() error
+		err    string
+	}{
+		{
+			name:   "overwrite",
+			change: func() error { return c.Overwrite(map[string]interface{}{"port": 9090}) },
+			err:    "configurer: overwrite ->",
+		},
+		{
+			name:   "unset",
+			change: func() error { return c.Unset("port") },
+			err:    "configurer: unset ->",
+		},
+		{
+			name:   "patch",
+			change: func() error { return c.ApplyPatch([]byte(`{"name": "other"}`), PatchMerge) },
+			err:    "configurer: apply patch ->",
+		},
+		{
+			name:   "reload",
+			change: func() error { return c.(*configurer).reload(context.Background()) },
+			err:    "configurer: reload ->",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.change()
+			if !errors.Is(err, ErrFrozen) || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error is %v, want %q", err, tt.err)
+			}
+			if got := c.GetInt("port"); got != 8080 {
+				t.Errorf("port is %d, want 8080", got)
+			}
+			if got := c.GetString("name"); got != "app" {
+				t.Errorf("name is %q, want app", got)
+			}
+		})
+	}
+
+	// clones are not frozen
+	clone := c.Clone()
+	if clone.Frozen() {
+		t.Error("the clone of a frozen configuration is frozen")
+	}
+	if err := clone.Overwrite(map[string]interface{}{"port": 9090}); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.GetInt("port"); got != 8080 {
+		t.Errorf("port is %d after an overwrite of the clone, want 8080", got)
+	}
+}
@@ -0,0 +1,58 @@
+package configwise
+
+import (
+	"cmp"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// WithFS loads the config document at path of the file system, e.g. an
+// embed.FS, so single binaries can ship their config. The type is taken
+// from the extension of the path, or from WithType. The document is read on
+// every load and overridden by the config file on disk.
+//
+//	//go:embed config
+//	var configFS embed.FS
+//
+//	configwise.WithFS(configFS, "config/app.yaml")
+func WithFS(fsys fs.FS, path string) Option {
+	return func(c *configurer) {
+		c.fsDocs = append(c.fsDocs, fsDoc{fsys: fsys, path: path})
+	}
+}
+
+type fsDoc struct {
+	fsys fs.FS
+	path string
+}
+
+// fsProvider provides a document of a file system.
+type fsProvider struct {
+	bytesProvider
+	fsys fs.FS
+}
+
+func newFSProvider(doc fsDoc, configType string, decode decodeFunc) *fsProvider {
+	if ext := path.Ext(doc.path); ext != "" {
+		configType = strings.ToLower(ext[1:])
+	}
+	return &fsProvider{
+		bytesProvider: bytesProvider{
+			name:       doc.path,
+			priority:   PriorityFS,
+			configType: cmp.Or(configType, defaultConfigType),
+			decode:     decode,
+		},
+		fsys: doc.fsys,
+	}
+}
+
+func (p *fsProvider) Read() (map[string]interface{}, error) {
+	data, err := fs.ReadFile(p.fsys, p.name)
+	if err != nil {
+		return nil, err
+	}
+	p.data = data
+	return p.bytesProvider.Read()
+}
@@ -0,0 +1,68 @@
+package configwise
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/app.yaml": {Data: []byte("name: embedded\nport: 80\ndebug: false")},
+		"config/app":      {Data: []byte(`name = "toml"`)},
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("debug: true"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		options []Option
+		want    map[string]interface{}
+		err     string
+	}{
+		{
+			name:    "type of the extension",
+			options: []Option{WithFS(fsys, "config/app.yaml"), WithOptionalFile()},
+			want:    map[string]interface{}{"name": "embedded", "port": 80, "debug": false},
+		},
+		{
+			name:    "type of WithType",
+			options: []Option{WithType("toml"), WithFS(fsys, "config/app"), WithOptionalFile()},
+			want:    map[string]interface{}{"name": "toml"},
+		},
+		{
+			name:    "overridden by the config file",
+			options: []Option{WithFS(fsys, "config/app.yaml"), WithPath(dir)},
+			want:    map[string]interface{}{"name": "embedded", "port": 80, "debug": true},
+		},
+		{
+			name:    "missing document",
+			options: []Option{WithFS(fsys, "config/missing.yaml"), WithOptionalFile()},
+			err:     "config/missing.yaml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewConfigurer(tt.options...)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error is %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for key, want := range tt.want {
+				if got := c.Get(key); got != want {
+					t.Errorf("%s is %v, want %v", key, got, want)
+				}
+			}
+		})
+	}
+}
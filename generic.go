@@ -0,0 +1,66 @@
+package configwise
+
+import "fmt"
+
+// Get decodes the value of the key into a T with the decode hooks of
+// UnmarshalKey, e.g. Get[time.Duration](cfg, "http.timeout"). Structs are
+// decoded, defaulted and validated like by UnmarshalKey. Keys which are not
+// set decode to the zero value of T, or the defaults of a struct.
+func Get[T any](c Configurer, key string) (T, error) {
+	var out T
+	if err := c.UnmarshalKey(key, &out); err != nil {
+		var zero T
+		return zero, err
+	}
+	return out, nil
+}
+
+// UnmarshalKey decodes the section stored under the key into a new T, e.g.
+// UnmarshalKey[HTTPConfig](cfg, "http").
+func UnmarshalKey[T any](c Configurer, key string) (*T, error) {
+	out := new(T)
+	if err := c.UnmarshalKey(key, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MustGet is like Get but panics if the value cannot be decoded. It is meant
+// for wiring in main, where a broken config is fatal anyway.
+func MustGet[T any](c Configurer, key string) T {
+	out, err := Get[T](c, key)
+	if err != nil {
+		panic(mustError(key, err))
+	}
+	return out
+}
+
+// MustUnmarshalKey is like UnmarshalKey but panics if the section cannot be
+// decoded. It is meant for wiring in main, where a broken config is fatal anyway.
+func MustUnmarshalKey[T any](c Configurer, key string) *T {
+	out, err := UnmarshalKey[T](c, key)
+	if err != nil {
+		panic(mustError(key, err))
+	}
+	return out
+}
+
+// mustError annotates the error of a Must function with the key. The cause
+// is wrapped, so it can still be matched after recovering.
+func mustError(key string, err error) error {
+	return fmt.Errorf("configwise: key %q: %w", key, err)
+}
+
+// GetOrDefault returns the value of the key decoded into a T, or the fallback
+// if the key is not set or its value cannot be decoded, e.g.
+// GetOrDefault(cfg, "http.timeout", 30*time.Second).
+func GetOrDefault[T any](c Configurer, key string, fallback T) T {
+	if !c.Has(key) {
+		return fallback
+	}
+	out, err := Get[T](c, key)
+	if err != nil {
+		return fallback
+	}
+	return out
+}
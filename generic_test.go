@@ -0,0 +1,129 @@
+package configwise
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const genericConfig = `
+http:
+  timeout: 30s
+  port: 8080
+  hosts: [a, b]
+name: app
+`
+
+type genericHTTP struct {
+	Timeout time.Duration
+	Port    int
+	Hosts   []string
+	Scheme  string `default:"https"`
+}
+
+func TestGet(t *testing.T) {
+	c, err := NewConfigurer(WithType("yaml"), WithReadInConfig([]byte(genericConfig)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := Get[time.Duration](c, "http.timeout"); err != nil || got != 30*time.Second {
+		t.Errorf("timeout is %v, %v, want 30s", got, err)
+	}
+	if got, err := Get[[]string](c, "http.hosts"); err != nil || !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("hosts are %v, %v, want [a b]", got, err)
+	}
+	if got, err := Get[int](c, "missing"); err != nil || got != 0 {
+		t.Errorf("missing key is %v, %v, want 0", got, err)
+	}
+
+	want := genericHTTP{Timeout: 30 * time.Second, Port: 8080, Hosts: []string{"a", "b"}, Scheme: "https"}
+	if got, err := Get[genericHTTP](c, "http"); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("section is %+v, %v, want %+v", got, err, want)
+	}
+
+	if got, err := Get[int](c, "name"); err == nil || got != 0 {
+		t.Errorf("name as int is %v, %v, want an error", got, err)
+	}
+}
+
+func TestUnmarshalKey(t *testing.T) {
+	c, err := NewConfigurer(WithType("yaml"), WithReadInConfig([]byte(genericConfig)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &genericHTTP{Timeout: 30 * time.Second, Port: 8080, Hosts: []string{"a", "b"}, Scheme: "https"}
+	if got, err := UnmarshalKey[genericHTTP](c, "http"); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("section is %+v, %v, want %+v", got, err, want)
+	}
+	if got, err := UnmarshalKey[genericHTTP](c, "missing"); err != nil || !reflect.DeepEqual(got, &genericHTTP{Scheme: "https"}) {
+		t.Errorf("missing section is %+v, %v, want the defaults", got, err)
+	}
+	if got, err := UnmarshalKey[genericHTTP](c, "name"); err == nil || got != nil {
+		t.Errorf("name as section is %+v, %v, want an error", got, err)
+	}
+}
+
+func TestMustGet(t *testing.T) {
+	c, err := NewConfigurer(WithType("yaml"), WithReadInConfig([]byte(genericConfig)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := MustGet[int](c, "http.port"); got != 8080 {
+		t.Errorf("port is %d, want 8080", got)
+	}
+	if got := MustUnmarshalKey[genericHTTP](c, "http"); got.Port != 8080 {
+		t.Errorf("section is %+v, want the port 8080", got)
+	}
+
+	tests := []struct {
+		name string
+		must func()
+	}{
+		{name: "get", must: func() { MustGet[int](c, "name") }},
+		{name: "unmarshal key", must: func() { MustUnmarshalKey[genericHTTP](c, "name") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				err, ok := recover().(error)
+				if !ok || !strings.HasPrefix(err.Error(), `configwise: key "name": `) {
+					t.Fatalf("panic is %v, want the error of the key", err)
+				}
+				var target *Error
+				if !errors.As(err, &target) {
+					t.Errorf("panic %v does not wrap the error of the configurer", err)
+				}
+			}()
+			tt.must()
+		})
+	}
+}
+
+func TestGetOrDefault(t *testing.T) {
+	c, err := NewConfigurer(WithType("yaml"), WithReadInConfig([]byte(genericConfig)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		key  string
+		want time.Duration
+	}{
+		{name: "set", key: "http.timeout", want: 30 * time.Second},
+		{name: "missing", key: "http.idle-timeout", want: time.Minute},
+		{name: "invalid", key: "name", want: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetOrDefault(c, tt.key, time.Minute); got != tt.want {
+				t.Errorf("value is %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package configwise
+
+import (
+	"reflect"
+	"time"
+)
+
+// GetString returns the value of the key as a string, or "" if it is not set
+// or cannot be converted.
+func (cfg *configurer) GetString(name string) string {
+	key, val := cfg.lookup(name)
+	cfg.auditAccess("get", key, val)
+	var out string
+	cfg.convert(key, val, &out)
+	return out
+}
+
+// GetInt returns the value of the key as an int, or 0 if it is not set or
+// cannot be converted.
+func (cfg *configurer) GetInt(name string) int {
+	key, val := cfg.lookup(name)
+	cfg.auditAccess("get", key, val)
+	var out int
+	cfg.convert(key, val, &out)
+	return out
+}
+
+// GetBool returns the value of the key as a bool, or false if it is not set
+// or cannot be converted.
+func (cfg *configurer) GetBool(name string) bool {
+	key, val := cfg.lookup(name)
+	cfg.auditAccess("get", key, val)
+	var out bool
+	cfg.convert(key, val, &out)
+	return out
+}
+
+// GetDuration returns the value of the key as a duration, or 0 if it is not
+// set or cannot be converted.
+func (cfg *configurer) GetDuration(name string) time.Duration {
+	key, val := cfg.lookup(name)
+	cfg.auditAccess("get", key, val)
+	var out time.Duration
+	cfg.convert(key, val, &out)
+	return out
+}
+
+// GetStringSlice returns the value of the key as a list of strings, or nil if
+// it is not set or cannot be converted. Strings are split at commas.
+func (cfg *configurer) GetStringSlice(name string) []string {
+	key, val := cfg.lookup(name)
+	cfg.auditAccess("get", key, val)
+	var out []string
+	if !cfg.convert(key, val, &out) {
+		return nil
+	}
+	return out
+}
+
+// GetStringMap returns a copy of the section stored under the key, or nil if
+// it is not set or not a section.
+func (cfg *configurer) GetStringMap(name string) map[string]interface{} {
+	key, val := cfg.lookup(name)
+	cfg.auditAccess("get", key, val)
+	var out map[string]interface{}
+	if !cfg.convert(key, val, &out) {
+		return nil
+	}
+	return deepCopy(out).(map[string]interface{})
+}
+
+// lookup returns the canonical key and the resolved value of the key, and
+// records the key as consumed. Values which fail to resolve are nil, never
+// the unresolved strings like ciphertexts, and reported as warnings.
+func (cfg *configurer) lookup(name string) (string, interface{}) {
+	key := cfg.canonicalKey(name)
+	val, _, err := cfg.find(key)
+	cfg.markUsed(key, nil)
+	if err == nil {
+		val, err = cfg.resolveLazy(val)
+	}
+	if err != nil {
+		cfg.addWarnings(Warning{Kind: WarningUnresolvedValue, Key: key, Name: err.Error()})
+		return key, nil
+	}
+	return key, val
+}
+
+// convert decodes the value into out with the decode hooks and reports
+// whether it succeeded. On failure out is reset to its zero value.
+func (cfg *configurer) convert(key string, val, out interface{}) bool {
+	if val == nil {
+		return false
+	}
+	if err := cfg.decode(key, val, out, nil); err != nil {
+		resetValue(out)
+		return false
+	}
+	return true
+}
+
+// resetValue sets the value out points to to its zero value.
+func resetValue(out interface{}) {
+	reflect.ValueOf(out).Elem().SetZero()
+}
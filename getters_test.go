@@ -0,0 +1,58 @@
+package configwise
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const gettersConfig = `
+name: app
+port: "8080"
+debug: "true"
+timeout: 1m30s
+hosts: a,b
+scopes: [read, write]
+server: {host: localhost}
+`
+
+func TestGetters(t *testing.T) {
+	c, err := NewConfigurer(WithType("yaml"), WithReadInConfig([]byte(gettersConfig)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		get  func() interface{}
+		want interface{}
+	}{
+		{name: "string", get: func() interface{} { return c.GetString("name") }, want: "app"},
+		{name: "string of a number", get: func() interface{} { return c.GetString("port") }, want: "8080"},
+		{name: "missing string", get: func() interface{} { return c.GetString("missing") }, want: ""},
+		{name: "int of a string", get: func() interface{} { return c.GetInt("Port") }, want: 8080},
+		{name: "int of a section", get: func() interface{} { return c.GetInt("server") }, want: 0},
+		{name: "bool of a string", get: func() interface{} { return c.GetBool("debug") }, want: true},
+		{name: "invalid bool", get: func() interface{} { return c.GetBool("name") }, want: false},
+		{name: "duration", get: func() interface{} { return c.GetDuration("timeout") }, want: 90 * time.Second},
+		{name: "invalid duration", get: func() interface{} { return c.GetDuration("name") }, want: time.Duration(0)},
+		{name: "split string slice", get: func() interface{} { return c.GetStringSlice("hosts") }, want: []string{"a", "b"}},
+		{name: "string slice", get: func() interface{} { return c.GetStringSlice("scopes") }, want: []string{"read", "write"}},
+		{name: "missing string slice", get: func() interface{} { return c.GetStringSlice("missing") }, want: []string(nil)},
+		{name: "string map", get: func() interface{} { return c.GetStringMap("server") }, want: map[string]interface{}{"host": "localhost"}},
+		{name: "string map of a value", get: func() interface{} { return c.GetStringMap("name") }, want: map[string]interface{}(nil)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.get(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("value is %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	// sections are copies
+	c.GetStringMap("server")["host"] = "example.com"
+	if got := c.GetString("server.host"); got != "localhost" {
+		t.Errorf("server.host is %q after a change of the returned section", got)
+	}
+}
@@ -0,0 +1,171 @@
+// Package hcl decodes HCL2 config documents for configwise. It is a
+// separate package so that only applications reading HCL link the HCL and
+// cty libraries:
+//
+//	c, err := configwise.NewConfigurer(hcl.Option())
+package hcl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/gowool/configwise"
+)
+
+// functions are the functions available to expressions of HCL documents.
+var functions = map[string]function.Function{
+	"upper":      stdlib.UpperFunc,
+	"lower":      stdlib.LowerFunc,
+	"trimspace":  stdlib.TrimSpaceFunc,
+	"format":     stdlib.FormatFunc,
+	"join":       stdlib.JoinFunc,
+	"split":      stdlib.SplitFunc,
+	"replace":    stdlib.ReplaceFunc,
+	"concat":     stdlib.ConcatFunc,
+	"merge":      stdlib.MergeFunc,
+	"length":     stdlib.LengthFunc,
+	"min":        stdlib.MinFunc,
+	"max":        stdlib.MaxFunc,
+	"tostring":   stdlib.MakeToFunc(cty.String),
+	"tonumber":   stdlib.MakeToFunc(cty.Number),
+	"tobool":     stdlib.MakeToFunc(cty.Bool),
+	"jsonencode": stdlib.JSONEncodeFunc,
+	"jsondecode": stdlib.JSONDecodeFunc,
+}
+
+// Option registers Decode for config documents of type hcl, detected by the
+// .hcl extension.
+func Option() configwise.Option {
+	return configwise.WithDecoder("hcl", Decode)
+}
+
+// Decode parses an HCL2 document into a configuration tree. Attributes
+// become values and blocks become sections, nested by their labels, e.g.
+//
+//	server "public" {
+//	  port = 8000 + 80
+//	}
+//
+// sets server.public.port to 8080. Repeated blocks of a type without labels
+// become a list of sections. Expressions may call common functions like
+// upper, format or join and refer to environment variables as env.NAME.
+func Decode(data []byte) (map[string]interface{}, error) {
+	file, diags := hclsyntax.ParseConfig(data, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diagnosticsError(diags)
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{"env": environment()},
+		Functions: functions,
+	}
+	return decodeBody(file.Body.(*hclsyntax.Body), ctx)
+}
+
+func decodeBody(body *hclsyntax.Body, ctx *hcl.EvalContext) (map[string]interface{}, error) {
+	tree := make(map[string]interface{}, len(body.Attributes)+len(body.Blocks))
+	for name, attr := range body.Attributes {
+		value, diags := attr.Expr.Value(ctx)
+		if diags.HasErrors() {
+			return nil, diagnosticsError(diags)
+		}
+		v, err := jsonValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		tree[name] = v
+	}
+
+	for _, block := range body.Blocks {
+		section, err := decodeBody(block.Body, ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(block.Labels) == 0 {
+			switch existing := tree[block.Type].(type) {
+			case nil:
+				tree[block.Type] = section
+			case map[string]interface{}:
+				tree[block.Type] = []interface{}{existing, section}
+			case []interface{}:
+				tree[block.Type] = append(existing, section)
+			}
+			continue
+		}
+
+		parent := tree
+		path := append([]string{block.Type}, block.Labels...)
+		for _, name := range path[:len(path)-1] {
+			next, ok := parent[name].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				parent[name] = next
+			}
+			parent = next
+		}
+		last := path[len(path)-1]
+		if _, ok := parent[last]; ok {
+			start := block.DefRange().Start
+			return nil, fmt.Errorf("%d:%d: duplicate block %s", start.Line, start.Column, strings.Join(path, " "))
+		}
+		parent[last] = section
+	}
+	return tree, nil
+}
+
+// diagnosticsError joins the errors of the diagnostics, located by line and column.
+func diagnosticsError(diags hcl.Diagnostics) error {
+	var errs []error
+	for _, d := range diags {
+		if d.Severity != hcl.DiagError {
+			continue
+		}
+		msg := d.Summary
+		if d.Detail != "" {
+			msg += "; " + d.Detail
+		}
+		if d.Subject != nil {
+			msg = fmt.Sprintf("%d:%d: %s", d.Subject.Start.Line, d.Subject.Start.Column, msg)
+		}
+		errs = append(errs, errors.New(msg))
+	}
+	return errors.Join(errs...)
+}
+
+// jsonValue converts an evaluated value into the values of decoded JSON.
+func jsonValue(value cty.Value) (interface{}, error) {
+	if value.IsNull() {
+		return nil, nil
+	}
+	data, err := ctyjson.Marshal(value, value.Type())
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// environment returns the environment variables as an object.
+func environment() cty.Value {
+	env := make(map[string]cty.Value)
+	for _, kv := range os.Environ() {
+		if name, value, ok := strings.Cut(kv, "="); ok && name != "" {
+			env[name] = cty.StringVal(value)
+		}
+	}
+	return cty.ObjectVal(env)
+}
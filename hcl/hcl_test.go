@@ -0,0 +1,252 @@
+package hcl
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gowool/configwise"
+)
+
+type decodeTest struct {
+	name string
+	doc  string
+	want map[string]interface{}
+	err  string
+}
+
+func runDecodeTests(t *testing.T, tests []decodeTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode([]byte(tt.doc))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error is %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tree is %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttributes(t *testing.T) {
+	runDecodeTests(t, []decodeTest{
+		{
+			name: "values",
+			doc: `
+name    = "app"
+debug   = true
+ratio   = 0.5
+tags    = ["a", "b"]
+limits  = { cpu = 2, "mem-mb" = 512 }
+nothing = null
+`,
+			want: map[string]interface{}{
+				"name":    "app",
+				"debug":   true,
+				"ratio":   0.5,
+				"tags":    []interface{}{"a", "b"},
+				"limits":  map[string]interface{}{"cpu": float64(2), "mem-mb": float64(512)},
+				"nothing": nil,
+			},
+		},
+		{
+			name: "expressions",
+			doc: `
+port    = 8000 + 80
+tls     = 8080 > 1024 ? false : true
+url     = "http://localhost:${8000 + 80}"
+scopes  = [for s in ["read", "write"] : upper(s)]
+`,
+			want: map[string]interface{}{
+				"port":   float64(8080),
+				"tls":    false,
+				"url":    "http://localhost:8080",
+				"scopes": []interface{}{"READ", "WRITE"},
+			},
+		},
+		{
+			name: "heredoc",
+			doc:  "motd = <<-EOT\n  hello\n  world\nEOT\n",
+			want: map[string]interface{}{"motd": "hello\nworld\n"},
+		},
+		{
+			name: "comments",
+			doc:  "# hash\nport = 80 // slash\n/* block\n   comment */\nhost = \"localhost\"\n",
+			want: map[string]interface{}{"port": float64(80), "host": "localhost"},
+		},
+		{
+			name: "duplicate attribute",
+			doc:  "port = 80\nport = 81\n",
+			err:  "2:1: Attribute redefined",
+		},
+		{
+			name: "syntax error",
+			doc:  "port = ",
+			err:  "1:8:",
+		},
+		{
+			name: "unknown variable",
+			doc:  "port = base + 1",
+			err:  "1:8: Unknown variable",
+		},
+	})
+}
+
+func TestBlocks(t *testing.T) {
+	runDecodeTests(t, []decodeTest{
+		{
+			name: "block without labels",
+			doc:  "tls {\n  enabled = true\n}\n",
+			want: map[string]interface{}{"tls": map[string]interface{}{"enabled": true}},
+		},
+		{
+			name: "labeled blocks",
+			doc: `
+server "public" {
+  port = 8080
+}
+server "admin" {
+  port = 9090
+  tls {
+    enabled = true
+  }
+}
+`,
+			want: map[string]interface{}{
+				"server": map[string]interface{}{
+					"public": map[string]interface{}{"port": float64(8080)},
+					"admin": map[string]interface{}{
+						"port": float64(9090),
+						"tls":  map[string]interface{}{"enabled": true},
+					},
+				},
+			},
+		},
+		{
+			name: "blocks with several labels",
+			doc: `
+backend "db" "primary" {
+  host = "db1"
+}
+backend "db" "replica" {
+  host = "db2"
+}
+`,
+			want: map[string]interface{}{
+				"backend": map[string]interface{}{
+					"db": map[string]interface{}{
+						"primary": map[string]interface{}{"host": "db1"},
+						"replica": map[string]interface{}{"host": "db2"},
+					},
+				},
+			},
+		},
+		{
+			name: "repeated blocks",
+			doc: `
+route {
+  path = "/"
+}
+route {
+  path = "/api"
+}
+route {
+  path = "/admin"
+}
+`,
+			want: map[string]interface{}{
+				"route": []interface{}{
+					map[string]interface{}{"path": "/"},
+					map[string]interface{}{"path": "/api"},
+					map[string]interface{}{"path": "/admin"},
+				},
+			},
+		},
+		{
+			name: "empty block",
+			doc:  "cache {}\n",
+			want: map[string]interface{}{"cache": map[string]interface{}{}},
+		},
+		{
+			name: "duplicate block",
+			doc:  "server \"a\" {}\nserver \"a\" {}\n",
+			err:  "2:1: duplicate block server a",
+		},
+		{
+			name: "error in a nested block",
+			doc:  "server \"a\" {\n  tls {\n    enabled = maybe\n  }\n}\n",
+			err:  "3:15: Unknown variable",
+		},
+	})
+}
+
+func TestFunctions(t *testing.T) {
+	t.Setenv("CONFIGWISE_TEST_REGION", "eu")
+
+	runDecodeTests(t, []decodeTest{
+		{
+			name: "functions",
+			doc: `
+hosts  = join(",", ["a", "b"])
+parts  = split("/", "a/b")
+url    = format("https://%s.example.com", "eu")
+port   = tonumber("8080")
+limits = merge({ cpu = 1 }, { mem = 2 })
+count  = length(["a", "b", "c"])
+`,
+			want: map[string]interface{}{
+				"hosts":  "a,b",
+				"parts":  []interface{}{"a", "b"},
+				"url":    "https://eu.example.com",
+				"port":   float64(8080),
+				"limits": map[string]interface{}{"cpu": float64(1), "mem": float64(2)},
+				"count":  float64(3),
+			},
+		},
+		{
+			name: "environment",
+			doc:  `region = upper(env.CONFIGWISE_TEST_REGION)`,
+			want: map[string]interface{}{"region": "EU"},
+		},
+		{
+			name: "undefined environment variable",
+			doc:  `region = env.CONFIGWISE_TEST_MISSING`,
+			err:  "Unsupported attribute",
+		},
+		{
+			name: "unknown function",
+			doc:  `name = shout("a")`,
+			err:  "Call to unknown function",
+		},
+	})
+}
+
+func TestKeyCase(t *testing.T) {
+	doc := []byte("Server \"Public\" {\n  HTTPPort = 8000 + 80\n}\n")
+
+	tree, err := Decode(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tree["Server"]; !ok {
+		t.Fatalf("tree is %#v, want the keys as written", tree)
+	}
+
+	c, err := configwise.NewConfigurer(Option(), configwise.WithType("hcl"), configwise.WithReadInConfig(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"server.public.httpport", "Server.Public.HTTPPort", "SERVER.PUBLIC.HTTPPORT"} {
+		if got := c.GetInt(key); got != 8080 {
+			t.Errorf("%s is %d, want 8080", key, got)
+		}
+	}
+}
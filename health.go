@@ -0,0 +1,190 @@
+package configwise
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Health describes the freshness of the configuration.
+type Health struct {
+	// Stale lists the critical keys whose source has not refreshed within
+	// their max staleness.
+	Stale []StaleKey
+	// Down lists the names of the providers whose last read failed.
+	Down []string
+}
+
+// Healthy reports whether no critical key is stale and no provider is down.
+func (h Health) Healthy() bool {
+	return len(h.Stale) == 0 && len(h.Down) == 0
+}
+
+// StaleKey is a critical key served from a stale source.
+type StaleKey struct {
+	Key string
+	// Provider is the name of the provider of the value, empty if the key is not set.
+	Provider string
+	// Refreshed is the time of the last successful read of the provider.
+	Refreshed    time.Time
+	MaxStaleness time.Duration
+}
+
+type criticalKey struct {
+	key          string
+	maxStaleness time.Duration
+}
+
+// WithCriticalKey marks the key as critical: the provider of its value must
+// have been read successfully within maxStaleness, otherwise the key is
+// reported by Health. This protects against silently serving expired
+// credentials or outdated allowlists when a remote source keeps failing.
+// Only remote sources re-fetched with WithRefreshInterval become stale;
+// files, which are re-read on change, and lookup providers such as the
+// environment never do. NewConfigurer fails for a maxStaleness which is
+// not positive.
+func WithCriticalKey(key string, maxStaleness time.Duration) Option {
+	return func(c *configurer) {
+		if maxStaleness <= 0 {
+			c.optionErrs = append(c.optionErrs, fmt.Errorf("critical key %q: max staleness must be positive", key))
+			return
+		}
+		c.critical = append(c.critical, criticalKey{key: strings.ToLower(key), maxStaleness: maxStaleness})
+	}
+}
+
+// WithOnStale registers a callback invoked when a critical key becomes stale.
+// Staleness is checked while the configurer is watched.
+func WithOnStale(fn func(StaleKey)) Option {
+	return func(c *configurer) {
+		c.onStale = append(c.onStale, fn)
+	}
+}
+
+func (cfg *configurer) Health() Health {
+	var health Health
+
+	cfg.stateMu.Lock()
+	for name := range cfg.down {
+		health.Down = append(health.Down, name)
+	}
+	cfg.stateMu.Unlock()
+	sort.Strings(health.Down)
+
+	health.Stale = cfg.staleKeys(time.Now())
+	return health
+}
+
+// staleKeys returns the critical keys which are stale at the given time.
+func (cfg *configurer) staleKeys(now time.Time) []StaleKey {
+	if len(cfg.critical) == 0 {
+		return nil
+	}
+
+	cfg.mu.RLock()
+	origins := cfg.origins
+	cfg.mu.RUnlock()
+
+	providers := make([][]Provider, len(cfg.critical))
+	var stale []StaleKey
+	for i, critical := range cfg.critical {
+		providers[i] = providersOf(origins, critical.key)
+		// keys only set by lookup providers have no origin
+		if _, ok, _ := cfg.find(critical.key); !ok {
+			stale = append(stale, StaleKey{Key: critical.key, MaxStaleness: critical.maxStaleness})
+		}
+	}
+
+	cfg.stateMu.Lock()
+	defer cfg.stateMu.Unlock()
+
+	for i, critical := range cfg.critical {
+		for _, p := range providers[i] {
+			if !cfg.isRefreshed(p) {
+				continue
+			}
+			refreshed := cfg.refreshed[p.Name()]
+			if now.Sub(refreshed) > critical.maxStaleness {
+				stale = append(stale, StaleKey{
+					Key:          critical.key,
+					Provider:     p.Name(),
+					Refreshed:    refreshed,
+					MaxStaleness: critical.maxStaleness,
+				})
+				break
+			}
+		}
+	}
+	return stale
+}
+
+// isRefreshed reports whether the provider is re-read periodically, which
+// are the remote sources with a refresh interval.
+func (cfg *configurer) isRefreshed(p Provider) bool {
+	if _, ok := p.(Lookuper); ok {
+		return false
+	}
+	return cfg.refreshInterval > 0 && sourceOf(p) == SourceRemote
+}
+
+// providersOf returns the providers of the key, or of all keys of a section,
+// sorted by name.
+func providersOf(origins map[string]Provider, key string) []Provider {
+	if p, ok := origins[key]; ok {
+		return []Provider{p}
+	}
+
+	seen := make(map[string]Provider)
+	for k, p := range origins {
+		if strings.HasPrefix(k, key+keyDelimiter) {
+			seen[p.Name()] = p
+		}
+	}
+	providers := make([]Provider, 0, len(seen))
+	for _, p := range seen {
+		providers = append(providers, p)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i].Name() < providers[j].Name() })
+	return providers
+}
+
+// staleCheckInterval returns half of the shortest max staleness, at least
+// a millisecond, or zero if there are no critical keys.
+func (cfg *configurer) staleCheckInterval() time.Duration {
+	var interval time.Duration
+	for _, critical := range cfg.critical {
+		if interval == 0 || critical.maxStaleness < interval {
+			interval = critical.maxStaleness
+		}
+	}
+	if interval == 0 {
+		return 0
+	}
+	return max(interval/2, time.Millisecond)
+}
+
+// checkStale publishes EventKeyStale and calls the stale callbacks for every
+// critical key which became stale since the last check.
+func (cfg *configurer) checkStale() {
+	stale := cfg.staleKeys(time.Now())
+
+	current := make(map[string]struct{}, len(stale))
+	var became []StaleKey
+	cfg.stateMu.Lock()
+	for _, key := range stale {
+		current[key.Key] = struct{}{}
+		if _, ok := cfg.stale[key.Key]; !ok {
+			became = append(became, key)
+		}
+	}
+	cfg.stale = current
+	cfg.stateMu.Unlock()
+
+	for _, key := range became {
+		cfg.bus.publish(Event{Type: EventKeyStale, Key: key.Key, Provider: key.Provider})
+		for _, fn := range cfg.onStale {
+			fn(key)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package configwise
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHealthStaleKeys(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"token": "abc", "limits": {"rps": 10}}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("CONFIGWISE_TEST_REGION", "eu")
+
+	tests := []struct {
+		name    string
+		options []Option
+		want    []string
+	}{
+		{
+			name:    "file",
+			options: []Option{WithCriticalKey("name", time.Second)},
+		},
+		{
+			name:    "environment",
+			options: []Option{WithCriticalKey("region", time.Second)},
+		},
+		{
+			name:    "remote without refresh",
+			options: []Option{WithCriticalKey("token", time.Second)},
+		},
+		{
+			name:    "refreshed remote",
+			options: []Option{WithCriticalKey("token", time.Second), WithRefreshInterval(time.Minute)},
+			want:    []string{"token"},
+		},
+		{
+			name:    "section of a refreshed remote",
+			options: []Option{WithCriticalKey("limits", time.Second), WithRefreshInterval(time.Minute)},
+			want:    []string{"limits"},
+		},
+		{
+			name:    "missing key",
+			options: []Option{WithCriticalKey("missing", time.Second)},
+			want:    []string{"missing"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]Option{
+				WithPrefix("configwise_test"),
+				WithType("yaml"),
+				WithReadInConfig([]byte("name: app")),
+				WithProvider(NewURLProvider(server.URL, WithRemoteType("json"))),
+			}, tt.options...)
+			c, err := NewConfigurer(options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg := c.(*configurer)
+
+			var got []string
+			for _, key := range cfg.staleKeys(time.Now().Add(time.Hour)) {
+				got = append(got, key.Key)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("stale keys are %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCriticalKeyStaleness(t *testing.T) {
+	for _, staleness := range []time.Duration{0, -time.Second} {
+		_, err := NewConfigurer(WithCriticalKey("token", staleness))
+		if err == nil || !strings.Contains(err.Error(), `critical key "token": max staleness must be positive`) {
+			t.Errorf("error of max staleness %v is %v", staleness, err)
+		}
+	}
+
+	c, err := NewConfigurer(WithCriticalKey("a", time.Nanosecond), WithCriticalKey("b", time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.(*configurer).staleCheckInterval(); got != time.Millisecond {
+		t.Errorf("stale check interval is %v, want 1ms", got)
+	}
+}
@@ -0,0 +1,165 @@
+package configwise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// defaultHistorySize is the number of revisions kept by default.
+const defaultHistorySize = 10
+
+// ErrUnknownGeneration is returned by Rollback for generations which are not
+// in the history.
+var ErrUnknownGeneration = errors.New("unknown generation")
+
+// WithHistory sets the number of revisions of the effective configuration
+// kept for Rollback, 10 by default. A size of 0 disables the history.
+func WithHistory(size int) Option {
+	return func(c *configurer) {
+		c.historySize = max(size, 0)
+	}
+}
+
+// Revision describes a state of the effective configuration.
+type Revision struct {
+	// Generation is incremented by every change of the configuration,
+	// starting with 1 for the initial load.
+	Generation uint64
+	Time       time.Time
+	// Trigger is the change which produced the revision, "load" or one of
+	// the sources of WebhookPayload.
+	Trigger string
+}
+
+// revision is a revision with the state it restores.
+type revision struct {
+	Revision
+	settings  map[string]interface{}
+	base      map[string]interface{}
+	secrets   map[string]string
+	origins   map[string]Provider
+	positions map[Provider]map[string]Position
+	overrides []override
+}
+
+// record starts a new generation with the current state and adds it to the
+// history. It must be called with mu and writeMu held.
+func (cfg *configurer) record(trigger string) {
+	cfg.generation++
+	if cfg.historySize == 0 {
+		return
+	}
+
+	cfg.history = append(cfg.history, revision{
+		Revision:  Revision{Generation: cfg.generation, Time: time.Now(), Trigger: trigger},
+		settings:  cfg.settings,
+		base:      cfg.base,
+		secrets:   cfg.secrets,
+		origins:   cfg.origins,
+		positions: cfg.positions,
+		overrides: cfg.overrides,
+	})
+	if n := len(cfg.history) - cfg.historySize; n > 0 {
+		// the slice is shared with clones, so it is copied rather than resliced
+		cfg.history = append([]revision(nil), cfg.history[n:]...)
+	}
+}
+
+// Generation returns the generation of the current configuration.
+func (cfg *configurer) Generation() uint64 {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+
+	return cfg.generation
+}
+
+// History returns the revisions kept for Rollback, oldest first. The last
+// revision is the current configuration.
+func (cfg *configurer) History() []Revision {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+
+	revisions := make([]Revision, len(cfg.history))
+	for i, rev := range cfg.history {
+		revisions[i] = rev.Revision
+	}
+	return revisions
+}
+
+// Rollback restores the configuration of a generation in the history, e.g.
+// to revert a bad runtime change or reload. The rollback is a change of its
+// own, starting a new generation. Overrides are restored as well, and
+// journaled so the rollback survives restarts; values of providers are
+// restored until the next reload.
+func (cfg *configurer) Rollback(generation uint64) error {
+	return cfg.RollbackContext(context.Background(), generation)
+}
+
+// RollbackContext is like Rollback, recording actor and request ID of the
+// context in the audit log.
+func (cfg *configurer) RollbackContext(ctx context.Context, generation uint64) error {
+	cfg.writeMu.Lock()
+	if cfg.frozen.Load() {
+		cfg.writeMu.Unlock()
+		return &Error{Op: OpRollback, Err: ErrFrozen}
+	}
+
+	cfg.mu.RLock()
+	old, oldSecrets := cfg.settings, cfg.secrets
+	var (
+		rev   revision
+		found bool
+	)
+	for _, r := range cfg.history {
+		if r.Generation == generation {
+			rev, found = r, true
+		}
+	}
+	cfg.mu.RUnlock()
+
+	if !found {
+		cfg.writeMu.Unlock()
+		return &Error{Op: OpRollback, Err: fmt.Errorf("%w %d", ErrUnknownGeneration, generation)}
+	}
+
+	if cfg.journal != nil {
+		err := cfg.journal.append(journalEntry{
+			Time:      time.Now().UTC(),
+			Op:        "rollback",
+			Values:    cfg.redactValues(overrideValues(rev.overrides), rev.secrets),
+			Actor:     ActorFromContext(ctx),
+			RequestID: RequestIDFromContext(ctx),
+		})
+		if err != nil {
+			cfg.writeMu.Unlock()
+			return &Error{Op: OpRollback, Err: fmt.Errorf("journal: %w", err)}
+		}
+	}
+
+	cfg.mu.Lock()
+	cfg.settings, cfg.base, cfg.secrets = rev.settings, rev.base, rev.secrets
+	cfg.origins, cfg.positions, cfg.overrides = rev.origins, rev.positions, rev.overrides
+	cfg.record("rollback")
+	cfg.mu.Unlock()
+	cfg.writeMu.Unlock()
+
+	changed := make(map[string]interface{})
+	for _, change := range diffSettings(old, rev.settings) {
+		changed[change.key] = nil
+	}
+	cfg.auditChange(ctx, "rollback", changed)
+	cfg.publishChange("rollback", old, rev.settings, oldSecrets, rev.secrets)
+	return nil
+}
+
+// overrideValues returns the values of the overrides by key, nil for keys
+// which are unset.
+func overrideValues(overrides []override) map[string]interface{} {
+	values := make(map[string]interface{}, len(overrides))
+	for _, o := range overrides {
+		values[o.key] = o.value
+	}
+	return values
+}
@@ -0,0 +1,66 @@
+package configwise
+
+import (
+	"testing"
+)
+
+func TestCloneHistoryIsIndependent(t *testing.T) {
+	c, err := NewConfigurer(WithType("yaml"), WithReadInConfig([]byte("name: base")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"first", "second"} {
+		if err = c.Overwrite(map[string]interface{}{"name": name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	clone := c.Clone()
+	if err = clone.Overwrite(map[string]interface{}{"name": "clone"}); err != nil {
+		t.Fatal(err)
+	}
+	generation := clone.Generation()
+	if err = c.Overwrite(map[string]interface{}{"name": "parent"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = clone.Rollback(generation); err != nil {
+		t.Fatal(err)
+	}
+	if got := clone.GetString("name"); got != "clone" {
+		t.Fatalf("clone rolled back to %q, want %q", got, "clone")
+	}
+	if got := c.GetString("name"); got != "parent" {
+		t.Fatalf("configurer has %q, want %q", got, "parent")
+	}
+}
+
+func TestRollback(t *testing.T) {
+	c, err := NewConfigurer(WithType("yaml"), WithHistory(2), WithReadInConfig([]byte("name: base")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	initial := c.Generation()
+	for _, name := range []string{"first", "second"} {
+		if err = c.Overwrite(map[string]interface{}{"name": name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = c.Rollback(initial); err == nil {
+		t.Fatal("rollback to a generation dropped from the history succeeded")
+	}
+	history := c.History()
+	if len(history) != 2 {
+		t.Fatalf("history has %d revisions, want 2", len(history))
+	}
+	if err = c.Rollback(history[0].Generation); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.GetString("name"); got != "first" {
+		t.Fatalf("rolled back to %q, want %q", got, "first")
+	}
+	if got := c.History(); got[len(got)-1].Trigger != "rollback" {
+		t.Fatalf("last revision was triggered by %q, want rollback", got[len(got)-1].Trigger)
+	}
+}
@@ -0,0 +1,826 @@
+package configwise
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxIncludeDepth limits nested includes of HOCON documents, which also
+// stops include cycles.
+const maxIncludeDepth = 32
+
+// isHOCON reports whether documents of the config type are HOCON.
+func isHOCON(configType string) bool {
+	return configType == "hocon" || configType == "conf"
+}
+
+// hoconSubst is a substitution like ${a.b} or ${?a.b}, which is resolved
+// once the whole document is parsed.
+type hoconSubst struct {
+	path     []string
+	optional bool
+}
+
+// hoconConcat is a concatenation of values holding substitutions, e.g.
+// ${base}"/bin" or ${defaults} { port = 80 }.
+type hoconConcat struct {
+	parts []interface{}
+	// spaces[i] is the whitespace before parts[i]
+	spaces []string
+}
+
+// hoconFallback is a value holding substitutions which replaced the prior
+// value of its key. The prior value is kept when the value resolves to an
+// undefined optional substitution, e.g. port = ${?PORT}.
+type hoconFallback struct {
+	value, prior interface{}
+}
+
+// decodeHOCON parses a HOCON document into a configuration tree. Includes
+// are relative to dir, substitutions are resolved against the whole document
+// and fall back to environment variables.
+func decodeHOCON(data []byte, dir string) (map[string]interface{}, error) {
+	root := make(map[string]interface{})
+	p := &hoconParser{src: []rune(string(data)), line: 1, col: 1, dir: dir, root: root}
+	if err := p.parseRoot(root, nil); err != nil {
+		return nil, err
+	}
+
+	r := &hoconResolver{root: root, visiting: make(map[string]bool)}
+	resolved, _, err := r.resolve(root)
+	if err != nil {
+		return nil, err
+	}
+	return resolved.(map[string]interface{}), nil
+}
+
+type hoconParser struct {
+	src       []rune
+	pos       int
+	line, col int
+	dir       string
+	depth     int
+	root      map[string]interface{}
+}
+
+func (p *hoconParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%d:%d: %s", p.line, p.col, fmt.Sprintf(format, args...))
+}
+
+func (p *hoconParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *hoconParser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *hoconParser) hasPrefix(s string) bool {
+	return strings.HasPrefix(string(p.src[p.pos:min(len(p.src), p.pos+len(s))]), s)
+}
+
+func (p *hoconParser) next() rune {
+	r := p.src[p.pos]
+	p.pos++
+	if r == '\n' {
+		p.line, p.col = p.line+1, 1
+	} else {
+		p.col++
+	}
+	return r
+}
+
+// skip skips whitespace and comments, and line breaks if newlines is set.
+// It returns the whitespace skipped on the current line.
+func (p *hoconParser) skip(newlines bool) string {
+	var ws strings.Builder
+	for !p.eof() {
+		r := p.peek()
+		switch {
+		case r == '\n' && !newlines:
+			return ws.String()
+		case r == '#' || p.hasPrefix("//"):
+			for !p.eof() && p.peek() != '\n' {
+				p.next()
+			}
+		case unicode.IsSpace(r) || r == '\uFEFF':
+			if r == '\n' {
+				ws.Reset()
+			} else {
+				ws.WriteRune(r)
+			}
+			p.next()
+		default:
+			return ws.String()
+		}
+	}
+	return ws.String()
+}
+
+// parseRoot parses a document, whose root braces are optional, into obj.
+func (p *hoconParser) parseRoot(obj map[string]interface{}, prefix []string) error {
+	p.skip(true)
+	if p.peek() == '{' {
+		p.next()
+		if err := p.parseFields(obj, prefix, '}'); err != nil {
+			return err
+		}
+		p.skip(true)
+	} else if p.peek() == '[' {
+		return p.errorf("root of document must be an object")
+	} else if err := p.parseFields(obj, prefix, 0); err != nil {
+		return err
+	}
+	if !p.eof() {
+		return p.errorf("unexpected %q", p.peek())
+	}
+	return nil
+}
+
+// parseFields parses the fields of an object into obj up to and including
+// the closing brace, or up to the end of the document for a closing of 0.
+func (p *hoconParser) parseFields(obj map[string]interface{}, prefix []string, closing rune) error {
+	for {
+		p.skip(true)
+		switch {
+		case p.eof() && closing == 0:
+			return nil
+		case p.eof():
+			return p.errorf("expected %q", closing)
+		case p.peek() == closing:
+			p.next()
+			return nil
+		case closing == 0 && p.peek() == '}':
+			return p.errorf("unexpected %q", '}')
+		}
+
+		if err := p.parseField(obj, prefix); err != nil {
+			return err
+		}
+
+		p.skip(false)
+		switch {
+		case p.eof(), p.peek() == '\n', p.peek() == closing:
+		case p.peek() == ',':
+			p.next()
+		default:
+			return p.errorf("expected end of field, found %q", p.peek())
+		}
+	}
+}
+
+func (p *hoconParser) parseField(obj map[string]interface{}, prefix []string) error {
+	if p.hasPrefix("include") {
+		save := *p
+		p.pos, p.col = p.pos+len("include"), p.col+len("include")
+		if ws := p.skip(false); ws != "" && (p.peek() == '"' || unicode.IsLetter(p.peek())) {
+			return p.parseInclude(obj, prefix)
+		}
+		*p = save
+	}
+
+	path, err := p.parsePath(false)
+	if err != nil {
+		return err
+	}
+	p.skip(false)
+
+	appending := false
+	switch {
+	case p.peek() == '{':
+	case p.hasPrefix("+="):
+		p.pos, p.col, appending = p.pos+2, p.col+2, true
+	case p.peek() == ':' || p.peek() == '=':
+		p.next()
+	default:
+		return p.errorf("expected ':', '=' or '{' after key %s", strings.Join(path, keyDelimiter))
+	}
+	p.skip(false)
+
+	full := append(append([]string(nil), prefix...), path...)
+	parent := obj
+	for _, name := range path[:len(path)-1] {
+		next, ok := parent[name].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			parent[name] = next
+		}
+		parent = next
+	}
+	name := path[len(path)-1]
+
+	// objects are merged into existing objects of the key
+	if !appending && p.peek() == '{' {
+		if existing, ok := parent[name].(map[string]interface{}); ok {
+			save := *p
+			p.next()
+			if err := p.parseFields(existing, full, '}'); err != nil {
+				return err
+			}
+			if p.skip(false); p.atValueEnd() {
+				return nil
+			}
+			// the object is concatenated with other values
+			*p = save
+		}
+	}
+
+	value, err := p.parseValue(full)
+	if err != nil {
+		return err
+	}
+	if appending {
+		value = &hoconConcat{
+			parts:  []interface{}{&hoconSubst{path: full, optional: true}, []interface{}{value}},
+			spaces: []string{"", ""},
+		}
+	}
+
+	value, ok, err := p.resolveSelf(value, full)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	if prior, isMap := parent[name].(map[string]interface{}); isMap {
+		if m, isMap := value.(map[string]interface{}); isMap {
+			mergeHOCON(prior, m)
+			return nil
+		}
+	}
+	if prior, exists := parent[name]; exists && hasSubst(value) {
+		value = &hoconFallback{value: value, prior: prior}
+	}
+	parent[name] = value
+	return nil
+}
+
+// atValueEnd reports whether the parser is at the end of a value.
+func (p *hoconParser) atValueEnd() bool {
+	if p.eof() || p.hasPrefix("//") {
+		return true
+	}
+	switch p.peek() {
+	case '\n', ',', '}', ']', '#':
+		return true
+	}
+	return false
+}
+
+// parseInclude parses the target of an include statement and merges the
+// included document into obj.
+func (p *hoconParser) parseInclude(obj map[string]interface{}, prefix []string) error {
+	required := false
+	if p.hasPrefix("required(") {
+		required = true
+		p.pos, p.col = p.pos+len("required("), p.col+len("required(")
+	}
+
+	var name string
+	switch {
+	case p.hasPrefix("file("):
+		p.pos, p.col = p.pos+len("file("), p.col+len("file(")
+		s, err := p.parseQuoted()
+		if err != nil {
+			return err
+		}
+		if p.peek() != ')' {
+			return p.errorf("expected ')'")
+		}
+		p.next()
+		name = s
+	case p.peek() == '"':
+		s, err := p.parseQuoted()
+		if err != nil {
+			return err
+		}
+		name = s
+	default:
+		return p.errorf("unsupported include, only files can be included")
+	}
+	if required {
+		if p.peek() != ')' {
+			return p.errorf("expected ')'")
+		}
+		p.next()
+	}
+
+	if p.depth >= maxIncludeDepth {
+		return p.errorf("include %s: too many nested includes", name)
+	}
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(p.dir, name)
+	}
+
+	candidates := []string{name}
+	if filepath.Ext(name) == "" {
+		candidates = []string{name + ".conf", name + ".json"}
+	}
+	for _, file := range candidates {
+		data, err := os.ReadFile(file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return p.errorf("include: %v", err)
+		}
+
+		sub := &hoconParser{src: []rune(string(data)), line: 1, col: 1, dir: filepath.Dir(file), depth: p.depth + 1, root: p.root}
+		if err := sub.parseRoot(obj, prefix); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		return nil
+	}
+	if required {
+		return p.errorf("include %s: %v", name, os.ErrNotExist)
+	}
+	return nil
+}
+
+// parsePath parses a key or the path of a substitution, e.g. a.b."c.d".
+func (p *hoconParser) parsePath(subst bool) ([]string, error) {
+	var (
+		path    []string
+		segment strings.Builder
+		started bool
+	)
+	for !p.eof() {
+		r := p.peek()
+		switch {
+		case r == '"':
+			s, err := p.parseQuoted()
+			if err != nil {
+				return nil, err
+			}
+			segment.WriteString(s)
+			started = true
+			continue
+		case r == '.':
+			if !started {
+				return nil, p.errorf("empty key segment")
+			}
+			path = append(path, segment.String())
+			segment.Reset()
+			started = false
+			p.next()
+			continue
+		case p.hasPrefix("+=") && !subst:
+		case unicode.IsSpace(r) || strings.ContainsRune(hoconForbidden, r) || p.hasPrefix("//"):
+		default:
+			segment.WriteRune(p.next())
+			started = true
+			continue
+		}
+		break
+	}
+	if !started {
+		if p.eof() {
+			return nil, p.errorf("expected key")
+		}
+		return nil, p.errorf("expected key, found %q", p.peek())
+	}
+	return append(path, segment.String()), nil
+}
+
+// hoconForbidden are the characters which cannot be part of unquoted strings.
+const hoconForbidden = "$\"{}[]:=,+#`^?!@*&\\"
+
+// parseValue parses a value, which may be a concatenation of values.
+func (p *hoconParser) parseValue(path []string) (interface{}, error) {
+	var (
+		parts  []interface{}
+		spaces []string
+		space  string
+		// unquoted scalars are only typed when they are the whole value
+		unquoted bool
+	)
+	for !p.atValueEnd() {
+		var (
+			part interface{}
+			err  error
+		)
+		r := p.peek()
+		unquoted = false
+		switch {
+		case r == '{':
+			p.next()
+			m := make(map[string]interface{})
+			err = p.parseFields(m, path, '}')
+			part = m
+		case r == '[':
+			part, err = p.parseArray(path)
+		case p.hasPrefix(`"""`):
+			part, err = p.parseTripleQuoted()
+		case r == '"':
+			part, err = p.parseQuoted()
+		case p.hasPrefix("${"):
+			part, err = p.parseSubst()
+		default:
+			var s strings.Builder
+			for !p.eof() && !unicode.IsSpace(p.peek()) && !strings.ContainsRune(hoconForbidden, p.peek()) && !p.hasPrefix("//") {
+				s.WriteRune(p.next())
+			}
+			if s.Len() == 0 {
+				return nil, p.errorf("unexpected %q", p.peek())
+			}
+			part, unquoted = s.String(), true
+		}
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+		spaces = append(spaces, space)
+		space = p.skip(false)
+	}
+
+	switch len(parts) {
+	case 0:
+		return nil, p.errorf("expected value")
+	case 1:
+		if s, ok := parts[0].(string); ok && unquoted {
+			return hoconScalar(s), nil
+		}
+		return parts[0], nil
+	}
+	concat := &hoconConcat{parts: parts, spaces: spaces}
+	if hasSubst(concat) {
+		return concat, nil
+	}
+	return concatHOCON(concat.parts, concat.spaces)
+}
+
+func (p *hoconParser) parseArray(path []string) ([]interface{}, error) {
+	p.next()
+	items := []interface{}{}
+	for {
+		p.skip(true)
+		if p.eof() {
+			return nil, p.errorf("expected ']'")
+		}
+		if p.peek() == ']' {
+			p.next()
+			return items, nil
+		}
+
+		item, err := p.parseValue(path)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+
+		p.skip(false)
+		switch {
+		case p.peek() == ',':
+			p.next()
+		case p.peek() == '\n', p.peek() == ']':
+		default:
+			return nil, p.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+func (p *hoconParser) parseQuoted() (string, error) {
+	start := p.pos
+	p.next()
+	for !p.eof() && p.peek() != '"' {
+		if p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		if p.next() == '\\' && !p.eof() {
+			p.next()
+		}
+	}
+	if p.eof() {
+		return "", p.errorf("unterminated string")
+	}
+	p.next()
+	s, err := strconv.Unquote(string(p.src[start:p.pos]))
+	if err != nil {
+		return "", p.errorf("invalid string: %v", err)
+	}
+	return s, nil
+}
+
+func (p *hoconParser) parseTripleQuoted() (string, error) {
+	for range 3 {
+		p.next()
+	}
+	var s strings.Builder
+	for !p.eof() {
+		// quotes before the closing quotes belong to the string
+		if p.hasPrefix(`"""`) && !p.hasPrefix(`""""`) {
+			for range 3 {
+				p.next()
+			}
+			return s.String(), nil
+		}
+		s.WriteRune(p.next())
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *hoconParser) parseSubst() (*hoconSubst, error) {
+	p.next()
+	p.next()
+	subst := &hoconSubst{}
+	if p.peek() == '?' {
+		p.next()
+		subst.optional = true
+	}
+	p.skip(false)
+	path, err := p.parsePath(true)
+	if err != nil {
+		return nil, err
+	}
+	p.skip(false)
+	if p.peek() != '}' {
+		return nil, p.errorf("expected '}' after substitution")
+	}
+	p.next()
+	subst.path = path
+	return subst, nil
+}
+
+// resolveSelf replaces substitutions of the key in its own value with the
+// prior value of the key, e.g. path = ${path}":/opt/bin". It reports false
+// when the value is an optional substitution of a missing prior value.
+func (p *hoconParser) resolveSelf(value interface{}, path []string) (interface{}, bool, error) {
+	key := strings.Join(path, keyDelimiter)
+	switch v := value.(type) {
+	case *hoconSubst:
+		if strings.Join(v.path, keyDelimiter) != key {
+			return v, true, nil
+		}
+		prior, ok := lookupHOCON(p.root, v.path)
+		if !ok {
+			if env, ok := os.LookupEnv(key); ok {
+				return env, true, nil
+			}
+			if v.optional {
+				return nil, false, nil
+			}
+			return nil, false, p.errorf("undefined substitution ${%s}", key)
+		}
+		return prior, true, nil
+	case *hoconConcat:
+		parts := make([]interface{}, 0, len(v.parts))
+		spaces := make([]string, 0, len(v.spaces))
+		for i, part := range v.parts {
+			resolved, ok, err := p.resolveSelf(part, path)
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				parts = append(parts, resolved)
+				spaces = append(spaces, v.spaces[i])
+			}
+		}
+		concat := &hoconConcat{parts: parts, spaces: spaces}
+		if len(parts) == 0 {
+			return nil, false, nil
+		}
+		if hasSubst(concat) {
+			return concat, true, nil
+		}
+		value, err := concatHOCON(parts, spaces)
+		return value, err == nil, err
+	default:
+		return value, true, nil
+	}
+}
+
+// hoconScalar types an unquoted value.
+func hoconScalar(s string) interface{} {
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
+
+func hasSubst(value interface{}) bool {
+	switch v := value.(type) {
+	case *hoconSubst, *hoconFallback:
+		return true
+	case *hoconConcat:
+		for _, part := range v.parts {
+			if hasSubst(part) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		for _, val := range v {
+			if hasSubst(val) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, val := range v {
+			if hasSubst(val) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// concatHOCON concatenates resolved values: strings and scalars into a
+// string, arrays into an array and objects into a merged object.
+func concatHOCON(parts []interface{}, spaces []string) (interface{}, error) {
+	switch parts[0].(type) {
+	case []interface{}:
+		var out []interface{}
+		for _, part := range parts {
+			items, ok := part.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("cannot concatenate array with %T", part)
+			}
+			out = append(out, items...)
+		}
+		return out, nil
+	case map[string]interface{}:
+		out := make(map[string]interface{})
+		for _, part := range parts {
+			m, ok := part.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("cannot concatenate object with %T", part)
+			}
+			mergeHOCON(out, m)
+		}
+		return out, nil
+	default:
+		var s strings.Builder
+		for i, part := range parts {
+			switch part.(type) {
+			case []interface{}, map[string]interface{}:
+				return nil, fmt.Errorf("cannot concatenate string with %T", part)
+			}
+			if i > 0 {
+				s.WriteString(spaces[i])
+			}
+			if part != nil {
+				s.WriteString(fmt.Sprint(part))
+			}
+		}
+		return s.String(), nil
+	}
+}
+
+// mergeHOCON merges src into dst, merging objects present in both.
+func mergeHOCON(dst, src map[string]interface{}) {
+	for key, value := range src {
+		if srcMap, ok := value.(map[string]interface{}); ok {
+			if dstMap, ok := dst[key].(map[string]interface{}); ok {
+				mergeHOCON(dstMap, srcMap)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+}
+
+func lookupHOCON(root map[string]interface{}, path []string) (interface{}, bool) {
+	var current interface{} = root
+	for _, name := range path {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[name]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// hoconResolver resolves the substitutions of a parsed document.
+type hoconResolver struct {
+	root     map[string]interface{}
+	visiting map[string]bool
+}
+
+// resolve returns a copy of the value with all substitutions resolved. It
+// reports false for optional substitutions of missing values.
+func (r *hoconResolver) resolve(value interface{}) (interface{}, bool, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			resolved, ok, err := r.resolve(val)
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				out[key] = resolved
+			}
+		}
+		return out, true, nil
+	case []interface{}:
+		out := make([]interface{}, 0, len(v))
+		for _, val := range v {
+			resolved, ok, err := r.resolve(val)
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				out = append(out, resolved)
+			}
+		}
+		return out, true, nil
+	case *hoconSubst:
+		return r.substitute(v)
+	case *hoconConcat:
+		var (
+			parts  []interface{}
+			spaces []string
+		)
+		for i, part := range v.parts {
+			resolved, ok, err := r.resolve(part)
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				parts = append(parts, resolved)
+				spaces = append(spaces, v.spaces[i])
+			}
+		}
+		if len(parts) == 0 {
+			return nil, false, nil
+		}
+		resolved, err := concatHOCON(parts, spaces)
+		return resolved, err == nil, err
+	case *hoconFallback:
+		resolved, ok, err := r.resolve(v.value)
+		if err != nil || ok {
+			return resolved, ok, err
+		}
+		return r.resolve(v.prior)
+	default:
+		return value, true, nil
+	}
+}
+
+func (r *hoconResolver) substitute(subst *hoconSubst) (interface{}, bool, error) {
+	key := strings.Join(subst.path, keyDelimiter)
+	if r.visiting[key] {
+		return nil, false, fmt.Errorf("cyclic substitution ${%s}", key)
+	}
+	r.visiting[key] = true
+	defer delete(r.visiting, key)
+
+	var current interface{} = r.root
+	found := true
+	for _, name := range subst.path {
+		// the values on the way may be substitutions themselves
+		switch current.(type) {
+		case *hoconSubst, *hoconConcat, *hoconFallback:
+			resolved, ok, err := r.resolve(current)
+			if err != nil {
+				return nil, false, err
+			}
+			if !ok {
+				resolved = nil
+			}
+			current = resolved
+		}
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			found = false
+			break
+		}
+		if current, ok = m[name]; !ok {
+			found = false
+			break
+		}
+	}
+	if found {
+		return r.resolve(current)
+	}
+
+	if env, ok := os.LookupEnv(key); ok {
+		return env, true, nil
+	}
+	if subst.optional {
+		return nil, false, nil
+	}
+	return nil, false, fmt.Errorf("undefined substitution ${%s}", key)
+}
@@ -0,0 +1,321 @@
+package configwise
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeHOCON(t *testing.T) {
+	t.Setenv("CONFIGWISE_TEST_HOME", "/home/app")
+
+	tests := []struct {
+		name string
+		doc  string
+		want map[string]interface{}
+	}{
+		{
+			name: "separators and comments",
+			doc: `
+# comment
+a = 1
+b: "two" // comment
+c { d = true }
+e = null
+`,
+			want: map[string]interface{}{
+				"a": int64(1),
+				"b": "two",
+				"c": map[string]interface{}{"d": true},
+				"e": nil,
+			},
+		},
+		{
+			name: "root braces",
+			doc:  `{ a = 1.5, b = [1, 2] }`,
+			want: map[string]interface{}{"a": 1.5, "b": []interface{}{int64(1), int64(2)}},
+		},
+		{
+			name: "paths",
+			doc: `
+server.http.port = 8080
+"a.b" = 1
+`,
+			want: map[string]interface{}{
+				"server": map[string]interface{}{"http": map[string]interface{}{"port": int64(8080)}},
+				"a.b":    int64(1),
+			},
+		},
+		{
+			name: "objects merge",
+			doc: `
+server { host = localhost, port = 80 }
+server { port = 8080 }
+server.tls = true
+`,
+			want: map[string]interface{}{
+				"server": map[string]interface{}{"host": "localhost", "port": int64(8080), "tls": true},
+			},
+		},
+		{
+			name: "values override",
+			doc: `
+server { port = 80 }
+server = 8080
+`,
+			want: map[string]interface{}{"server": int64(8080)},
+		},
+		{
+			name: "unquoted concatenation",
+			doc:  `greeting = hello   world`,
+			want: map[string]interface{}{"greeting": "hello   world"},
+		},
+		{
+			name: "triple quoted",
+			doc:  "text = \"\"\"a \"quoted\"\nline\"\"\"",
+			want: map[string]interface{}{"text": "a \"quoted\"\nline"},
+		},
+		{
+			name: "substitutions",
+			doc: `
+base = /opt
+bin = ${base}"/bin"
+later = ${server.port}
+server.port = 8080
+`,
+			want: map[string]interface{}{
+				"base":   "/opt",
+				"bin":    "/opt/bin",
+				"later":  int64(8080),
+				"server": map[string]interface{}{"port": int64(8080)},
+			},
+		},
+		{
+			name: "optional substitution",
+			doc: `
+a = ${?configwise_test_missing}
+b = 1
+b = ${?configwise_test_missing}
+`,
+			want: map[string]interface{}{"b": int64(1)},
+		},
+		{
+			name: "optional substitution of environment",
+			doc: `
+home = /root
+home = ${?CONFIGWISE_TEST_HOME}
+`,
+			want: map[string]interface{}{"home": "/home/app"},
+		},
+		{
+			name: "environment",
+			doc:  `home = ${CONFIGWISE_TEST_HOME}`,
+			want: map[string]interface{}{"home": "/home/app"},
+		},
+		{
+			name: "self reference",
+			doc: `
+path = /bin
+path = ${path}":/opt/bin"
+`,
+			want: map[string]interface{}{"path": "/bin:/opt/bin"},
+		},
+		{
+			name: "append",
+			doc: `
+list = [1]
+list += 2
+empty += 1
+`,
+			want: map[string]interface{}{
+				"list":  []interface{}{int64(1), int64(2)},
+				"empty": []interface{}{int64(1)},
+			},
+		},
+		{
+			name: "array concatenation",
+			doc: `
+a = [1]
+b = ${a} [2]
+`,
+			want: map[string]interface{}{
+				"a": []interface{}{int64(1)},
+				"b": []interface{}{int64(1), int64(2)},
+			},
+		},
+		{
+			name: "object concatenation",
+			doc: `
+defaults { port = 80, host = localhost }
+server = ${defaults} { port = 8080 }
+`,
+			want: map[string]interface{}{
+				"defaults": map[string]interface{}{"port": int64(80), "host": "localhost"},
+				"server":   map[string]interface{}{"port": int64(8080), "host": "localhost"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeHOCON([]byte(tt.doc), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tree is %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeHOCONIncludes(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.conf":         "server { host = localhost, port = 80 }\nname = base",
+		"nested/tls.conf":   `include "certs"` + "\ntls = true",
+		"nested/certs.conf": "cert = server.pem",
+		"loop.conf":         `include "loop.conf"`,
+	}
+	for name, data := range files {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		doc  string
+		want map[string]interface{}
+		err  string
+	}{
+		{
+			name: "include",
+			doc: `
+include "base.conf"
+server.port = 8080
+`,
+			want: map[string]interface{}{
+				"server": map[string]interface{}{"host": "localhost", "port": int64(8080)},
+				"name":   "base",
+			},
+		},
+		{
+			name: "overridden by include",
+			doc: `
+name = app
+include file("base")
+`,
+			want: map[string]interface{}{
+				"server": map[string]interface{}{"host": "localhost", "port": int64(80)},
+				"name":   "base",
+			},
+		},
+		{
+			name: "include into object",
+			doc:  `server { include "nested/tls.conf" }`,
+			want: map[string]interface{}{
+				"server": map[string]interface{}{"tls": true, "cert": "server.pem"},
+			},
+		},
+		{
+			name: "substitution of included value",
+			doc: `
+include "base"
+url = "http://"${server.host}
+`,
+			want: map[string]interface{}{
+				"server": map[string]interface{}{"host": "localhost", "port": int64(80)},
+				"name":   "base",
+				"url":    "http://localhost",
+			},
+		},
+		{
+			name: "missing include",
+			doc:  `include "missing.conf"` + "\na = 1",
+			want: map[string]interface{}{"a": int64(1)},
+		},
+		{
+			name: "missing required include",
+			doc:  `include required("missing.conf")`,
+			err:  "include " + filepath.Join(dir, "missing.conf"),
+		},
+		{
+			name: "include cycle",
+			doc:  `include "loop.conf"`,
+			err:  "too many nested includes",
+		},
+		{
+			name: "include key",
+			doc:  `include = 1`,
+			want: map[string]interface{}{"include": int64(1)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeHOCON([]byte(tt.doc), dir)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error is %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tree is %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeHOCONErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		err  string
+	}{
+		{name: "missing separator", doc: "a 1", err: "1:3: expected ':', '=' or '{' after key a"},
+		{name: "undefined substitution", doc: "a = ${configwise_test_missing}", err: "undefined substitution ${configwise_test_missing}"},
+		{name: "cyclic substitution", doc: "a = ${b}\nb = ${a}", err: "cyclic substitution"},
+		{name: "unterminated string", doc: `a = "open`, err: "1:"},
+		{name: "unterminated object", doc: "a { b = 1", err: "1:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeHOCON([]byte(tt.doc), "")
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error is %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestHOCONConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"app.conf":      "include \"defaults\"\nserver.port = 8080",
+		"defaults.conf": "server { host = localhost, port = 80 }",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c, err := NewConfigurer(WithPath(filepath.Join(dir, "app.conf")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.GetString("server.host"); got != "localhost" {
+		t.Errorf("server.host is %q, want localhost", got)
+	}
+	if got := c.GetInt("server.port"); got != 8080 {
+		t.Errorf("server.port is %d, want 8080", got)
+	}
+}
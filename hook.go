@@ -0,0 +1,199 @@
+package configwise
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// Names of the built-in decode hooks, which convert strings to typed values.
+const (
+	HookUUID     = "uuid"
+	HookTime     = "time"
+	HookDuration = "duration"
+	// HookIP converts strings to net.IP and netip.Addr.
+	HookIP = "ip"
+	// HookCIDR converts strings in CIDR notation, e.g. 10.0.0.0/8, to
+	// net.IPNet and netip.Prefix.
+	HookCIDR  = "cidr"
+	HookSlice = "slice"
+	// HookCustom is the name of the hooks added with WithDecodeHook.
+	HookCustom = "custom"
+)
+
+// hookSuffix marks the errors of named hooks within the messages of the decoder.
+const hookSuffix = " hook: "
+
+type namedHook struct {
+	name string
+	hook mapstructure.DecodeHookFunc
+}
+
+// decodeHooks are the hooks of the decoder, applied in order.
+var decodeHooks = []namedHook{
+	{name: HookUUID, hook: stringToUUID},
+	{name: HookTime, hook: mapstructure.StringToTimeHookFunc(time.RFC3339)},
+	{name: HookDuration, hook: mapstructure.StringToTimeDurationHookFunc()},
+	// net.IP is a slice, so it must be converted before strings are split
+	{name: HookIP, hook: stringToIP},
+	{name: HookCIDR, hook: stringToCIDR},
+	{name: HookSlice, hook: mapstructure.StringToSliceHookFunc(",")},
+}
+
+// stringToIP converts strings to net.IP and netip.Addr.
+func stringToIP(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String {
+		return data, nil
+	}
+	switch to {
+	case reflect.TypeOf(net.IP{}):
+		ip := net.ParseIP(data.(string))
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address %q", data)
+		}
+		return ip, nil
+	case reflect.TypeOf(netip.Addr{}):
+		return netip.ParseAddr(data.(string))
+	}
+	return data, nil
+}
+
+// stringToCIDR converts strings in CIDR notation to net.IPNet and netip.Prefix.
+func stringToCIDR(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String {
+		return data, nil
+	}
+	switch to {
+	case reflect.TypeOf(net.IPNet{}):
+		_, ipNet, err := net.ParseCIDR(data.(string))
+		if err != nil {
+			return nil, err
+		}
+		return *ipNet, nil
+	case reflect.TypeOf(netip.Prefix{}):
+		return netip.ParsePrefix(data.(string))
+	}
+	return data, nil
+}
+
+// WithDecodeHook adds hooks to the decoder of Unmarshal, UnmarshalKey and the
+// getters, e.g. to convert strings to types of the application. The hooks are
+// applied in order after the built-in hooks, and their failures are subject
+// to the policy of HookCustom.
+func WithDecodeHook(hooks ...mapstructure.DecodeHookFunc) Option {
+	return func(c *configurer) {
+		c.customHooks = append(c.customHooks, hooks...)
+	}
+}
+
+// hooks returns the built-in hooks followed by the hooks of WithDecodeHook.
+func (cfg *configurer) hooks() []namedHook {
+	hooks := make([]namedHook, 0, len(decodeHooks)+len(cfg.customHooks))
+	hooks = append(hooks, decodeHooks...)
+	for _, hook := range cfg.customHooks {
+		hooks = append(hooks, namedHook{name: HookCustom, hook: hook})
+	}
+	return hooks
+}
+
+// composeHooks composes the hooks, prefixing their errors with their names
+// so that failures can be attributed to a hook. The decoder reports errors
+// as messages only, so the errors of the hooks are recorded in causes, if not
+// nil, by their message.
+func composeHooks(hooks []namedHook, causes map[string]error) mapstructure.DecodeHookFunc {
+	funcs := make([]mapstructure.DecodeHookFunc, len(hooks))
+	for i, h := range hooks {
+		h := h
+		funcs[i] = func(from reflect.Value, to reflect.Value) (interface{}, error) {
+			out, err := mapstructure.DecodeHookExec(h.hook, from, to)
+			if err != nil {
+				wrapped := fmt.Errorf("%s%s%w", h.name, hookSuffix, err)
+				if causes != nil {
+					causes[wrapped.Error()] = err
+				}
+				return nil, wrapped
+			}
+			return out, nil
+		}
+	}
+	return mapstructure.ComposeDecodeHookFunc(funcs...)
+}
+
+// splitHookError splits the reason of a failing hook into the name of the hook
+// and its error.
+func splitHookError(reason string) (string, string) {
+	name, rest, ok := strings.Cut(reason, hookSuffix)
+	if !ok || strings.ContainsAny(name, " :'") {
+		return "", reason
+	}
+	return name, rest
+}
+
+// HookPolicy defines how failures of a decode hook are handled.
+type HookPolicy int
+
+const (
+	// HookStrict fails Unmarshal and UnmarshalKey when the hook fails.
+	HookStrict HookPolicy = iota
+	// HookLenient ignores values the hook fails to convert, so their fields
+	// keep their defaults, and reports a WarningDecodeFallback instead.
+	HookLenient
+)
+
+type hookPolicy struct {
+	hook   string
+	policy HookPolicy
+	keys   []string
+}
+
+// WithHookPolicy sets the policy for failures of the named decode hook, or of
+// all hooks for "*", e.g. WithHookPolicy(HookUUID, HookLenient, "*.trace_id").
+// The policy applies to keys matching one of the patterns, in which * matches
+// a single key segment, and to their children, or to all keys without patterns.
+// Hooks are strict by default; the policy registered last wins.
+func WithHookPolicy(hook string, policy HookPolicy, keys ...string) Option {
+	return func(c *configurer) {
+		patterns := make([]string, len(keys))
+		for i, key := range keys {
+			patterns[i] = strings.ToLower(key)
+		}
+		c.hookPolicies = append(c.hookPolicies, hookPolicy{hook: hook, policy: policy, keys: patterns})
+	}
+}
+
+// hookPolicy returns the policy for failures of the hook for the key.
+func (cfg *configurer) hookPolicy(hook, key string) HookPolicy {
+	policy := HookStrict
+	for _, p := range cfg.hookPolicies {
+		if p.hook != hook && p.hook != "*" {
+			continue
+		}
+		if len(p.keys) == 0 || matchKeys(p.keys, key) {
+			policy = p.policy
+		}
+	}
+	return policy
+}
+
+// applyHookPolicies reports failures of lenient hooks as warnings and returns
+// the remaining errors.
+func (cfg *configurer) applyHookPolicies(errs []*DecodeError) error {
+	var (
+		strict   []*DecodeError
+		warnings []Warning
+	)
+	for _, e := range errs {
+		if e.Hook != "" && cfg.hookPolicy(e.Hook, e.Key) == HookLenient {
+			warnings = append(warnings, Warning{Kind: WarningDecodeFallback, Key: e.Key, Name: e.Hook})
+			continue
+		}
+		strict = append(strict, e)
+	}
+	cfg.addWarnings(warnings...)
+	return joinDecodeErrors(strict)
+}
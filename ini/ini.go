@@ -0,0 +1,79 @@
+// Package ini decodes INI config documents for configwise. It is a separate
+// package so that only applications reading INI link the INI library:
+//
+//	c, err := configwise.NewConfigurer(ini.Option())
+package ini
+
+import (
+	"strings"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/gowool/configwise"
+)
+
+// Option registers Decode for config documents of type ini, detected by the
+// .ini extension.
+func Option() configwise.Option {
+	return configwise.WithDecoder("ini", Decode)
+}
+
+// Decode parses an INI document into a configuration tree. Keys of a
+// section are nested below the section, which is itself nested at its
+// dots, e.g. port of [server.http] becomes server.http.port. Keys before the
+// first section are top-level keys. Values are kept as strings.
+func Decode(data []byte) (map[string]interface{}, error) {
+	file, err := ini.LoadSources(ini.LoadOptions{
+		SpaceBeforeInlineComment: true,
+		AllowBooleanKeys:         true,
+	}, data)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := make(map[string]interface{})
+	for _, section := range file.Sections() {
+		var path []string
+		if name := section.Name(); name != ini.DefaultSection {
+			path = strings.Split(name, ".")
+		}
+		for _, key := range section.Keys() {
+			keyPath := append(path[:len(path):len(path)], strings.Split(key.Name(), ".")...)
+			parent := sectionOf(tree, keyPath[:len(keyPath)-1])
+			parent[keyPath[len(keyPath)-1]] = key.Value()
+		}
+		if len(path) > 0 && len(section.Keys()) == 0 && !exists(tree, path) {
+			sectionOf(tree, path)
+		}
+	}
+	return tree, nil
+}
+
+// sectionOf returns the section at the path, creating sections as needed
+// and replacing any value in the way.
+func sectionOf(tree map[string]interface{}, path []string) map[string]interface{} {
+	for _, name := range path {
+		next, ok := tree[name].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			tree[name] = next
+		}
+		tree = next
+	}
+	return tree
+}
+
+// exists reports whether a value is stored at the path.
+func exists(tree map[string]interface{}, path []string) bool {
+	var current interface{} = tree
+	for _, name := range path {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if current, ok = m[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,167 @@
+package ini
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gowool/configwise"
+)
+
+type decodeTest struct {
+	name string
+	doc  string
+	want map[string]interface{}
+	err  string
+}
+
+func runDecodeTests(t *testing.T, tests []decodeTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode([]byte(tt.doc))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error is %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tree is %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSections(t *testing.T) {
+	runDecodeTests(t, []decodeTest{
+		{
+			name: "top-level keys",
+			doc:  "name = app\nport = 8080\n",
+			want: map[string]interface{}{"name": "app", "port": "8080"},
+		},
+		{
+			name: "dotted sections",
+			doc: `
+[server]
+host = localhost
+
+[server.tls]
+enabled = true
+`,
+			want: map[string]interface{}{
+				"server": map[string]interface{}{
+					"host": "localhost",
+					"tls":  map[string]interface{}{"enabled": "true"},
+				},
+			},
+		},
+		{
+			name: "dotted keys",
+			doc:  "[db]\npool.size = 10\n",
+			want: map[string]interface{}{
+				"db": map[string]interface{}{"pool": map[string]interface{}{"size": "10"}},
+			},
+		},
+		{
+			name: "empty sections",
+			doc:  "[cache]\n[server.http]\n",
+			want: map[string]interface{}{
+				"cache":  map[string]interface{}{},
+				"server": map[string]interface{}{"http": map[string]interface{}{}},
+			},
+		},
+		{
+			name: "empty section of a value",
+			doc:  "cache = off\n[cache]\n",
+			want: map[string]interface{}{"cache": "off"},
+		},
+		{
+			name: "unclosed section",
+			doc:  "[server\nport = 1",
+			err:  "unclosed section",
+		},
+	})
+}
+
+func TestValues(t *testing.T) {
+	runDecodeTests(t, []decodeTest{
+		{
+			name: "quoted values",
+			doc:  "greeting = \"hello world\"\npadded = '  a  '\n",
+			want: map[string]interface{}{"greeting": "hello world", "padded": "  a  "},
+		},
+		{
+			name: "colon separator",
+			doc:  "host: localhost\n",
+			want: map[string]interface{}{"host": "localhost"},
+		},
+		{
+			name: "empty value",
+			doc:  "token =\n",
+			want: map[string]interface{}{"token": ""},
+		},
+		{
+			name: "boolean keys",
+			doc:  "[features]\nbeta\n",
+			want: map[string]interface{}{
+				"features": map[string]interface{}{"beta": "true"},
+			},
+		},
+		{
+			name: "multi-line values",
+			doc:  "motd = \"\"\"hello\nworld\"\"\"\n",
+			want: map[string]interface{}{"motd": "hello\nworld"},
+		},
+	})
+}
+
+func TestComments(t *testing.T) {
+	runDecodeTests(t, []decodeTest{
+		{
+			name: "comment lines",
+			doc:  "; semicolon\n# hash\n[server] ; section comment\nport = 80\n",
+			want: map[string]interface{}{"server": map[string]interface{}{"port": "80"}},
+		},
+		{
+			name: "inline comments",
+			doc:  "name = app ; comment\nregion = eu # comment\n",
+			want: map[string]interface{}{"name": "app", "region": "eu"},
+		},
+		{
+			name: "comment markers without a space",
+			doc:  "dsn = user:pass@host;db\ncolor = #fff\n",
+			want: map[string]interface{}{"dsn": "user:pass@host;db", "color": "#fff"},
+		},
+		{
+			name: "comment markers in backquotes",
+			doc:  "pattern = `a ; b # c`\n",
+			want: map[string]interface{}{"pattern": "a ; b # c"},
+		},
+	})
+}
+
+func TestKeyCase(t *testing.T) {
+	doc := []byte("[Server.HTTP]\nPort = 8080\n")
+
+	tree, err := Decode(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tree["Server"]; !ok {
+		t.Fatalf("tree is %#v, want the keys as written", tree)
+	}
+
+	c, err := configwise.NewConfigurer(Option(), configwise.WithType("ini"), configwise.WithReadInConfig(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"server.http.port", "Server.HTTP.Port", "SERVER.HTTP.PORT"} {
+		if got := c.GetInt(key); got != 8080 {
+			t.Errorf("%s is %d, want 8080", key, got)
+		}
+	}
+}
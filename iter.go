@@ -0,0 +1,104 @@
+package configwise
+
+import (
+	"iter"
+	"slices"
+	"sort"
+)
+
+// Keys returns an iterator over the sorted keys of all values below prefix,
+// or of all values for an empty prefix. It walks the settings at the time of
+// the call without materializing the list of keys.
+func (cfg *configurer) Keys(prefix string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		key, tree, ok := cfg.subtree(prefix)
+		if !ok {
+			return
+		}
+		walkLeaves(key, tree, func(key string, _ interface{}) bool {
+			return yield(key)
+		})
+	}
+}
+
+// Values returns an iterator over the keys and values of all values below
+// prefix, ordered by key. Like Keys, it walks the settings at the time of the
+// call. Values are copies, lazy secret references are resolved.
+func (cfg *configurer) Values(prefix string) iter.Seq2[string, interface{}] {
+	return func(yield func(string, interface{}) bool) {
+		key, tree, ok := cfg.subtree(prefix)
+		if !ok {
+			return
+		}
+		cfg.auditAccess("values", key, tree)
+		walkLeaves(key, tree, func(key string, value interface{}) bool {
+			if resolved, err := cfg.resolveLazy(value); err == nil {
+				value = resolved
+			}
+			return yield(key, value)
+		})
+	}
+}
+
+// AllKeys returns the sorted keys of all values of the effective configuration.
+func (cfg *configurer) AllKeys() []string {
+	return slices.Collect(cfg.Keys(""))
+}
+
+// Settings returns a copy of the effective configuration as a tree of
+// sections. Lazy secret references are resolved.
+func (cfg *configurer) Settings() map[string]interface{} {
+	_, tree, _ := cfg.subtree("")
+	cfg.auditAccess("settings", "", tree)
+	return cfg.resolveSection(tree)
+}
+
+// resolveSection returns a copy of the section with lazy secret references
+// resolved. Values which are not sections result in an empty section.
+func (cfg *configurer) resolveSection(value interface{}) map[string]interface{} {
+	if _, ok := value.(map[string]interface{}); !ok {
+		return map[string]interface{}{}
+	}
+	// references which fail to resolve are kept, like by Values
+	resolved, _ := cfg.resolveLazy(value)
+	return resolved.(map[string]interface{})
+}
+
+// subtree returns the canonical prefix and the value stored under it.
+func (cfg *configurer) subtree(prefix string) (string, interface{}, bool) {
+	cfg.mu.RLock()
+	settings := cfg.settings
+	cfg.mu.RUnlock()
+
+	key := cfg.canonicalKey(prefix)
+	if key == "" {
+		return "", settings, true
+	}
+	value, ok := searchPath(settings, key)
+	return key, value, ok
+}
+
+// walkLeaves calls fn for every non-map value below key, ordered by key, until fn returns false.
+func walkLeaves(key string, value interface{}, fn func(key string, value interface{}) bool) bool {
+	m, ok := value.(map[string]interface{})
+	if !ok || (len(m) == 0 && key != "") {
+		return fn(key, value)
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		path := k
+		if key != "" {
+			path = key + keyDelimiter + k
+		}
+		if !walkLeaves(path, m[k], fn) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,179 @@
+package configwise
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// journalEntry is a single line of the journal.
+type journalEntry struct {
+	Time      time.Time              `json:"time"`
+	Op        string                 `json:"op"`
+	Values    map[string]interface{} `json:"values"`
+	Actor     string                 `json:"actor,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+}
+
+// WithJournal appends every runtime mutation to the file before it is applied
+// and replays the journal on startup after all providers are loaded, so that
+// operational overrides deliberately survive restarts. Delete the file to drop them.
+// Secrets are journaled according to the secret policy.
+func WithJournal(path string) Option {
+	return func(c *configurer) {
+		c.journal = &journal{path: path}
+	}
+}
+
+type journal struct {
+	mu   sync.Mutex
+	path string
+}
+
+// append writes the entry and flushes it to disk.
+func (j *journal) append(entry journalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.Write(append(line, '\n')); err == nil {
+		err = f.Sync()
+	}
+	return errors.Join(err, f.Close())
+}
+
+// entries reads all entries of the journal. A missing file is an empty journal.
+// Entries are written with a trailing line break, so a last line without one
+// was torn by a crash while appending, before the mutation was applied. It is
+// removed from the file, so the next entry starts on a line of its own, and its
+// line number is returned. Lines which fail to parse otherwise are corruption.
+func (j *journal) entries() ([]journalEntry, int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	data, err := os.ReadFile(j.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, 0, nil
+		}
+		return nil, 0, err
+	}
+
+	torn := 0
+	if end := bytes.LastIndexByte(data, '\n') + 1; end < len(data) {
+		if len(bytes.TrimSpace(data[end:])) > 0 {
+			torn = bytes.Count(data[:end], []byte{'\n'}) + 1
+		}
+		if err = os.Truncate(j.path, int64(end)); err != nil {
+			return nil, 0, err
+		}
+		data = data[:end]
+	}
+
+	var entries []journalEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(scanner.Bytes()))
+		decoder.UseNumber()
+
+		var entry journalEntry
+		if err = decoder.Decode(&entry); err != nil {
+			return nil, 0, fmt.Errorf("%s:%d: %w", j.path, line, err)
+		}
+		entry.Values = fromJSONNumbers(entry.Values).(map[string]interface{})
+		entries = append(entries, entry)
+	}
+	return entries, torn, scanner.Err()
+}
+
+// fromJSONNumbers converts json.Number values to int64 or float64.
+func fromJSONNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, val := range v {
+			v[key] = fromJSONNumbers(val)
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = fromJSONNumbers(val)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// replayJournal applies the journaled mutations on top of the loaded settings.
+// Masked secrets keep their current values. A torn last entry is reported as
+// warning.
+func (cfg *configurer) replayJournal() error {
+	entries, torn, err := cfg.journal.entries()
+	if err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+	if torn > 0 {
+		cfg.warnings = append(cfg.warnings, Warning{
+			Kind: WarningTornJournalEntry,
+			Name: fmt.Sprintf("%s:%d", cfg.journal.path, torn),
+		})
+	}
+
+	for _, entry := range entries {
+		switch entry.Op {
+		case "overwrite":
+			values, err := cfg.coerceValues(restoreRedacted(cfg.settings, entry.Values, ""))
+			if err != nil {
+				return fmt.Errorf("journal: %w", err)
+			}
+			cfg.settings, cfg.overrides = applyOverrides(cfg.settings, cfg.overrides, values)
+		case "patch":
+			values, err := cfg.coerceValues(restoreRedacted(cfg.settings, entry.Values, ""))
+			if err != nil {
+				return fmt.Errorf("journal: %w", err)
+			}
+			cfg.overrides = patchOverrides(cfg.overrides, values)
+			cfg.settings = withOverrides(cfg.base, cfg.overrides)
+		case "rollback":
+			values, err := cfg.coerceValues(restoreRedacted(cfg.settings, entry.Values, ""))
+			if err != nil {
+				return fmt.Errorf("journal: %w", err)
+			}
+			cfg.overrides = patchOverrides(nil, values)
+			cfg.settings = withOverrides(cfg.base, cfg.overrides)
+		case "unset":
+			for key := range entry.Values {
+				cfg.overrides = unsetOverride(cfg.overrides, key)
+			}
+			cfg.settings = withOverrides(cfg.base, cfg.overrides)
+		default:
+			return fmt.Errorf("journal: unknown operation %q", entry.Op)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package configwise
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const journalEntries = `{"time":"2026-01-01T00:00:00Z","op":"overwrite","values":{"name":"first"}}
+{"time":"2026-01-01T00:00:01Z","op":"overwrite","values":{"port":8080}}
+`
+
+func TestJournalTornLastEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	torn := journalEntries + `{"time":"2026-01-01T00:00:02Z","op":"overwr`
+	if err := os.WriteFile(path, []byte(torn), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	open := func() Configurer {
+		c, err := NewConfigurer(WithType("yaml"), WithJournal(path), WithReadInConfig([]byte("name: base\nport: 80")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	c := open()
+	if got := c.GetString("name"); got != "first" {
+		t.Fatalf("name is %q, want %q", got, "first")
+	}
+	if got := c.GetInt("port"); got != 8080 {
+		t.Fatalf("port is %d, want 8080", got)
+	}
+	warnings := c.Warnings()
+	if len(warnings) != 1 || warnings[0].Kind != WarningTornJournalEntry || warnings[0].Name != path+":3" {
+		t.Fatalf("warnings are %v, want the torn entry at line 3", warnings)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != journalEntries {
+		t.Fatalf("journal is %q, want the torn entry removed", data)
+	}
+
+	if err = c.Overwrite(map[string]interface{}{"name": "second"}); err != nil {
+		t.Fatal(err)
+	}
+	c = open()
+	if got := c.GetString("name"); got != "second" {
+		t.Fatalf("name is %q after restart, want %q", got, "second")
+	}
+	if got := c.Warnings(); len(got) != 0 {
+		t.Fatalf("warnings are %v after restart, want none", got)
+	}
+}
+
+func TestJournalCorruptEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	corrupt := "{\"op\":\n" + journalEntries
+	if err := os.WriteFile(path, []byte(corrupt), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := NewConfigurer(WithType("yaml"), WithJournal(path), WithReadInConfig([]byte("name: base")))
+	if err == nil || !strings.Contains(err.Error(), path+":1") {
+		t.Fatalf("error is %v, want the corrupt entry at line 1", err)
+	}
+}
@@ -0,0 +1,34 @@
+// Package json5 decodes JSON5 config documents for configwise: JSON with
+// comments, trailing commas, unquoted keys and single quoted strings. It is
+// a separate package so that only applications reading JSON5 link the JSON5
+// library:
+//
+//	c, err := configwise.NewConfigurer(json5.Option())
+package json5
+
+import (
+	"bytes"
+
+	"github.com/titanous/json5"
+
+	"github.com/gowool/configwise"
+)
+
+// Option registers Decode for config documents of type json5, detected by
+// the .json5 extension.
+func Option() configwise.Option {
+	return configwise.WithDecoder("json5", Decode)
+}
+
+// Decode parses a JSON5 document into a configuration tree. An empty
+// document is an empty tree.
+func Decode(data []byte) (map[string]interface{}, error) {
+	tree := make(map[string]interface{})
+	if len(bytes.TrimSpace(data)) == 0 {
+		return tree, nil
+	}
+	if err := json5.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
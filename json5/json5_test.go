@@ -0,0 +1,149 @@
+package json5
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gowool/configwise"
+)
+
+type decodeTest struct {
+	name string
+	doc  string
+	want map[string]interface{}
+	err  string
+}
+
+func runDecodeTests(t *testing.T, tests []decodeTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode([]byte(tt.doc))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error is %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tree is %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyntax(t *testing.T) {
+	runDecodeTests(t, []decodeTest{
+		{
+			name: "unquoted keys",
+			doc:  `{name: "app", $ref: 1, _id: 2}`,
+			want: map[string]interface{}{"name": "app", "$ref": float64(1), "_id": float64(2)},
+		},
+		{
+			name: "single quoted strings",
+			doc:  `{greeting: 'say "hi"', 'quoted key': 'it\'s'}`,
+			want: map[string]interface{}{"greeting": `say "hi"`, "quoted key": "it's"},
+		},
+		{
+			name: "trailing commas",
+			doc:  `{hosts: ["a", "b",], port: 80,}`,
+			want: map[string]interface{}{"hosts": []interface{}{"a", "b"}, "port": float64(80)},
+		},
+		{
+			name: "numbers",
+			doc:  `{hex: 0xFF, leading: .5, trailing: 5., positive: +1}`,
+			want: map[string]interface{}{"hex": float64(255), "leading": 0.5, "trailing": float64(5), "positive": float64(1)},
+		},
+		{
+			name: "multi-line strings",
+			doc:  "{motd: 'hello \\\nworld'}",
+			want: map[string]interface{}{"motd": "hello world"},
+		},
+		{
+			name: "json",
+			doc:  `{"a": {"b": true, "c": null}}`,
+			want: map[string]interface{}{"a": map[string]interface{}{"b": true, "c": nil}},
+		},
+		{
+			name: "empty",
+			doc:  " \n",
+			want: map[string]interface{}{},
+		},
+		{
+			name: "missing value",
+			doc:  `{a: }`,
+			err:  "invalid character '}'",
+		},
+		{
+			name: "not an object",
+			doc:  `[1, 2]`,
+			err:  "cannot unmarshal array",
+		},
+	})
+}
+
+func TestComments(t *testing.T) {
+	runDecodeTests(t, []decodeTest{
+		{
+			name: "line comments",
+			doc:  "// header\n{\n  port: 80, // inline\n}\n// footer",
+			want: map[string]interface{}{"port": float64(80)},
+		},
+		{
+			name: "block comments",
+			doc:  "{/* before */ port /* between */: /* value */ 80 /* after */}",
+			want: map[string]interface{}{"port": float64(80)},
+		},
+		{
+			name: "comment markers in strings",
+			doc:  `{url: "https://example.com", glob: '/* all */'}`,
+			want: map[string]interface{}{"url": "https://example.com", "glob": "/* all */"},
+		},
+		{
+			name: "commented out brace",
+			doc:  "{port: 80 // }",
+			err:  "unexpected end of JSON input",
+		},
+	})
+}
+
+func TestKeyCase(t *testing.T) {
+	doc := []byte("{Server: {HTTPPort: 8080, 'TLS-Enabled': true}}")
+
+	tree, err := Decode(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tree["Server"]; !ok {
+		t.Fatalf("tree is %#v, want the keys as written", tree)
+	}
+
+	if _, err := configwise.NewConfigurer(configwise.WithType("json5"), configwise.WithReadInConfig(doc)); err == nil {
+		t.Fatal("JSON5 is decoded without the decoder")
+	}
+	c, err := configwise.NewConfigurer(Option(), configwise.WithType("json5"), configwise.WithReadInConfig(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key  string
+		want interface{}
+	}{
+		{key: "server.httpport", want: float64(8080)},
+		{key: "Server.HTTPPort", want: float64(8080)},
+		{key: "SERVER.HTTPPORT", want: float64(8080)},
+		{key: "server.tls-enabled", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := c.Get(tt.key); got != tt.want {
+				t.Errorf("%s is %#v, want %#v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
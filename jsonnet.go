@@ -0,0 +1,75 @@
+package configwise
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrJsonnetVMMissing is returned for Jsonnet documents when no VM is configured.
+var ErrJsonnetVMMissing = errors.New("document is Jsonnet, but no jsonnet vm is configured")
+
+// JsonnetVM evaluates Jsonnet documents into JSON, like the VM of the jsonnet
+// subpackage, which keeps a Jsonnet implementation out of applications not
+// using Jsonnet:
+//
+//	c, err := configwise.NewConfigurer(jsonnet.Option())
+//
+// Imports are relative to the file, which is empty for documents which are
+// not read from a file. The external variables are read by std.extVar.
+type JsonnetVM interface {
+	Evaluate(file string, source []byte, extVars map[string]string) (string, error)
+}
+
+// JsonnetVMFunc is an adapter to allow the use of ordinary functions as JsonnetVM.
+type JsonnetVMFunc func(file string, source []byte, extVars map[string]string) (string, error)
+
+func (f JsonnetVMFunc) Evaluate(file string, source []byte, extVars map[string]string) (string, error) {
+	return f(file, source, extVars)
+}
+
+// WithJsonnet hooks the VM into load and reload to evaluate config documents
+// of type "jsonnet", e.g. config.jsonnet, which fail with ErrJsonnetVMMissing
+// otherwise. The environment variables are passed as external variables, e.g.
+// std.extVar('HOME'), so the config can be programmed without a separate
+// render step.
+func WithJsonnet(vm JsonnetVM) Option {
+	return func(c *configurer) {
+		c.jsonnet = vm
+	}
+}
+
+// isJsonnet reports whether documents of the config type are Jsonnet.
+func isJsonnet(configType string) bool {
+	return configType == "jsonnet"
+}
+
+// evaluateJsonnet evaluates the Jsonnet document into a configuration tree.
+func (cfg *configurer) evaluateJsonnet(file string, data []byte) (map[string]interface{}, error) {
+	if cfg.jsonnet == nil {
+		return nil, ErrJsonnetVMMissing
+	}
+
+	extVars := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if name, value, ok := strings.Cut(kv, "="); ok && name != "" {
+			extVars[name] = value
+		}
+	}
+
+	out, err := cfg.jsonnet.Evaluate(file, data, extVars)
+	if err != nil {
+		return nil, fmt.Errorf("jsonnet: %w", err)
+	}
+
+	var tree map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &tree); err != nil {
+		return nil, fmt.Errorf("jsonnet: document must evaluate to an object: %w", err)
+	}
+	if tree == nil {
+		tree = make(map[string]interface{})
+	}
+	return tree, nil
+}
@@ -0,0 +1,54 @@
+// Package jsonnet evaluates Jsonnet config documents for configwise with
+// go-jsonnet. It is a separate package so that only applications reading
+// Jsonnet link the Jsonnet interpreter:
+//
+//	c, err := configwise.NewConfigurer(jsonnet.Option())
+package jsonnet
+
+import (
+	"path/filepath"
+
+	"github.com/google/go-jsonnet"
+
+	"github.com/gowool/configwise"
+)
+
+// Option evaluates config documents of type jsonnet, e.g. config.jsonnet,
+// with a VM of NewVM.
+func Option(libraryPaths ...string) configwise.Option {
+	return configwise.WithJsonnet(NewVM(libraryPaths...))
+}
+
+// VM evaluates Jsonnet documents as configwise.JsonnetVM.
+type VM struct {
+	libraryPaths []string
+}
+
+var _ configwise.JsonnetVM = (*VM)(nil)
+
+// NewVM returns a VM which resolves imports relative to the importing file
+// and then in the library paths, the right-most first like the -J flags of
+// the jsonnet command.
+func NewVM(libraryPaths ...string) *VM {
+	return &VM{libraryPaths: libraryPaths}
+}
+
+// Evaluate evaluates the document into JSON. The external variables are
+// read by std.extVar. Every evaluation uses a VM of its own, so imported
+// files are read again on reload.
+func (v *VM) Evaluate(file string, source []byte, extVars map[string]string) (string, error) {
+	// the snippet is not imported from the file, so the directory of the
+	// file is searched first, as the right-most path, like for the imports
+	// of a file
+	paths := v.libraryPaths
+	if file != "" {
+		paths = append(paths[:len(paths):len(paths)], filepath.Dir(file))
+	}
+
+	vm := jsonnet.MakeVM()
+	vm.Importer(&jsonnet.FileImporter{JPaths: paths})
+	for name, value := range extVars {
+		vm.ExtVar(name, value)
+	}
+	return vm.EvaluateAnonymousSnippet(file, string(source))
+}
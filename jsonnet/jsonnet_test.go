@@ -0,0 +1,113 @@
+package jsonnet
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gowool/configwise"
+)
+
+func TestEvaluate(t *testing.T) {
+	dir := t.TempDir()
+	lib := t.TempDir()
+	files := map[string]string{
+		filepath.Join(dir, "base.libsonnet"):   `{ port: 8080 }`,
+		filepath.Join(lib, "shared.libsonnet"): `{ region: 'eu' }`,
+		// shadowed by the file next to the config
+		filepath.Join(lib, "base.libsonnet"): `{ port: 80 }`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		source  string
+		extVars map[string]string
+		want    string
+		err     string
+	}{
+		{
+			name:   "expressions",
+			source: `{ local base = 8000, port: base + 80, hosts: [h for h in ['a', 'b']] }`,
+			want:   `{"hosts":["a","b"],"port":8080}`,
+		},
+		{
+			name:    "external variables",
+			source:  `{ home: std.extVar('HOME') }`,
+			extVars: map[string]string{"HOME": "/home/app"},
+			want:    `{"home":"/home/app"}`,
+		},
+		{
+			name:   "relative import",
+			file:   filepath.Join(dir, "config.jsonnet"),
+			source: `(import 'base.libsonnet') + { debug: true }`,
+			want:   `{"debug":true,"port":8080}`,
+		},
+		{
+			name:   "library import",
+			file:   filepath.Join(dir, "config.jsonnet"),
+			source: `import 'shared.libsonnet'`,
+			want:   `{"region":"eu"}`,
+		},
+		{
+			name:   "undefined external variable",
+			source: `{ home: std.extVar('HOME') }`,
+			err:    "Undefined external variable: HOME",
+		},
+		{
+			name:   "missing import",
+			file:   filepath.Join(dir, "config.jsonnet"),
+			source: `import 'missing.libsonnet'`,
+			err:    "couldn't open import \"missing.libsonnet\"",
+		},
+	}
+	vm := NewVM(lib)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := vm.Evaluate(tt.file, []byte(tt.source), tt.extVars)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error is %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if compact := strings.Join(strings.Fields(got), ""); compact != tt.want {
+				t.Errorf("JSON is %s, want %s", compact, tt.want)
+			}
+		})
+	}
+}
+
+func TestOption(t *testing.T) {
+	t.Setenv("CONFIGWISE_TEST_REGION", "eu")
+
+	dir := t.TempDir()
+	config := `{ server: { port: 8000 + 80, region: std.extVar('CONFIGWISE_TEST_REGION') } }`
+	if err := os.WriteFile(filepath.Join(dir, "config.jsonnet"), []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := configwise.NewConfigurer(configwise.WithPath(dir), configwise.WithName("config.jsonnet")); err == nil || !strings.Contains(err.Error(), "no jsonnet vm is configured") {
+		t.Fatalf("error is %v, want a missing VM", err)
+	}
+
+	c, err := configwise.NewConfigurer(Option(), configwise.WithPath(dir), configwise.WithName("config.jsonnet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.GetInt("server.port"); got != 8080 {
+		t.Errorf("server.port is %d, want 8080", got)
+	}
+	if got := c.GetString("server.region"); got != "eu" {
+		t.Errorf("server.region is %q, want eu", got)
+	}
+}
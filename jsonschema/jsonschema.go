@@ -0,0 +1,222 @@
+// Package jsonschema validates configwise config trees against JSON
+// Schemas. It is a separate package so that only applications validating
+// JSON Schemas link the JSON Schema library:
+//
+//	c, err := configwise.NewConfigurer(jsonschema.WithJSONSchema(data))
+//
+// Compile returns the schema for use with configwise.WithSchemaValidator.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	"github.com/gowool/configwise"
+)
+
+// Schema is a compiled JSON Schema, which validates config trees as
+// configwise.SchemaValidator.
+type Schema struct {
+	schema *jsonschema.Schema
+}
+
+var _ configwise.SchemaValidator = (*Schema)(nil)
+
+// WithJSONSchema validates the config tree against the JSON Schema like
+// configwise.WithSchemaValidator. A schema which does not compile fails
+// NewConfigurer.
+func WithJSONSchema(schema []byte) configwise.Option {
+	compiled, err := Compile(schema)
+	if err != nil {
+		return configwise.WithOptionError(err)
+	}
+	return configwise.WithSchemaValidator(compiled)
+}
+
+// Compile compiles the JSON Schema.
+func Compile(schema []byte) (*Schema, error) {
+	compiled, err := jsonschema.CompileString("config.schema.json", string(schema))
+	if err != nil {
+		return nil, fmt.Errorf("json schema: %w", err)
+	}
+	return &Schema{schema: compiled}, nil
+}
+
+// Validate validates the config tree against the schema. Strings are
+// validated as the integers, numbers or booleans the schema expects if they
+// can be converted, like the decoder does, as the values of environment
+// variables and flags are always strings. Violations are reported with JSON
+// pointers of the affected values, e.g.
+// "/http/port: expected integer, but got string".
+func (s *Schema) Validate(tree map[string]interface{}) error {
+	// the schema validates JSON values, so numbers of all Go types and
+	// values like time.Time are converted to their JSON form first
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return fmt.Errorf("json schema: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var instance interface{}
+	if err = decoder.Decode(&instance); err != nil {
+		return fmt.Errorf("json schema: %w", err)
+	}
+
+	err = s.schema.Validate(coerceStrings([]*jsonschema.Schema{s.schema}, instance))
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return err
+	}
+
+	var errs []error
+	var leaves func(ve *jsonschema.ValidationError)
+	leaves = func(ve *jsonschema.ValidationError) {
+		if len(ve.Causes) == 0 {
+			location := ve.InstanceLocation
+			if location == "" {
+				location = "/"
+			}
+			errs = append(errs, fmt.Errorf("%s: %s", location, ve.Message))
+			return
+		}
+		for _, cause := range ve.Causes {
+			leaves(cause)
+		}
+	}
+	leaves(ve)
+	return fmt.Errorf("json schema: %w", errors.Join(errs...))
+}
+
+// coerceStrings converts the strings of the instance to the type the schemas
+// applying to them expect, unless they accept strings or the conversion fails.
+func coerceStrings(schemas []*jsonschema.Schema, value interface{}) interface{} {
+	schemas = expandSchemas(schemas)
+	if len(schemas) == 0 {
+		return value
+	}
+
+	switch v := value.(type) {
+	case string:
+		return coerceString(schemas, v)
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[key] = coerceStrings(propertySchemas(schemas, key), val)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = coerceStrings(itemSchemas(schemas, i), val)
+		}
+		return s
+	}
+	return value
+}
+
+// coerceString converts the string to the first of integer, number and
+// boolean the schemas expect.
+func coerceString(schemas []*jsonschema.Schema, s string) interface{} {
+	var types []string
+	for _, schema := range schemas {
+		types = append(types, schema.Types...)
+	}
+	if slices.Contains(types, "string") {
+		return s
+	}
+	if slices.Contains(types, "integer") {
+		if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return json.Number(s)
+		}
+	}
+	if slices.Contains(types, "number") {
+		if _, err := strconv.ParseFloat(s, 64); err == nil {
+			return json.Number(s)
+		}
+	}
+	if slices.Contains(types, "boolean") {
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	}
+	return s
+}
+
+// expandSchemas returns the schemas with the schemas they refer to or are
+// composed of.
+func expandSchemas(schemas []*jsonschema.Schema) []*jsonschema.Schema {
+	var expanded []*jsonschema.Schema
+	seen := make(map[*jsonschema.Schema]bool)
+	var add func(schema *jsonschema.Schema)
+	add = func(schema *jsonschema.Schema) {
+		if schema == nil || seen[schema] {
+			return
+		}
+		seen[schema] = true
+		expanded = append(expanded, schema)
+		add(schema.Ref)
+		add(schema.RecursiveRef)
+		add(schema.DynamicRef)
+		add(schema.Then)
+		add(schema.Else)
+		for _, list := range [][]*jsonschema.Schema{schema.AllOf, schema.AnyOf, schema.OneOf} {
+			for _, s := range list {
+				add(s)
+			}
+		}
+	}
+	for _, schema := range schemas {
+		add(schema)
+	}
+	return expanded
+}
+
+// propertySchemas returns the schemas of the property of objects.
+func propertySchemas(schemas []*jsonschema.Schema, key string) []*jsonschema.Schema {
+	var out []*jsonschema.Schema
+	for _, schema := range schemas {
+		matched := false
+		if s, ok := schema.Properties[key]; ok {
+			out, matched = append(out, s), true
+		}
+		for re, s := range schema.PatternProperties {
+			if re.MatchString(key) {
+				out, matched = append(out, s), true
+			}
+		}
+		if s, ok := schema.AdditionalProperties.(*jsonschema.Schema); ok && !matched {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// itemSchemas returns the schemas of the item of arrays at the index.
+func itemSchemas(schemas []*jsonschema.Schema, index int) []*jsonschema.Schema {
+	var out []*jsonschema.Schema
+	for _, schema := range schemas {
+		switch items := schema.Items.(type) {
+		case *jsonschema.Schema:
+			out = append(out, items)
+		case []*jsonschema.Schema:
+			if index < len(items) {
+				out = append(out, items[index])
+			} else if s, ok := schema.AdditionalItems.(*jsonschema.Schema); ok {
+				out = append(out, s)
+			}
+		}
+		switch {
+		case index < len(schema.PrefixItems):
+			out = append(out, schema.PrefixItems[index])
+		case schema.Items2020 != nil:
+			out = append(out, schema.Items2020)
+		}
+	}
+	return out
+}
@@ -0,0 +1,140 @@
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gowool/configwise"
+)
+
+const portSchema = `{
+	"type": "object",
+	"properties": {
+		"http": {
+			"type": "object",
+			"properties": {
+				"port": {"type": "integer", "maximum": 65535},
+				"tls": {"type": "boolean"},
+				"host": {"type": "string"}
+			}
+		}
+	}
+}`
+
+func compile(t *testing.T) *Schema {
+	t.Helper()
+	schema, err := Compile([]byte(portSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+func TestJSONSchemaCoercesStrings(t *testing.T) {
+	t.Setenv("APP_HTTP_PORT", "8080")
+	t.Setenv("APP_HTTP_HOST", "0")
+
+	tests := []struct {
+		name    string
+		options []configwise.Option
+		port    int
+	}{
+		{name: "env", options: []configwise.Option{configwise.WithPrefix("APP")}, port: 8080},
+		{name: "flags", options: []configwise.Option{configwise.WithFlags([]string{"http.port=9090", "http.tls=true"})}, port: 9090},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]configwise.Option{
+				configwise.WithType("yaml"),
+				configwise.WithSchemaValidator(compile(t)),
+				configwise.WithReadInConfig([]byte("http: {port: 80, tls: false, host: localhost}")),
+			}, tt.options...)
+			c, err := configwise.NewConfigurer(options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.GetInt("http.port"); got != tt.port {
+				t.Fatalf("port is %d, want %d", got, tt.port)
+			}
+		})
+	}
+}
+
+func TestJSONSchemaRejectsInvalidStrings(t *testing.T) {
+	tests := []struct {
+		name  string
+		flag  string
+		error string
+	}{
+		{name: "not a number", flag: "http.port=http", error: "/http/port: expected integer, but got string"},
+		{name: "out of range", flag: "http.port=70000", error: "/http/port: must be <= 65535"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := configwise.NewConfigurer(
+				configwise.WithType("yaml"),
+				configwise.WithSchemaValidator(compile(t)),
+				configwise.WithReadInConfig([]byte("http: {port: 80}")),
+				configwise.WithFlags([]string{tt.flag}),
+			)
+			if err == nil || !strings.Contains(err.Error(), tt.error) {
+				t.Fatalf("error is %v, want %q", err, tt.error)
+			}
+		})
+	}
+}
+
+func TestJSONSchemaRejectsOverwrite(t *testing.T) {
+	c, err := configwise.NewConfigurer(
+		configwise.WithType("yaml"),
+		configwise.WithSchemaValidator(compile(t)),
+		configwise.WithReadInConfig([]byte("http: {port: 80}")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Overwrite(map[string]interface{}{"http.tls": "maybe"})
+	if err == nil || !strings.Contains(err.Error(), "/http/tls: expected boolean, but got string") {
+		t.Fatalf("error is %v, want the invalid boolean", err)
+	}
+	if c.Get("http.tls") != nil {
+		t.Errorf("http.tls is %v, want the overwrite rejected", c.Get("http.tls"))
+	}
+}
+
+func TestCompile(t *testing.T) {
+	if _, err := Compile([]byte(`{"type": 1}`)); err == nil || !strings.HasPrefix(err.Error(), "json schema: ") {
+		t.Fatalf("error is %v, want an invalid schema", err)
+	}
+}
+
+func TestWithJSONSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		err    string
+	}{
+		{name: "valid config", schema: portSchema},
+		{name: "invalid config", schema: `{"properties": {"http": {"properties": {"port": {"maximum": 1}}}}}`, err: "/http/port: must be <= 1"},
+		{name: "invalid schema", schema: `{"type": 1}`, err: "new -> json schema: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := configwise.NewConfigurer(
+				configwise.WithType("yaml"),
+				WithJSONSchema([]byte(tt.schema)),
+				configwise.WithReadInConfig([]byte("http: {port: 80}")),
+			)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error is %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package configwise
+
+import (
+	"reflect"
+	"sync"
+)
+
+// WithUnmarshalCache caches the results of UnmarshalKey per key and target
+// type until the settings change, for hot paths which unmarshal a section on
+// every request. A cached result is copied into the target, replacing values
+// set before the call; maps, slices and pointers within it are shared by all
+// callers and must not be modified. Defaults and Validate methods are only
+// called when the section is decoded. With WithLazyExpansion and lazy value
+// schemes, cached results keep the values resolved when they were decoded.
+func WithUnmarshalCache() Option {
+	return func(c *configurer) {
+		c.unmarshalCache = true
+	}
+}
+
+// memo holds the results of UnmarshalKey of one generation.
+type memo struct {
+	mu         sync.Mutex
+	generation uint64
+	entries    map[memoKey]memoEntry
+}
+
+type memoKey struct {
+	key string
+	typ reflect.Type
+}
+
+type memoEntry struct {
+	value reflect.Value
+	// keys the decoder did not use, to record the usage on hits
+	unused []string
+}
+
+// loadDecoded copies the cached result of the key decoded in the generation
+// into out and reports whether there was one.
+func (cfg *configurer) loadDecoded(key string, generation uint64, out interface{}) bool {
+	target := reflect.ValueOf(out)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return false
+	}
+
+	c := &cfg.decoded
+	c.mu.Lock()
+	entry, ok := c.entries[memoKey{key: key, typ: target.Type()}]
+	ok = ok && c.generation == generation
+	c.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	target.Elem().Set(entry.value)
+	cfg.markUsed(key, entry.unused)
+	return true
+}
+
+// storeDecoded caches a copy of the result of the key decoded in the
+// generation. Results of older generations are dropped. The generation must
+// have been read before the settings the result was decoded from, so that a
+// result is never cached for a newer generation than its settings.
+func (cfg *configurer) storeDecoded(key string, generation uint64, out interface{}, unused []string) {
+	target := reflect.ValueOf(out)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return
+	}
+	value := reflect.New(target.Type().Elem()).Elem()
+	value.Set(target.Elem())
+
+	c := &cfg.decoded
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch {
+	case generation < c.generation:
+		return
+	case generation > c.generation || c.entries == nil:
+		c.generation = generation
+		c.entries = make(map[memoKey]memoEntry)
+	}
+	c.entries[memoKey{key: key, typ: target.Type()}] = memoEntry{value: value, unused: unused}
+}
@@ -0,0 +1,68 @@
+package configwise
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+var memoDecodes atomic.Int32
+
+type memoServer struct {
+	Host string
+	Port int
+}
+
+func (s *memoServer) Defaults() {
+	memoDecodes.Add(1)
+}
+
+func TestUnmarshalCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []Option
+		decodes int32
+	}{
+		{name: "cached", options: []Option{WithUnmarshalCache()}, decodes: 2},
+		{name: "uncached", decodes: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memoDecodes.Store(0)
+			options := append([]Option{WithConfigMap(map[string]interface{}{
+				"server": map[string]interface{}{"host": "localhost", "port": 80},
+			})}, tt.options...)
+			c, err := NewConfigurer(options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var server memoServer
+			for range 2 {
+				server = memoServer{Host: "stale"}
+				if err := c.UnmarshalKey("server", &server); err != nil {
+					t.Fatal(err)
+				}
+				if server != (memoServer{Host: "localhost", Port: 80}) {
+					t.Errorf("server is %+v", server)
+				}
+			}
+
+			// changes of the settings invalidate the cache
+			if err := c.Overwrite(map[string]interface{}{"server.port": 8080}); err != nil {
+				t.Fatal(err)
+			}
+			for range 2 {
+				if err := c.UnmarshalKey("server", &server); err != nil {
+					t.Fatal(err)
+				}
+				if server.Port != 8080 {
+					t.Errorf("port is %d after an overwrite, want 8080", server.Port)
+				}
+			}
+
+			if got := memoDecodes.Load(); got != tt.decodes {
+				t.Errorf("section decoded %d times, want %d", got, tt.decodes)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package configwise
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultLanguage is the language used when no translation of a message exists.
+const DefaultLanguage = "en"
+
+// MessageID identifies a localizable message of the catalog.
+type MessageID string
+
+const (
+	MsgInvalidFlag    MessageID = "invalid_flag"
+	MsgFlagUsage      MessageID = "flag_usage"
+	MsgEmptyFlagKey   MessageID = "empty_flag_key"
+	MsgEmptyFlagValue MessageID = "empty_flag_value"
+
+	// messages of errors of Unmarshal and UnmarshalKey
+	MsgRequired            MessageID = "required"
+	MsgEnum                MessageID = "enum"
+	MsgUnknownKey          MessageID = "unknown_key"
+	MsgValidationRequired  MessageID = "validation_required"
+	MsgValidationMin       MessageID = "validation_min"
+	MsgValidationMax       MessageID = "validation_max"
+	MsgValidationGreater   MessageID = "validation_greater"
+	MsgValidationLess      MessageID = "validation_less"
+	MsgValidationLen       MessageID = "validation_len"
+	MsgValidationOneOf     MessageID = "validation_oneof"
+	MsgValidationRule      MessageID = "validation_rule"
+	MsgValidationRuleParam MessageID = "validation_rule_param"
+
+	// messages of warnings
+	MsgUndefinedVariable   MessageID = "undefined_variable"
+	MsgUnresolvedReference MessageID = "unresolved_reference"
+	MsgUnexpanded          MessageID = "unexpanded"
+	MsgDecodeFallback      MessageID = "decode_fallback"
+	MsgDeprecatedKey       MessageID = "deprecated_key"
+	MsgDuplicateKey        MessageID = "duplicate_key"
+	MsgTornJournalEntry    MessageID = "torn_journal_entry"
+	MsgUnresolvedValue     MessageID = "unresolved_value"
+)
+
+var (
+	catalogMu sync.RWMutex
+	catalog   = map[string]map[MessageID]string{
+		DefaultLanguage: {
+			MsgInvalidFlag:    "invalid flag `%s`",
+			MsgFlagUsage:      "usage: -o key=value",
+			MsgEmptyFlagKey:   "key should not be empty",
+			MsgEmptyFlagValue: "value should not be empty",
+
+			MsgRequired:            "required field is not set",
+			MsgEnum:                "value is not allowed: %q, accepted: %s",
+			MsgUnknownKey:          "unknown key",
+			MsgValidationRequired:  "is required",
+			MsgValidationMin:       "must be >= %s",
+			MsgValidationMax:       "must be <= %s",
+			MsgValidationGreater:   "must be > %s",
+			MsgValidationLess:      "must be < %s",
+			MsgValidationLen:       "must have length %s",
+			MsgValidationOneOf:     "must be one of [%s]",
+			MsgValidationRule:      "must satisfy %s",
+			MsgValidationRuleParam: "must satisfy %s=%s",
+
+			MsgUndefinedVariable:   "%s: undefined variable %s",
+			MsgUnresolvedReference: "%s: unresolved reference %s",
+			MsgUnexpanded:          "%s: unexpanded value",
+			MsgDecodeFallback:      "%s: %s hook failed, value ignored",
+			MsgDeprecatedKey:       "%s: deprecated, use %s",
+			MsgDuplicateKey:        "%s: duplicate key, definition at %s ignored",
+			MsgTornJournalEntry:    "%s: incomplete journal entry dropped",
+			MsgUnresolvedValue:     "%s: value not resolved: %s",
+		},
+	}
+)
+
+// RegisterMessages adds translations of the given language to the catalog.
+// Messages are fmt format strings receiving the same arguments as the English
+// originals. Missing messages fall back to English.
+func RegisterMessages(lang string, messages map[MessageID]string) {
+	lang = normalizeLanguage(lang)
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	if catalog[lang] == nil {
+		catalog[lang] = make(map[MessageID]string, len(messages))
+	}
+	for id, msg := range messages {
+		catalog[lang][id] = msg
+	}
+}
+
+// WithErrorLanguage selects the language (a BCP 47 tag such as "de" or "pt-BR")
+// of the errors of flags, of the required, enum, strict and validate checks of
+// Unmarshal and UnmarshalKey, and of loads failing with WithFailOnWarnings.
+// Warning.Message translates warnings. Errors of schema validators, of
+// decoding and of Validate methods are not translated.
+func WithErrorLanguage(tag string) Option {
+	return func(c *configurer) {
+		c.lang = normalizeLanguage(tag)
+	}
+}
+
+func normalizeLanguage(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(tag, "_", "-"))
+}
+
+// translate formats the message in the given language, trying the
+// language itself, its base language and English in that order.
+func translate(lang string, id MessageID, args ...interface{}) string {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	for _, candidate := range languageFallbacks(lang) {
+		if msg, ok := catalog[candidate][id]; ok {
+			return fmt.Sprintf(msg, args...)
+		}
+	}
+	return string(id)
+}
+
+func languageFallbacks(lang string) []string {
+	fallbacks := make([]string, 0, 3)
+	for lang != "" {
+		fallbacks = append(fallbacks, lang)
+		idx := strings.LastIndex(lang, "-")
+		if idx == -1 {
+			break
+		}
+		lang = lang[:idx]
+	}
+	return append(fallbacks, DefaultLanguage)
+}
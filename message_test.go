@@ -0,0 +1,74 @@
+package configwise
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type languageApp struct {
+	Name    string `cfg:"name,required"`
+	Level   string `cfg:"level" enum:"debug,info"`
+	Workers int    `cfg:"workers" validate:"min=1"`
+}
+
+func TestErrorLanguage(t *testing.T) {
+	RegisterMessages("x-test", map[MessageID]string{
+		MsgRequired:      "pflichtfeld fehlt",
+		MsgEnum:          "%q nicht erlaubt, erlaubt: %s",
+		MsgUnknownKey:    "unbekannter schlüssel",
+		MsgValidationMin: "muss >= %s sein",
+	})
+
+	tests := []struct {
+		name   string
+		config string
+		target error
+		want   string
+	}{
+		{name: "required", config: "app: {workers: 1}", target: ErrRequired, want: "app.name: pflichtfeld fehlt"},
+		{name: "enum", config: "app: {name: api, level: trace, workers: 1}", target: ErrEnum, want: `app.level: "trace" nicht erlaubt, erlaubt: debug, info`},
+		{name: "validate", config: "app: {name: api, workers: 0}", want: "app.workers: muss >= 1 sein"},
+		{name: "strict", config: "app: {name: api, workers: 1, extra: 1}", target: ErrUnknownKey, want: "app.extra: unbekannter schlüssel"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewConfigurer(
+				WithType("yaml"),
+				WithErrorLanguage("x-test"),
+				WithStrict(),
+				WithValidation(),
+				WithReadInConfig([]byte(tt.config)),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var app languageApp
+			err = c.UnmarshalKey("app", &app)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error %v does not contain %q", err, tt.want)
+			}
+			if tt.target != nil && !errors.Is(err, tt.target) {
+				t.Fatalf("error %v does not match %v", err, tt.target)
+			}
+		})
+	}
+}
+
+func TestWarningMessage(t *testing.T) {
+	RegisterMessages("x-test", map[MessageID]string{
+		MsgUndefinedVariable: "%s: variable %s fehlt",
+	})
+
+	w := Warning{Kind: WarningUndefinedVariable, Key: "db.host", Name: "DB_HOST"}
+	if got, want := w.Message("x-test"), "db.host: variable DB_HOST fehlt"; got != want {
+		t.Errorf("message is %q, want %q", got, want)
+	}
+	if got, want := w.Message("fr"), "db.host: undefined variable DB_HOST"; got != want {
+		t.Errorf("fallback message is %q, want %q", got, want)
+	}
+	if got, want := w.String(), w.Message(DefaultLanguage); got != want {
+		t.Errorf("string is %q, want %q", got, want)
+	}
+}
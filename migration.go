@@ -0,0 +1,125 @@
+package configwise
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// VersionKey is the default config key holding the version of the config
+// schema a document is written for, e.g. version: 2.
+const VersionKey = "version"
+
+// ErrUnsupportedConfigVersion is returned for documents of a version newer than
+// the registered migrations lead to, or with a version which is not an integer.
+var ErrUnsupportedConfigVersion = errors.New("unsupported config version")
+
+// Migration upgrades the settings of a document to the next version. It
+// may modify and return the settings or return new ones.
+type Migration func(settings map[string]interface{}) (map[string]interface{}, error)
+
+type migration struct {
+	to int
+	fn Migration
+}
+
+// WithMigration registers the upgrade of documents from one version to
+// another. Config files and remote documents declaring a version are
+// upgraded in-memory at load time by the chain of migrations starting at
+// their version, so old config files keep working after the schema evolved.
+// Documents without version are taken as current; defaults, environment
+// variables and flags are never migrated. NewConfigurer fails when a
+// migration from the version is registered twice or does not upgrade.
+func WithMigration(from, to int, fn Migration) Option {
+	return func(c *configurer) {
+		name := fmt.Sprintf("migration %d -> %d", from, to)
+		switch _, ok := c.migrations[from]; {
+		case fn == nil:
+			c.optionErrs = append(c.optionErrs, fmt.Errorf("%s: nil migration", name))
+		case to <= from:
+			c.optionErrs = append(c.optionErrs, fmt.Errorf("%s: target version must be greater", name))
+		case ok:
+			c.optionErrs = append(c.optionErrs, fmt.Errorf("%s: already registered", name))
+		default:
+			if c.migrations == nil {
+				c.migrations = make(map[int]migration)
+			}
+			c.migrations[from] = migration{to: to, fn: fn}
+		}
+	}
+}
+
+// WithVersionKey sets the key of the version migrations start at, e.g.
+// "schema_version" or "meta.version", VersionKey by default.
+func WithVersionKey(key string) Option {
+	return func(c *configurer) {
+		c.versionKey = strings.ToLower(key)
+	}
+}
+
+// migrate upgrades the tree of a document of the provider to the latest version.
+func (cfg *configurer) migrate(p Provider, tree map[string]interface{}) (map[string]interface{}, error) {
+	if len(cfg.migrations) == 0 {
+		return tree, nil
+	}
+	if source := sourceOf(p); source != SourceFile && source != SourceRemote {
+		return tree, nil
+	}
+
+	key := cfg.versionKey
+	if key == "" {
+		key = VersionKey
+	}
+	value, ok := searchPath(tree, key)
+	if !ok {
+		return tree, nil
+	}
+	version, err := parseVersion(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+	if latest := latestVersion(cfg.migrations); version > latest {
+		return nil, fmt.Errorf("%w %d, latest is %d", ErrUnsupportedConfigVersion, version, latest)
+	}
+
+	for m, ok := cfg.migrations[version]; ok; m, ok = cfg.migrations[version] {
+		migrated, err := m.fn(deepCopy(tree).(map[string]interface{}))
+		if err != nil {
+			return nil, fmt.Errorf("migrate version %d to %d: %w", version, m.to, err)
+		}
+		tree, version = normalizeTree(migrated), m.to
+		setPath(tree, key, version)
+	}
+	return tree, nil
+}
+
+// latestVersion returns the highest version the migrations lead to.
+func latestVersion(migrations map[int]migration) int {
+	latest := math.MinInt
+	for from, m := range migrations {
+		latest = max(latest, from, m.to)
+	}
+	return latest
+}
+
+func parseVersion(value interface{}) (int, error) {
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case uint64:
+		return int(v), nil
+	case float64:
+		if v == math.Trunc(v) {
+			return int(v), nil
+		}
+	case string:
+		if n, err := strconv.Atoi(v); err == nil {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("%w %v", ErrUnsupportedConfigVersion, value)
+}
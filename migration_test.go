@@ -0,0 +1,139 @@
+package configwise
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMigrations(t *testing.T) {
+	// version 1 named the listen address "addr", version 2 split it into host
+	// and port, version 3 moved both below "server"
+	renameAddr := WithMigration(1, 2, func(settings map[string]interface{}) (map[string]interface{}, error) {
+		host, port, ok := strings.Cut(settings["addr"].(string), ":")
+		if !ok {
+			return nil, errors.New("addr without port")
+		}
+		delete(settings, "addr")
+		settings["host"], settings["port"] = host, port
+		return settings, nil
+	})
+	moveToServer := WithMigration(2, 3, func(settings map[string]interface{}) (map[string]interface{}, error) {
+		settings["server"] = map[string]interface{}{"host": settings["host"], "port": settings["port"]}
+		delete(settings, "host")
+		delete(settings, "port")
+		return settings, nil
+	})
+
+	tests := []struct {
+		name    string
+		options []Option
+		config  string
+		want    map[string]interface{}
+		err     string
+	}{
+		{
+			name:    "chain",
+			options: []Option{renameAddr, moveToServer},
+			config:  "version: 1\naddr: localhost:80",
+			want:    map[string]interface{}{"version": 3, "server": map[string]interface{}{"host": "localhost", "port": "80"}},
+		},
+		{
+			name:    "string version",
+			options: []Option{renameAddr, moveToServer},
+			config:  "version: '2'\nhost: localhost\nport: 80",
+			want:    map[string]interface{}{"version": 3, "server": map[string]interface{}{"host": "localhost", "port": 80}},
+		},
+		{
+			name:    "current version",
+			options: []Option{renameAddr, moveToServer},
+			config:  "version: 3\nserver: {host: localhost}",
+			want:    map[string]interface{}{"version": 3, "server": map[string]interface{}{"host": "localhost"}},
+		},
+		{
+			name:    "without version",
+			options: []Option{renameAddr, moveToServer},
+			config:  "addr: localhost:80",
+			want:    map[string]interface{}{"addr": "localhost:80"},
+		},
+		{
+			name:    "version key",
+			options: []Option{renameAddr, WithVersionKey("Meta.Version")},
+			config:  "meta: {version: 1}\naddr: localhost:80\nversion: 1.2.3",
+			want:    map[string]interface{}{"meta": map[string]interface{}{"version": 2}, "host": "localhost", "port": "80", "version": "1.2.3"},
+		},
+		{
+			name:   "without migrations",
+			config: "version: 1.2.3",
+			want:   map[string]interface{}{"version": "1.2.3"},
+		},
+		{
+			name: "versions of other sources",
+			options: []Option{
+				renameAddr,
+				WithDefaults([]byte("version: 1.2.3"), "yaml"),
+				WithFlags([]string{"version=v2"}),
+			},
+			want: map[string]interface{}{"version": "v2"},
+		},
+		{
+			name:    "newer version",
+			options: []Option{renameAddr, moveToServer},
+			config:  "version: 4",
+			err:     "unsupported config version 4, latest is 3",
+		},
+		{
+			name:    "invalid version",
+			options: []Option{renameAddr},
+			config:  "version: 1.2.3",
+			err:     "version: unsupported config version 1.2.3",
+		},
+		{
+			name:    "failing migration",
+			options: []Option{renameAddr},
+			config:  "version: 1\naddr: localhost",
+			err:     "migrate version 1 to 2: addr without port",
+		},
+		{
+			name:    "duplicate migration",
+			options: []Option{renameAddr, renameAddr},
+			err:     "migration 1 -> 2: already registered",
+		},
+		{
+			name:    "downgrade",
+			options: []Option{WithMigration(2, 1, func(s map[string]interface{}) (map[string]interface{}, error) { return s, nil })},
+			err:     "migration 2 -> 1: target version must be greater",
+		},
+		{
+			name:    "nil migration",
+			options: []Option{WithMigration(1, 2, nil)},
+			err:     "migration 1 -> 2: nil migration",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := tt.options
+			if tt.config != "" {
+				options = append([]Option{WithType("yaml"), WithReadInConfig([]byte(tt.config))}, options...)
+			}
+			c, err := NewConfigurer(options...)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error is %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got map[string]interface{}
+			if err := c.Unmarshal(&got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("settings are %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package configwise
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// KeyNormalizer maps keys of a naming convention onto the canonical key
+// space, e.g. maxConns, max-conns and MAX_CONNS onto max_conns. It is applied
+// to every segment of a key.
+type KeyNormalizer interface {
+	NormalizeKey(key string) string
+}
+
+// KeyNormalizerFunc adapts a function to the KeyNormalizer interface.
+type KeyNormalizerFunc func(key string) string
+
+func (fn KeyNormalizerFunc) NormalizeKey(key string) string {
+	return fn(key)
+}
+
+var (
+	// SnakeCase normalizes keys to snake_case.
+	SnakeCase KeyNormalizer = KeyNormalizerFunc(func(key string) string {
+		return strings.Join(splitWords(key), "_")
+	})
+
+	// KebabCase normalizes keys to kebab-case.
+	KebabCase KeyNormalizer = KeyNormalizerFunc(func(key string) string {
+		return strings.Join(splitWords(key), "-")
+	})
+)
+
+// WithKeyNormalizer sets the canonical key space: the keys of all providers
+// and the keys passed to Get, UnmarshalKey, Overwrite and friends are
+// normalized with it. Providers implementing KeyNormalizer themselves, or
+// wrapped with NormalizeKeys, use their own normalizer instead.
+func WithKeyNormalizer(normalizer KeyNormalizer) Option {
+	return func(c *configurer) {
+		c.keyNormalizer = normalizer
+	}
+}
+
+// NormalizeKeys returns the provider with its keys normalized by the
+// normalizer, for sources following a naming convention of their own like
+// camelCase JSON generated by JavaScript tooling.
+func NormalizeKeys(provider Provider, normalizer KeyNormalizer) Provider {
+	return &normalizedProvider{Provider: provider, normalizer: normalizer}
+}
+
+type normalizedProvider struct {
+	Provider
+	normalizer KeyNormalizer
+}
+
+func (p *normalizedProvider) NormalizeKey(key string) string {
+	return p.normalizer.NormalizeKey(key)
+}
+
+func (p *normalizedProvider) Source() Source {
+	return sourceOf(p.Provider)
+}
+
+func (p *normalizedProvider) Positions() map[string]Position {
+	if pp, ok := p.Provider.(Positioner); ok {
+		return pp.Positions()
+	}
+	return nil
+}
+
+// normalizerOf returns the normalizer for the tree of the provider.
+func (cfg *configurer) normalizerOf(p Provider) KeyNormalizer {
+	if n, ok := p.(KeyNormalizer); ok {
+		return n
+	}
+	return cfg.keyNormalizer
+}
+
+// normalizeKeys renames the keys of the tree, and of nested maps, with the normalizer.
+func normalizeKeys(tree map[string]interface{}, normalizer KeyNormalizer) map[string]interface{} {
+	if normalizer == nil {
+		return tree
+	}
+
+	var walk func(value interface{}) interface{}
+	walk = func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			m := make(map[string]interface{}, len(v))
+			for key, val := range v {
+				m[normalizer.NormalizeKey(key)] = walk(val)
+			}
+			return m
+		case map[interface{}]interface{}:
+			m := make(map[string]interface{}, len(v))
+			for key, val := range v {
+				m[normalizer.NormalizeKey(fmt.Sprint(key))] = walk(val)
+			}
+			return m
+		case []interface{}:
+			s := make([]interface{}, len(v))
+			for i, val := range v {
+				s[i] = walk(val)
+			}
+			return s
+		default:
+			return v
+		}
+	}
+	return walk(tree).(map[string]interface{})
+}
+
+// canonicalKey returns the key in the canonical key space, in which
+// deprecated keys are renamed.
+func (cfg *configurer) canonicalKey(key string) string {
+	return cfg.resolveAlias(cfg.renameDeprecated(cfg.normalizeKey(key)))
+}
+
+// normalizeKey lower-cases the key after applying the key normalizer.
+func (cfg *configurer) normalizeKey(key string) string {
+	if cfg.keyNormalizer != nil && key != "" {
+		parts := strings.Split(key, keyDelimiter)
+		for i, part := range parts {
+			parts[i] = cfg.keyNormalizer.NormalizeKey(part)
+		}
+		key = strings.Join(parts, keyDelimiter)
+	}
+	return strings.ToLower(key)
+}
+
+// splitWords splits a key into lower-cased words at separators and at the
+// boundaries of camelCase, e.g. "maxHTTPConns" into "max", "http", "conns".
+func splitWords(key string) []string {
+	var (
+		words []string
+		word  []rune
+	)
+	runes := []rune(key)
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0:
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && nextLower {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+	return words
+}
@@ -0,0 +1,66 @@
+// Package otel records the spans of configwise loads, reloads, provider reads
+// and secret resolution with OpenTelemetry. It is a separate package so that
+// only applications tracing configwise link OpenTelemetry:
+//
+//	c, err := configwise.NewConfigurer(otel.Option())
+package otel
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	otelapi "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gowool/configwise"
+)
+
+const tracerName = "github.com/gowool/configwise"
+
+// Option records spans with the global tracer provider of OpenTelemetry, see
+// WithTracerProvider.
+func Option() configwise.Option {
+	return WithTracerProvider(otelapi.GetTracerProvider())
+}
+
+// WithTracerProvider records OpenTelemetry spans for the initial load, reloads,
+// reads of every provider and the resolution of secrets. Reads of single
+// values are not traced.
+func WithTracerProvider(provider trace.TracerProvider) configwise.Option {
+	return configwise.WithTracer(NewTracer(provider))
+}
+
+// NewTracer returns a configwise.Tracer recording spans with the tracer of
+// the provider named after the configwise module.
+func NewTracer(provider trace.TracerProvider) configwise.Tracer {
+	return tracer{tracer: provider.Tracer(tracerName)}
+}
+
+type tracer struct {
+	tracer trace.Tracer
+}
+
+func (t tracer) Start(ctx context.Context, name string, attrs map[string]string) (context.Context, configwise.Span) {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, key := range slices.Sorted(maps.Keys(attrs)) {
+		kvs = append(kvs, attribute.String(key, attrs[key]))
+	}
+	ctx, s := t.tracer.Start(ctx, name, trace.WithAttributes(kvs...))
+	return ctx, span{span: s}
+}
+
+type span struct {
+	span trace.Span
+}
+
+// End records the error, if any, and ends the span.
+func (s span) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
@@ -0,0 +1,124 @@
+package otel
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	otelapi "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/gowool/configwise"
+)
+
+// recorder is a tracer provider recording the spans started and ended.
+type recorder struct {
+	noop.TracerProvider
+	spans []*recordedSpan
+}
+
+func (r *recorder) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return recordingTracer{recorder: r}
+}
+
+type recordingTracer struct {
+	noop.Tracer
+	recorder *recorder
+}
+
+func (t recordingTracer) Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	config := trace.NewSpanStartConfig(options...)
+	s := &recordedSpan{name: name, attrs: config.Attributes()}
+	t.recorder.spans = append(t.recorder.spans, s)
+	return ctx, s
+}
+
+type recordedSpan struct {
+	noop.Span
+	name   string
+	attrs  []attribute.KeyValue
+	status codes.Code
+	ended  bool
+}
+
+func (s *recordedSpan) SetStatus(code codes.Code, _ string) {
+	s.status = code
+}
+
+func (s *recordedSpan) End(...trace.SpanEndOption) {
+	s.ended = true
+}
+
+func TestWithTracerProvider(t *testing.T) {
+	r := &recorder{}
+	_, err := configwise.NewConfigurer(
+		WithTracerProvider(r),
+		configwise.WithType("yaml"),
+		configwise.WithReadInConfig([]byte("port: 80")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, s := range r.spans {
+		names = append(names, s.name)
+		if !s.ended {
+			t.Errorf("span %s is not ended", s.name)
+		}
+		if s.status == codes.Error {
+			t.Errorf("span %s failed", s.name)
+		}
+	}
+	for _, name := range []string{"configwise.load", "configwise.provider.read", "configwise.secrets.resolve"} {
+		if !slices.Contains(names, name) {
+			t.Errorf("spans are %v, want %s", names, name)
+		}
+	}
+
+	i := slices.IndexFunc(r.spans, func(s *recordedSpan) bool {
+		return s.name == "configwise.provider.read" && slices.Contains(s.attrs, attribute.String("configwise.provider", "read in config"))
+	})
+	if i == -1 {
+		t.Fatalf("spans are %v, want the read of the read in config", names)
+	}
+	if !slices.Contains(r.spans[i].attrs, attribute.String("configwise.source", string(configwise.SourceFile))) {
+		t.Errorf("attributes are %v, want the source", r.spans[i].attrs)
+	}
+}
+
+func TestWithTracerProviderRecordsErrors(t *testing.T) {
+	r := &recorder{}
+	_, err := configwise.NewConfigurer(
+		WithTracerProvider(r),
+		configwise.WithType("yaml"),
+		configwise.WithReadInConfig([]byte("port: [80")),
+	)
+	if err == nil {
+		t.Fatal("invalid config is loaded")
+	}
+
+	failed := make(map[string]bool)
+	for _, s := range r.spans {
+		failed[s.name] = s.status == codes.Error
+	}
+	if !failed["configwise.load"] || !failed["configwise.provider.read"] {
+		t.Errorf("failed spans are %v, want the load and the provider read", failed)
+	}
+}
+
+func TestOption(t *testing.T) {
+	r := &recorder{}
+	otelapi.SetTracerProvider(r)
+
+	_, err := configwise.NewConfigurer(Option(), configwise.WithConfigMap(map[string]interface{}{"port": 80}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.spans) == 0 || r.spans[0].name != "configwise.load" {
+		t.Fatalf("spans of the global tracer provider are %v, want configwise.load", r.spans)
+	}
+}
@@ -0,0 +1,411 @@
+package configwise
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidPatch is returned for patches which are malformed or cannot be
+// applied, like a JSON Patch whose test operation fails.
+var ErrInvalidPatch = errors.New("invalid patch")
+
+// PatchKind is the format of a patch passed to ApplyPatch.
+type PatchKind int
+
+const (
+	// PatchMerge is a JSON Merge Patch (RFC 7386): the patch is merged into
+	// the configuration and null values remove keys.
+	PatchMerge PatchKind = iota
+	// PatchJSON is a JSON Patch (RFC 6902): a list of add, remove, replace,
+	// move, copy and test operations addressed by JSON Pointers.
+	PatchJSON
+)
+
+// ApplyPatch applies the patch to the configuration, as a single runtime
+// change like Overwrite. Keys of the patch are matched case-insensitively,
+// keys removed by the patch stay removed across reloads until they are
+// overwritten again. A JSON Patch is applied entirely or not at all.
+func (cfg *configurer) ApplyPatch(patch []byte, kind PatchKind) error {
+	return cfg.ApplyPatchContext(context.Background(), patch, kind)
+}
+
+// ApplyPatchContext is like ApplyPatch, recording actor and request ID of the
+// context in the audit log.
+func (cfg *configurer) ApplyPatchContext(ctx context.Context, patch []byte, kind PatchKind) error {
+	return cfg.applyPatch(ctx, "", patch, kind)
+}
+
+// applyPatch applies the patch to the section stored under root, or to the
+// whole configuration for an empty root.
+func (cfg *configurer) applyPatch(ctx context.Context, root string, patch []byte, kind PatchKind) error {
+	cfg.writeMu.Lock()
+	if cfg.frozen.Load() {
+		cfg.writeMu.Unlock()
+		return &Error{Op: OpApplyPatch, Err: ErrFrozen}
+	}
+	cfg.mu.RLock()
+	old, base, secrets, overrides := cfg.settings, cfg.base, cfg.secrets, cfg.overrides
+	cfg.mu.RUnlock()
+
+	var doc interface{} = old
+	if root != "" {
+		doc, _ = searchPath(old, root)
+	}
+	patched, err := patchDocument(deepCopy(doc), patch, kind)
+	if err == nil && root == "" {
+		if _, ok := patched.(map[string]interface{}); !ok {
+			err = fmt.Errorf("%w: the configuration must remain a section", ErrInvalidPatch)
+		}
+	}
+	if err != nil {
+		cfg.writeMu.Unlock()
+		return &Error{Op: OpApplyPatch, Err: err}
+	}
+
+	var tree map[string]interface{}
+	if root == "" {
+		tree = normalizeTree(patched.(map[string]interface{}))
+	} else {
+		tree = deepCopy(old).(map[string]interface{})
+		setPath(tree, root, normalizeValue(patched))
+	}
+
+	// the patch as changes of leaf keys, nil for removed keys
+	values := make(map[string]interface{})
+	for _, change := range diffSettings(old, tree) {
+		values[change.key] = change.newValue
+	}
+	if values, err = cfg.coerceValues(values); err != nil {
+		cfg.writeMu.Unlock()
+		return &Error{Op: OpApplyPatch, Err: err}
+	}
+
+	overrides = patchOverrides(overrides, values)
+	settings := withOverrides(base, overrides)
+	if err = cfg.validateSchema(settings); err != nil {
+		cfg.writeMu.Unlock()
+		return &Error{Op: OpApplyPatch, Err: err}
+	}
+
+	if cfg.journal != nil {
+		err = cfg.journal.append(journalEntry{
+			Time:      time.Now().UTC(),
+			Op:        "patch",
+			Values:    cfg.redactValues(values, secrets),
+			Actor:     ActorFromContext(ctx),
+			RequestID: RequestIDFromContext(ctx),
+		})
+		if err != nil {
+			cfg.writeMu.Unlock()
+			return &Error{Op: OpApplyPatch, Err: fmt.Errorf("journal: %w", err)}
+		}
+	}
+
+	cfg.mu.Lock()
+	cfg.settings, cfg.overrides = settings, overrides
+	cfg.record("patch")
+	cfg.mu.Unlock()
+	cfg.writeMu.Unlock()
+
+	cfg.auditChange(ctx, "patch", values)
+	cfg.publishChange("patch", old, settings, secrets)
+	return nil
+}
+
+// patchOverrides returns the overrides with the values of a patch applied.
+// Nil values remove their keys, even if the providers define them.
+func patchOverrides(overrides []override, values map[string]interface{}) []override {
+	// sections before their keys, so that overrides of keys are kept
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		value := values[key]
+		overrides = setOverride(overrides, override{key: key, value: value, unset: value == nil})
+	}
+	return overrides
+}
+
+// patchDocument applies the patch to the document, which it may modify.
+func patchDocument(doc interface{}, patch []byte, kind PatchKind) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(patch))
+	decoder.UseNumber()
+
+	switch kind {
+	case PatchMerge:
+		var p interface{}
+		if err := decoder.Decode(&p); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+		}
+		return mergePatch(doc, fromJSONNumbers(p)), nil
+	case PatchJSON:
+		var ops []patchOperation
+		if err := decoder.Decode(&ops); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+		}
+		for i, op := range ops {
+			var err error
+			if doc, err = op.apply(doc); err != nil {
+				return nil, fmt.Errorf("%w: operation %d (%s %s): %w", ErrInvalidPatch, i, op.Op, op.Path, err)
+			}
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown patch kind %d", ErrInvalidPatch, kind)
+	}
+}
+
+// mergePatch applies a JSON Merge Patch to the target as defined by RFC 7386.
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{})
+	}
+	for key, value := range p {
+		key = strings.ToLower(key)
+		if value == nil {
+			delete(t, key)
+			continue
+		}
+		t[key] = mergePatch(t[key], value)
+	}
+	return t
+}
+
+// patchOperation is an operation of a JSON Patch.
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// apply applies the operation to the document as defined by RFC 6902.
+func (op patchOperation) apply(doc interface{}) (interface{}, error) {
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, errors.New("missing value")
+		}
+		decoder := json.NewDecoder(bytes.NewReader(op.Value))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		value = fromJSONNumbers(value)
+
+		switch op.Op {
+		case "add":
+			return pointerAdd(doc, op.Path, value)
+		case "replace":
+			if op.Path == "" {
+				return value, nil
+			}
+			doc, err := pointerRemove(doc, op.Path)
+			if err != nil {
+				return nil, err
+			}
+			return pointerAdd(doc, op.Path, value)
+		default:
+			current, err := pointerGet(doc, op.Path)
+			if err != nil {
+				return nil, err
+			}
+			if !jsonEqual(current, value) {
+				return nil, errors.New("test failed")
+			}
+			return doc, nil
+		}
+	case "remove":
+		return pointerRemove(doc, op.Path)
+	case "move", "copy":
+		value, err := pointerGet(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if op.Path == op.From {
+				return doc, nil
+			}
+			if strings.HasPrefix(op.Path, op.From+"/") {
+				return nil, errors.New("cannot move a value into itself")
+			}
+			if doc, err = pointerRemove(doc, op.From); err != nil {
+				return nil, err
+			}
+		} else {
+			value = deepCopy(value)
+		}
+		return pointerAdd(doc, op.Path, value)
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+// jsonEqual reports whether the values are equal as JSON, so that numbers of
+// different Go types compare equal.
+func jsonEqual(a, b interface{}) bool {
+	x, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	y, err := json.Marshal(b)
+	return err == nil && bytes.Equal(x, y)
+}
+
+// pointerTokens splits a JSON Pointer into its unescaped reference tokens.
+func pointerTokens(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// pointerGet returns the value the pointer refers to.
+func pointerGet(doc interface{}, pointer string) (interface{}, error) {
+	tokens, err := pointerTokens(pointer)
+	if err != nil {
+		return nil, err
+	}
+	current := doc
+	for _, token := range tokens {
+		switch v := current.(type) {
+		case map[string]interface{}:
+			value, ok := v[strings.ToLower(token)]
+			if !ok {
+				return nil, fmt.Errorf("path %q not found", pointer)
+			}
+			current = value
+		case []interface{}:
+			i, err := listIndex(token, len(v))
+			if err != nil {
+				return nil, err
+			}
+			current = v[i]
+		default:
+			return nil, fmt.Errorf("path %q not found", pointer)
+		}
+	}
+	return current, nil
+}
+
+// pointerAdd adds the value at the pointer and returns the document.
+func pointerAdd(doc interface{}, pointer string, value interface{}) (interface{}, error) {
+	return pointerUpdate(doc, pointer, func(parent interface{}, token string) (interface{}, error) {
+		switch v := parent.(type) {
+		case map[string]interface{}:
+			v[strings.ToLower(token)] = value
+			return v, nil
+		case []interface{}:
+			if token == "-" {
+				return append(v, value), nil
+			}
+			i, err := listIndex(token, len(v)+1)
+			if err != nil {
+				return nil, err
+			}
+			v = append(v, nil)
+			copy(v[i+1:], v[i:])
+			v[i] = value
+			return v, nil
+		default:
+			return nil, fmt.Errorf("path %q not found", pointer)
+		}
+	}, value)
+}
+
+// pointerRemove removes the value at the pointer and returns the document.
+func pointerRemove(doc interface{}, pointer string) (interface{}, error) {
+	if pointer == "" {
+		return nil, errors.New("cannot remove the whole document")
+	}
+	return pointerUpdate(doc, pointer, func(parent interface{}, token string) (interface{}, error) {
+		switch v := parent.(type) {
+		case map[string]interface{}:
+			key := strings.ToLower(token)
+			if _, ok := v[key]; !ok {
+				return nil, fmt.Errorf("path %q not found", pointer)
+			}
+			delete(v, key)
+			return v, nil
+		case []interface{}:
+			i, err := listIndex(token, len(v))
+			if err != nil {
+				return nil, err
+			}
+			return append(v[:i], v[i+1:]...), nil
+		default:
+			return nil, fmt.Errorf("path %q not found", pointer)
+		}
+	}, nil)
+}
+
+// pointerUpdate calls fn with the parent of the value the pointer refers to
+// and the last token, and stores the updated parent in the document. A
+// pointer to the whole document replaces it with root.
+func pointerUpdate(doc interface{}, pointer string, fn func(parent interface{}, token string) (interface{}, error), root interface{}) (interface{}, error) {
+	tokens, err := pointerTokens(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return root, nil
+	}
+
+	var update func(current interface{}, tokens []string) (interface{}, error)
+	update = func(current interface{}, tokens []string) (interface{}, error) {
+		if len(tokens) == 1 {
+			return fn(current, tokens[0])
+		}
+		switch v := current.(type) {
+		case map[string]interface{}:
+			key := strings.ToLower(tokens[0])
+			child, ok := v[key]
+			if !ok {
+				return nil, fmt.Errorf("path %q not found", pointer)
+			}
+			if v[key], err = update(child, tokens[1:]); err != nil {
+				return nil, err
+			}
+			return v, nil
+		case []interface{}:
+			i, err := listIndex(tokens[0], len(v))
+			if err != nil {
+				return nil, err
+			}
+			if v[i], err = update(v[i], tokens[1:]); err != nil {
+				return nil, err
+			}
+			return v, nil
+		default:
+			return nil, fmt.Errorf("path %q not found", pointer)
+		}
+	}
+	return update(doc, tokens)
+}
+
+// listIndex parses the token as an index of a list of size n.
+func listIndex(token string, n int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i >= n || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid list index %q", token)
+	}
+	return i, nil
+}
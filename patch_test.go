@@ -0,0 +1,233 @@
+package configwise
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPatchDocument(t *testing.T) {
+	const doc = `{"server": {"host": "localhost", "port": 80}, "tags": ["a", "b"], "a/b": 1, "m~n": 2}`
+
+	tests := []struct {
+		name  string
+		kind  PatchKind
+		patch string
+		want  string
+		err   string
+	}{
+		{
+			name:  "merge",
+			kind:  PatchMerge,
+			patch: `{"server": {"port": 8080, "host": null, "tls": {"enabled": true}}, "tags": ["c"]}`,
+			want:  `{"server": {"port": 8080, "tls": {"enabled": true}}, "tags": ["c"], "a/b": 1, "m~n": 2}`,
+		},
+		{
+			name:  "merge replaces a section",
+			kind:  PatchMerge,
+			patch: `{"server": "off"}`,
+			want:  `{"server": "off", "tags": ["a", "b"], "a/b": 1, "m~n": 2}`,
+		},
+		{
+			name:  "merge of a scalar",
+			kind:  PatchMerge,
+			patch: `"off"`,
+			want:  `"off"`,
+		},
+		{
+			name:  "add",
+			kind:  PatchJSON,
+			patch: `[{"op": "add", "path": "/server/tls", "value": {"enabled": true}}]`,
+			want:  `{"server": {"host": "localhost", "port": 80, "tls": {"enabled": true}}, "tags": ["a", "b"], "a/b": 1, "m~n": 2}`,
+		},
+		{
+			name: "add to list",
+			kind: PatchJSON,
+			patch: `[
+				{"op": "add", "path": "/tags/0", "value": "first"},
+				{"op": "add", "path": "/tags/-", "value": "last"}
+			]`,
+			want: `{"server": {"host": "localhost", "port": 80}, "tags": ["first", "a", "b", "last"], "a/b": 1, "m~n": 2}`,
+		},
+		{
+			name: "remove",
+			kind: PatchJSON,
+			patch: `[
+				{"op": "remove", "path": "/server/host"},
+				{"op": "remove", "path": "/tags/0"},
+				{"op": "remove", "path": "/a~1b"},
+				{"op": "remove", "path": "/m~0n"}
+			]`,
+			want: `{"server": {"port": 80}, "tags": ["b"]}`,
+		},
+		{
+			name:  "replace",
+			kind:  PatchJSON,
+			patch: `[{"op": "replace", "path": "/server/port", "value": 8080}, {"op": "replace", "path": "/tags/1", "value": "c"}]`,
+			want:  `{"server": {"host": "localhost", "port": 8080}, "tags": ["a", "c"], "a/b": 1, "m~n": 2}`,
+		},
+		{
+			name:  "replace the document",
+			kind:  PatchJSON,
+			patch: `[{"op": "replace", "path": "", "value": {"a": 1}}]`,
+			want:  `{"a": 1}`,
+		},
+		{
+			name:  "move",
+			kind:  PatchJSON,
+			patch: `[{"op": "move", "from": "/server/host", "path": "/host"}]`,
+			want:  `{"server": {"port": 80}, "host": "localhost", "tags": ["a", "b"], "a/b": 1, "m~n": 2}`,
+		},
+		{
+			name:  "copy",
+			kind:  PatchJSON,
+			patch: `[{"op": "copy", "from": "/server", "path": "/backup"}, {"op": "replace", "path": "/backup/port", "value": 81}]`,
+			want:  `{"server": {"host": "localhost", "port": 80}, "backup": {"host": "localhost", "port": 81}, "tags": ["a", "b"], "a/b": 1, "m~n": 2}`,
+		},
+		{
+			name:  "test",
+			kind:  PatchJSON,
+			patch: `[{"op": "test", "path": "/server/port", "value": 80.0}, {"op": "test", "path": "/tags", "value": ["a", "b"]}]`,
+			want:  doc,
+		},
+		{
+			name:  "failed test",
+			kind:  PatchJSON,
+			patch: `[{"op": "remove", "path": "/tags"}, {"op": "test", "path": "/server/port", "value": 81}]`,
+			err:   "operation 1 (test /server/port): test failed",
+		},
+		{
+			name:  "missing path",
+			kind:  PatchJSON,
+			patch: `[{"op": "replace", "path": "/server/tls", "value": true}]`,
+			err:   `path "/server/tls" not found`,
+		},
+		{
+			name:  "invalid index",
+			kind:  PatchJSON,
+			patch: `[{"op": "add", "path": "/tags/3", "value": "c"}]`,
+			err:   `invalid list index "3"`,
+		},
+		{
+			name:  "invalid pointer",
+			kind:  PatchJSON,
+			patch: `[{"op": "remove", "path": "server"}]`,
+			err:   `invalid JSON pointer "server"`,
+		},
+		{
+			name:  "move into itself",
+			kind:  PatchJSON,
+			patch: `[{"op": "move", "from": "/server", "path": "/server/inner"}]`,
+			err:   "cannot move a value into itself",
+		},
+		{
+			name:  "missing value",
+			kind:  PatchJSON,
+			patch: `[{"op": "add", "path": "/a"}]`,
+			err:   "missing value",
+		},
+		{
+			name:  "unknown operation",
+			kind:  PatchJSON,
+			patch: `[{"op": "swap", "path": "/a"}]`,
+			err:   `unknown operation "swap"`,
+		},
+		{
+			name:  "malformed",
+			kind:  PatchMerge,
+			patch: `{`,
+			err:   "unexpected EOF",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := patchDocument(jsonDocument(t, doc), []byte(tt.patch), tt.kind)
+			if tt.err != "" {
+				if !errors.Is(err, ErrInvalidPatch) || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error is %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := jsonDocument(t, tt.want); !jsonEqual(got, want) {
+				t.Errorf("document is %#v, want %#v", got, want)
+			}
+		})
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	const config = `
+server:
+  host: localhost
+  port: 80
+tags: [a, b]
+`
+	c, err := NewConfigurer(WithType("yaml"), WithReadInConfig([]byte(config)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.ApplyPatch([]byte(`{"Server": {"Port": 8080, "host": null}}`), PatchMerge); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.GetInt("server.port"); got != 8080 {
+		t.Errorf("server.port is %d, want 8080", got)
+	}
+	if c.Get("server.host") != nil {
+		t.Errorf("server.host is set to %v, want it removed", c.Get("server.host"))
+	}
+
+	// a failing JSON Patch changes nothing
+	err = c.ApplyPatch([]byte(`[{"op": "replace", "path": "/server/port", "value": 9090}, {"op": "test", "path": "/tags/0", "value": "x"}]`), PatchJSON)
+	if !errors.Is(err, ErrInvalidPatch) {
+		t.Fatalf("error is %v, want ErrInvalidPatch", err)
+	}
+	if got := c.GetInt("server.port"); got != 8080 {
+		t.Errorf("server.port is %d after a failed patch, want 8080", got)
+	}
+
+	err = c.ApplyPatch([]byte(`[{"op": "add", "path": "/tags/-", "value": "c"}, {"op": "copy", "from": "/server/port", "path": "/server/admin_port"}]`), PatchJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := c.GetStringSlice("tags"), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("tags are %v, want %v", got, want)
+	}
+	if got := c.GetInt("server.admin_port"); got != 8080 {
+		t.Errorf("server.admin_port is %d, want 8080", got)
+	}
+
+	// patched and removed keys survive reloads
+	if err := c.(*configurer).reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.GetInt("server.port"); got != 8080 {
+		t.Errorf("server.port is %d after reload, want 8080", got)
+	}
+	if c.Get("server.host") != nil {
+		t.Errorf("server.host is set to %v after reload, want it removed", c.Get("server.host"))
+	}
+
+	err = c.ApplyPatch([]byte(`[{"op": "replace", "path": "", "value": [1]}]`), PatchJSON)
+	if !errors.Is(err, ErrInvalidPatch) {
+		t.Fatalf("error is %v, want ErrInvalidPatch for a configuration which is no section", err)
+	}
+}
+
+// jsonDocument decodes a JSON document with the numbers of patch values.
+func jsonDocument(t *testing.T, doc string) interface{} {
+	t.Helper()
+	decoder := json.NewDecoder(strings.NewReader(doc))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	return fromJSONNumbers(v)
+}
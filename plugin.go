@@ -0,0 +1,154 @@
+package configwise
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// PluginsKey is the config key listing the plugins to activate, e.g.
+//
+//	plugins:
+//	  - name: metrics
+//	  - path: /usr/lib/app/auth.so
+//
+// Entries with a name refer to plugins registered with RegisterPlugin,
+// usually by files guarded with build tags. Entries with a path refer to Go
+// plugins, which export their Plugin as the symbol PluginSymbol, and are
+// opened by the opener registered with RegisterPluginOpener.
+const PluginsKey = "plugins"
+
+// PluginSymbol is the name of the symbol looked up in Go plugins.
+const PluginSymbol = "Plugin"
+
+// Plugin is a module of the application activated by the config.
+type Plugin interface {
+	// Section returns the config key of the plugin settings.
+	Section() string
+
+	// Config returns a pointer to a new value the section is decoded into.
+	Config() interface{}
+
+	// Load activates the plugin with its decoded and validated config.
+	Load(config interface{}) error
+}
+
+// PluginOpener opens the plugin of an entry of the plugins section with a
+// path.
+type PluginOpener func(path string) (Plugin, error)
+
+var (
+	pluginsMu    sync.RWMutex
+	plugins      = make(map[string]Plugin)
+	pluginOpener PluginOpener
+)
+
+// RegisterPlugin makes the plugin available under name to the plugins
+// section. It panics when name is registered twice.
+func RegisterPlugin(name string, p Plugin) {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+
+	if p == nil {
+		panic("configwise: register plugin " + name + ": nil plugin")
+	}
+	if _, ok := plugins[name]; ok {
+		panic("configwise: register plugin " + name + ": already registered")
+	}
+	plugins[name] = p
+}
+
+// RegisterPluginOpener sets the opener of the entries of the plugins section
+// with a path. The plugin subpackage registers the opener of Go plugins when
+// it is imported, so that only applications loading Go plugins link the
+// runtime support of the plugin package:
+//
+//	import _ "github.com/gowool/configwise/plugin"
+func RegisterPluginOpener(opener PluginOpener) {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+
+	pluginOpener = opener
+}
+
+// pluginSpec is an entry of the plugins section.
+type pluginSpec struct {
+	Name string
+	Path string
+}
+
+// LoadPlugins activates the plugins listed in the plugins section. The
+// sections of all plugins are decoded and validated first, like with
+// UnmarshalKey, and no plugin is loaded unless all of them are valid.
+func LoadPlugins(c Configurer) error {
+	var specs []pluginSpec
+	if err := c.UnmarshalKey(PluginsKey, &specs); err != nil {
+		return fmt.Errorf("plugins: %w", err)
+	}
+
+	type loadable struct {
+		name   string
+		plugin Plugin
+		config interface{}
+	}
+	var (
+		loads []loadable
+		errs  []error
+	)
+	for i, spec := range specs {
+		name, p, err := openPlugin(spec)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s[%d]: %w", PluginsKey, i, err))
+			continue
+		}
+		config := p.Config()
+		if config != nil {
+			if err = c.UnmarshalKey(p.Section(), config); err != nil {
+				errs = append(errs, fmt.Errorf("plugin %s: %w", name, err))
+				continue
+			}
+		}
+		loads = append(loads, loadable{name: name, plugin: p, config: config})
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("plugins: %w", errors.Join(errs...))
+	}
+
+	for _, l := range loads {
+		if err := l.plugin.Load(l.config); err != nil {
+			return fmt.Errorf("plugins: plugin %s: %w", l.name, err)
+		}
+	}
+	return nil
+}
+
+// openPlugin returns the registered plugin or opens the plugin at the path
+// of the spec.
+func openPlugin(spec pluginSpec) (string, Plugin, error) {
+	switch {
+	case spec.Name != "" && spec.Path != "":
+		return "", nil, errors.New("name and path are mutually exclusive")
+	case spec.Name != "":
+		pluginsMu.RLock()
+		p, ok := plugins[spec.Name]
+		pluginsMu.RUnlock()
+		if !ok {
+			return "", nil, fmt.Errorf("plugin %s is not registered", spec.Name)
+		}
+		return spec.Name, p, nil
+	case spec.Path != "":
+		pluginsMu.RLock()
+		open := pluginOpener
+		pluginsMu.RUnlock()
+		if open == nil {
+			return "", nil, fmt.Errorf("%s: no plugin opener is registered, import github.com/gowool/configwise/plugin", spec.Path)
+		}
+		p, err := open(spec.Path)
+		if err != nil {
+			return "", nil, err
+		}
+		return spec.Path, p, nil
+	default:
+		return "", nil, errors.New("name or path is required")
+	}
+}
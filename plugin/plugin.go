@@ -0,0 +1,41 @@
+// Package plugin opens Go plugins listed with a path in the plugins section
+// of configwise. Importing it registers Open as the plugin opener; it is a
+// separate package so that only applications loading Go plugins link the
+// runtime support of the standard plugin package:
+//
+//	import _ "github.com/gowool/configwise/plugin"
+package plugin
+
+import (
+	"fmt"
+	goplugin "plugin"
+
+	"github.com/gowool/configwise"
+)
+
+func init() {
+	configwise.RegisterPluginOpener(Open)
+}
+
+// Open opens the Go plugin at the path and returns the configwise.Plugin it
+// exports as the symbol configwise.PluginSymbol.
+func Open(path string) (configwise.Plugin, error) {
+	so, err := goplugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	sym, err := so.Lookup(configwise.PluginSymbol)
+	if err != nil {
+		return nil, err
+	}
+	// exported variables are looked up as pointers
+	switch p := sym.(type) {
+	case configwise.Plugin:
+		return p, nil
+	case *configwise.Plugin:
+		if *p != nil {
+			return *p, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: symbol %s is not a configwise.Plugin", path, configwise.PluginSymbol)
+}
@@ -0,0 +1,28 @@
+package plugin
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gowool/configwise"
+)
+
+func TestOpenIsRegistered(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.so")
+	c, err := configwise.NewConfigurer(
+		configwise.WithType("yaml"),
+		configwise.WithReadInConfig([]byte("plugins: [{path: "+path+"}]")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = configwise.LoadPlugins(c)
+	if err == nil || strings.Contains(err.Error(), "no plugin opener is registered") {
+		t.Fatalf("error is %v, want the error of opening %s", err, path)
+	}
+	if !strings.Contains(err.Error(), path) {
+		t.Errorf("error is %v, want the path", err)
+	}
+}
@@ -0,0 +1,119 @@
+package configwise
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testPlugin records the configs it is loaded with.
+type testPlugin struct {
+	section string
+	loaded  []interface{}
+}
+
+type testPluginConfig struct {
+	Port int
+}
+
+func (p *testPlugin) Section() string {
+	return p.section
+}
+
+func (p *testPlugin) Config() interface{} {
+	return new(testPluginConfig)
+}
+
+func (p *testPlugin) Load(config interface{}) error {
+	p.loaded = append(p.loaded, config)
+	return nil
+}
+
+var testMetrics = &testPlugin{section: "metrics"}
+
+func init() {
+	RegisterPlugin("test-metrics", testMetrics)
+}
+
+func TestLoadPlugins(t *testing.T) {
+	auth := &testPlugin{section: "auth"}
+	opener := func(path string) (Plugin, error) {
+		if path != "/plugins/auth.so" {
+			return nil, errors.New("no such plugin")
+		}
+		return auth, nil
+	}
+
+	tests := []struct {
+		name   string
+		config string
+		opener PluginOpener
+		loaded bool
+		err    string
+	}{
+		{
+			name:   "name and path",
+			config: "plugins: [{name: test-metrics}, {path: /plugins/auth.so}]\nmetrics: {port: 9090}\nauth: {port: 8443}",
+			opener: opener,
+			loaded: true,
+		},
+		{
+			name:   "path without opener",
+			config: "plugins: [{name: test-metrics}, {path: /plugins/auth.so}]",
+			err:    "plugins[1]: /plugins/auth.so: no plugin opener is registered",
+		},
+		{
+			name:   "opener error",
+			config: "plugins: [{path: /plugins/other.so}]",
+			opener: opener,
+			err:    "plugins[0]: no such plugin",
+		},
+		{
+			name:   "unregistered name",
+			config: "plugins: [{name: test-tracing}]",
+			err:    "plugins[0]: plugin test-tracing is not registered",
+		},
+		{
+			name:   "name and path of an entry",
+			config: "plugins: [{name: test-metrics, path: /plugins/auth.so}]",
+			err:    "plugins[0]: name and path are mutually exclusive",
+		},
+		{
+			name:   "invalid section",
+			config: "plugins: [{name: test-metrics}, {path: /plugins/auth.so}]\nauth: {port: https}",
+			opener: opener,
+			err:    "plugin /plugins/auth.so:",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RegisterPluginOpener(tt.opener)
+			t.Cleanup(func() { RegisterPluginOpener(nil) })
+			testMetrics.loaded, auth.loaded = nil, nil
+
+			c, err := NewConfigurer(WithType("yaml"), WithReadInConfig([]byte(tt.config)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = LoadPlugins(c)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error is %v, want %q", err, tt.err)
+				}
+				if len(testMetrics.loaded)+len(auth.loaded) > 0 {
+					t.Errorf("plugins are loaded although %q failed", tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(testMetrics.loaded) != 1 || testMetrics.loaded[0].(*testPluginConfig).Port != 9090 {
+				t.Errorf("metrics plugin is loaded with %v, want port 9090", testMetrics.loaded)
+			}
+			if len(auth.loaded) != 1 || auth.loaded[0].(*testPluginConfig).Port != 8443 {
+				t.Errorf("auth plugin is loaded with %v, want port 8443", auth.loaded)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package configwise
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Position is the location of a value in a config document.
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// Positioner is implemented by providers which know where their values are
+// defined, like the providers of YAML documents.
+type Positioner interface {
+	// Positions returns the positions of the values of the last Read, keyed
+	// by the keys of the tree returned by Read, e.g. "http.timeout" or "hosts[1]".
+	Positions() map[string]Position
+}
+
+// KeyError is an error concerning the value of a key, like a required value
+// which is not set.
+type KeyError struct {
+	Key string
+	Err error
+}
+
+func (e *KeyError) Error() string {
+	return e.Key + ": " + e.Err.Error()
+}
+
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+// PositionError locates an error concerning a key in the config document
+// which defines the value, e.g. "config.yaml:42:7: http.timeout: ...".
+type PositionError struct {
+	Position Position
+	Err      error
+}
+
+func (e *PositionError) Error() string {
+	return e.Position.String() + ": " + e.Err.Error()
+}
+
+func (e *PositionError) Unwrap() error {
+	return e.Err
+}
+
+// yamlPositions returns the positions of all keys of the YAML documents.
+// Sections are located at their key, values at the value itself. Keys of
+// later documents of a stream are located in the later document.
+func yamlPositions(file string, data []byte) map[string]Position {
+	positions := make(map[string]Position)
+	var walk func(key string, at, node *yaml.Node)
+	walk = func(key string, at, node *yaml.Node) {
+		if node.Kind == yaml.AliasNode && node.Alias != nil {
+			node = node.Alias
+		}
+		if key != "" {
+			if node.Kind == yaml.ScalarNode {
+				at = node
+			}
+			positions[key] = Position{File: file, Line: at.Line, Column: at.Column}
+		}
+
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				k, v := node.Content[i], node.Content[i+1]
+				if k.Value == "<<" {
+					continue
+				}
+				walk(joinKey(key, k.Value), k, v)
+			}
+		case yaml.SequenceNode:
+			for i, item := range node.Content {
+				walk(key+"["+strconv.Itoa(i)+"]", item, item)
+			}
+		}
+	}
+
+	d := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		if err := d.Decode(&doc); err != nil {
+			break
+		}
+		if len(doc.Content) > 0 {
+			walk("", doc.Content[0], doc.Content[0])
+		}
+	}
+	if len(positions) == 0 {
+		return nil
+	}
+	return positions
+}
+
+// normalizePositions renames the keys of the positions of a provider like
+// its tree is renamed, so they can be looked up by canonical keys.
+func normalizePositions(positions map[string]Position, normalizer KeyNormalizer) map[string]Position {
+	out := make(map[string]Position, len(positions))
+	for key, pos := range positions {
+		parts := strings.Split(key, keyDelimiter)
+		for i, part := range parts {
+			name, index, _ := strings.Cut(part, "[")
+			if normalizer != nil {
+				name = normalizer.NormalizeKey(name)
+			}
+			if index != "" {
+				name += "[" + index
+			}
+			parts[i] = strings.ToLower(name)
+		}
+		out[strings.Join(parts, keyDelimiter)] = pos
+	}
+	return out
+}
+
+// position returns the position of the value of the key, or of its closest
+// parent with a known position, as defined by the provider of the value.
+func (cfg *configurer) position(key string) (Position, bool) {
+	cfg.mu.RLock()
+	positions := cfg.positions
+	cfg.mu.RUnlock()
+
+	// the provider of the value, which may be a list holding the key
+	var provider Provider
+	for k := key; k != "" && provider == nil; k = parentKey(k) {
+		provider = cfg.origin(k)
+	}
+	if provider == nil {
+		return Position{}, false
+	}
+
+	for k := key; k != ""; k = parentKey(k) {
+		if pos, ok := positions[provider][k]; ok {
+			return pos, true
+		}
+	}
+	return Position{}, false
+}
+
+// parentKey returns the key of the section or list holding the key, or an
+// empty string for top-level keys.
+func parentKey(key string) string {
+	idx := strings.LastIndexAny(key, ".[")
+	if idx == -1 {
+		return ""
+	}
+	return key[:idx]
+}
+
+// locate wraps every error concerning a key in a PositionError, if the
+// position of the key is known.
+func (cfg *configurer) locate(err error) error {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs := joined.Unwrap()
+		located := make([]error, len(errs))
+		for i, e := range errs {
+			located[i] = cfg.locate(e)
+		}
+		return errors.Join(located...)
+	}
+
+	var (
+		key string
+		de  *DecodeError
+		ve  *ValidationError
+		ke  *KeyError
+	)
+	switch {
+	case errors.As(err, &de):
+		key = de.Key
+	case errors.As(err, &ve):
+		key = ve.Key
+	case errors.As(err, &ke):
+		key = ke.Key
+	default:
+		return err
+	}
+
+	if pos, ok := cfg.position(key); ok {
+		return &PositionError{Position: pos, Err: err}
+	}
+	return err
+}
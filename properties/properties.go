@@ -0,0 +1,59 @@
+// Package properties decodes Java properties config documents for
+// configwise. It is a separate package so that only applications reading
+// properties files link the properties library:
+//
+//	c, err := configwise.NewConfigurer(properties.Option())
+package properties
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/magiconair/properties"
+
+	"github.com/gowool/configwise"
+)
+
+// Option registers Decode for config documents of type properties, detected
+// by the .properties extension, and of its aliases props and prop.
+func Option() configwise.Option {
+	return configwise.WithDecoder("properties", Decode)
+}
+
+// Decode parses a Java properties document into a configuration tree,
+// nesting dotted keys like server.http.port. References like ${key} are left
+// to the expansion of config values. Values are kept as strings.
+func Decode(data []byte) (map[string]interface{}, error) {
+	loader := &properties.Loader{Encoding: properties.UTF8, DisableExpansion: true}
+	props, err := loader.LoadBytes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := props.Keys()
+	sort.Strings(keys)
+
+	tree := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		path := strings.Split(key, ".")
+		for i := len(path) - 1; i > 0; i-- {
+			parent := strings.Join(path[:i], ".")
+			if _, ok := props.Get(parent); ok {
+				return nil, fmt.Errorf("%s: key is both a value and a section", parent)
+			}
+		}
+		section := tree
+		for _, name := range path[:len(path)-1] {
+			next, ok := section[name].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				section[name] = next
+			}
+			section = next
+		}
+		value, _ := props.Get(key)
+		section[path[len(path)-1]] = value
+	}
+	return tree, nil
+}
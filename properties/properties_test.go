@@ -0,0 +1,159 @@
+package properties
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gowool/configwise"
+)
+
+type decodeTest struct {
+	name string
+	doc  string
+	want map[string]interface{}
+	err  string
+}
+
+func runDecodeTests(t *testing.T, tests []decodeTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode([]byte(tt.doc))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error is %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tree is %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeparators(t *testing.T) {
+	runDecodeTests(t, []decodeTest{
+		{
+			name: "equals, colon and whitespace",
+			doc:  "a = 1\nb: 2\nc 3\nd=4\n",
+			want: map[string]interface{}{"a": "1", "b": "2", "c": "3", "d": "4"},
+		},
+		{
+			name: "first separator",
+			doc:  "url = http://localhost:8080/?a=b\n",
+			want: map[string]interface{}{"url": "http://localhost:8080/?a=b"},
+		},
+		{
+			name: "empty value",
+			doc:  "token =\n",
+			want: map[string]interface{}{"token": ""},
+		},
+		{
+			name: "comments",
+			doc:  "# hash\n! bang\n  # indented\nname = app # not a comment\n",
+			want: map[string]interface{}{"name": "app # not a comment"},
+		},
+	})
+}
+
+func TestEscapes(t *testing.T) {
+	runDecodeTests(t, []decodeTest{
+		{
+			name: "continuation lines",
+			doc:  "greeting = hello \\\n    world\n",
+			want: map[string]interface{}{"greeting": "hello world"},
+		},
+		{
+			name: "backslashes",
+			doc:  "path = C:\\\\app\n",
+			want: map[string]interface{}{"path": `C:\app`},
+		},
+		{
+			name: "unicode",
+			doc:  "unicode = \\u00e9t\\u00E9\n",
+			want: map[string]interface{}{"unicode": "été"},
+		},
+		{
+			name: "control characters",
+			doc:  "lines = a\\nb\\tc\n",
+			want: map[string]interface{}{"lines": "a\nb\tc"},
+		},
+		{
+			name: "separators in keys",
+			doc:  "key\\=with\\:separators\\ and\\ spaces = value\n",
+			want: map[string]interface{}{"key=with:separators and spaces": "value"},
+		},
+		{
+			name: "comment markers",
+			doc:  "\\#name = \\!value\n",
+			want: map[string]interface{}{"#name": "!value"},
+		},
+		{
+			name: "references are kept",
+			doc:  "home = /app\nbin = ${home}/bin\n",
+			want: map[string]interface{}{"home": "/app", "bin": "${home}/bin"},
+		},
+		{
+			name: "invalid unicode",
+			doc:  "a = \\u00zz\n",
+			err:  "invalid unicode literal",
+		},
+	})
+}
+
+func TestNesting(t *testing.T) {
+	runDecodeTests(t, []decodeTest{
+		{
+			name: "dotted keys",
+			doc:  "name = app\nserver.host = localhost\nserver.http.port = 8080\n",
+			want: map[string]interface{}{
+				"name": "app",
+				"server": map[string]interface{}{
+					"host": "localhost",
+					"http": map[string]interface{}{"port": "8080"},
+				},
+			},
+		},
+		{
+			name: "value and section",
+			doc:  "server = on\nserver.port = 80\n",
+			err:  "server: key is both a value and a section",
+		},
+		{
+			name: "value and nested section",
+			doc:  "a.b = on\na.b.c.d = 80\n",
+			err:  "a.b: key is both a value and a section",
+		},
+	})
+}
+
+func TestKeyCase(t *testing.T) {
+	doc := []byte("Server.HTTP.Port = 8080\n")
+
+	tree, err := Decode(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tree["Server"]; !ok {
+		t.Fatalf("tree is %#v, want the keys as written", tree)
+	}
+
+	for _, configType := range []string{"properties", "props", "prop"} {
+		t.Run(configType, func(t *testing.T) {
+			c, err := configwise.NewConfigurer(Option(), configwise.WithType(configType), configwise.WithReadInConfig(doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, key := range []string{"server.http.port", "Server.HTTP.Port", "SERVER.HTTP.PORT"} {
+				if got := c.GetInt(key); got != 8080 {
+					t.Errorf("%s is %d, want 8080", key, got)
+				}
+			}
+		})
+	}
+}
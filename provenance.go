@@ -0,0 +1,133 @@
+package configwise
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SourceOverride is the origin of values set at runtime with Overwrite. It is
+// reported by Origin only and takes no part in the precedence of sources.
+const SourceOverride Source = "override"
+
+// Origin returns the kind of source which produced the effective value of the
+// key, or an empty Source if the key is not set. For a section the source with
+// the highest precedence among its keys is returned.
+func (cfg *configurer) Origin(key string) Source {
+	key = cfg.canonicalKey(key)
+	if p := cfg.origin(key); p != nil {
+		return sourceOf(p)
+	}
+	if cfg.overridden(key) {
+		return SourceOverride
+	}
+	return ""
+}
+
+// origin returns the provider of the value of the key. It returns nil for
+// missing and overridden keys.
+func (cfg *configurer) origin(key string) Provider {
+	if cfg.overridden(key) {
+		return nil
+	}
+
+	cfg.mu.RLock()
+	origins := cfg.origins
+	cfg.mu.RUnlock()
+
+	var origin Provider
+	rank := -1
+	for _, p := range providersOf(origins, key) {
+		for i, chained := range cfg.providers {
+			if chained == p && i > rank {
+				origin, rank = p, i
+			}
+		}
+	}
+	if origin != nil {
+		return origin
+	}
+
+	for i := len(cfg.providers) - 1; i >= 0; i-- {
+		if l, ok := cfg.providers[i].(Lookuper); ok {
+			if _, ok := l.Lookup(key); ok {
+				return cfg.providers[i]
+			}
+		}
+	}
+	return nil
+}
+
+// overridden reports whether the key or one of its parents was set with Overwrite.
+func (cfg *configurer) overridden(key string) bool {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+
+	for _, o := range cfg.overrides {
+		if !o.unset && (o.key == key || strings.HasPrefix(key, o.key+keyDelimiter)) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeOrigin returns a comment naming the origin of the key, e.g.
+// "file: config.yaml" or "env: APP_DB_HOST".
+func (cfg *configurer) describeOrigin(key string) string {
+	p := cfg.origin(key)
+	switch {
+	case p != nil:
+		if env, ok := p.(*envProvider); ok {
+			return fmt.Sprintf("%s: %s", SourceEnv, env.variable(key))
+		}
+		return fmt.Sprintf("%s: %s", sourceOf(p), p.Name())
+	case cfg.overridden(key):
+		return string(SourceOverride)
+	default:
+		return ""
+	}
+}
+
+// DumpAnnotated renders the effective configuration as YAML in which every
+// value is commented with its origin. Secrets are stored according to the
+// secret policy.
+func (cfg *configurer) DumpAnnotated() ([]byte, error) {
+	return cfg.dumpAnnotated("")
+}
+
+// dumpAnnotated renders the section stored under root, or all settings for an empty root.
+func (cfg *configurer) dumpAnnotated(root string) ([]byte, error) {
+	var doc yaml.Node
+	if err := doc.Encode(cfg.redactSection(root)); err != nil {
+		return nil, err
+	}
+
+	var annotate func(prefix string, node *yaml.Node)
+	annotate = func(prefix string, node *yaml.Node) {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			key := keyNode.Value
+			if prefix != "" {
+				key = prefix + keyDelimiter + key
+			}
+
+			if valueNode.Kind == yaml.MappingNode && len(valueNode.Content) > 0 {
+				annotate(key, valueNode)
+				continue
+			}
+			comment := cfg.describeOrigin(key)
+			if comment == "" {
+				continue
+			}
+			if valueNode.Kind == yaml.ScalarNode {
+				valueNode.LineComment = comment
+			} else {
+				keyNode.LineComment = comment
+			}
+		}
+	}
+	annotate(root, &doc)
+
+	return yaml.Marshal(&doc)
+}
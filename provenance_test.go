@@ -0,0 +1,53 @@
+package configwise
+
+import "testing"
+
+func TestProvenance(t *testing.T) {
+	t.Setenv("CONFIGWISE_TEST_DB_HOST", "db.internal")
+
+	c, err := NewConfigurer(
+		WithPrefix("configwise_test"),
+		WithConfigMap(map[string]interface{}{"db": map[string]interface{}{"pool": 10}}),
+		WithType("yaml"),
+		WithReadInConfig([]byte("db: {host: localhost, port: 5432}\nname: app")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Overwrite(map[string]interface{}{"name": "other"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key  string
+		want Source
+	}{
+		{key: "db.pool", want: SourceDefault},
+		{key: "db.port", want: SourceFile},
+		{key: "DB.Host", want: SourceEnv},
+		{key: "db", want: SourceEnv},
+		{key: "name", want: SourceOverride},
+		{key: "missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := c.Origin(tt.key); got != tt.want {
+				t.Errorf("origin is %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	got, err := c.DumpAnnotated()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `db:
+    host: db.internal # env: CONFIGWISE_TEST_DB_HOST
+    pool: 10 # default: config map
+    port: 5432 # file: read in config
+name: other # override
+`
+	if string(got) != want {
+		t.Errorf("dump is\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,372 @@
+package configwise
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Priorities of the built-in providers. Providers of the same source kind
+// with a higher priority override values of providers with a lower one.
+const (
+	PriorityDefaults     = 50
+	PriorityConfigMap    = 100
+	PriorityReadInConfig = 200
+	PriorityFS           = 250
+	PriorityFile         = 300
+	PriorityStdin        = 350
+	PriorityEnv          = 400
+	PriorityFlags        = 500
+)
+
+// Provider is a source of configuration values. The configurer composes
+// an ordered chain of providers and merges their trees by priority.
+type Provider interface {
+	// Name identifies the provider in error messages.
+	Name() string
+
+	// Priority defines the position of the provider among providers
+	// of the same source kind. Values of providers with a higher priority win.
+	Priority() int
+
+	// Read returns the configuration tree of the provider.
+	Read() (map[string]interface{}, error)
+
+	// Watch calls notify every time the provider data changes, until ctx is done.
+	// Providers which cannot detect changes return nil immediately.
+	Watch(ctx context.Context, notify func()) error
+}
+
+// Lookuper is implemented by providers which resolve values on demand,
+// such as environment variables, instead of exposing a complete tree.
+type Lookuper interface {
+	// Lookup returns the value for the given key, if the provider has one.
+	Lookup(key string) (interface{}, bool)
+}
+
+// WithProvider appends a custom provider to the chain.
+func WithProvider(provider Provider) Option {
+	return func(c *configurer) {
+		c.custom = append(c.custom, provider)
+	}
+}
+
+type mapProvider struct {
+	name     string
+	priority int
+	source   Source
+	tree     map[string]interface{}
+}
+
+func (p *mapProvider) Name() string {
+	return p.name
+}
+
+func (p *mapProvider) Priority() int {
+	return p.priority
+}
+
+func (p *mapProvider) Source() Source {
+	return p.source
+}
+
+func (p *mapProvider) Read() (map[string]interface{}, error) {
+	return normalizeTree(p.tree), nil
+}
+
+func (p *mapProvider) Watch(context.Context, func()) error {
+	return nil
+}
+
+// decodeFunc parses a config document of the given format, read from the
+// file if it is not empty.
+type decodeFunc func(file, format string, data []byte) (map[string]interface{}, error)
+
+type bytesProvider struct {
+	name       string
+	priority   int
+	source     Source
+	configType string
+	data       []byte
+	decode     decodeFunc
+}
+
+// isYAML reports whether documents of the config type are YAML.
+func isYAML(configType string) bool {
+	return configType == "yaml" || configType == "yml"
+}
+
+func (p *bytesProvider) Name() string {
+	return p.name
+}
+
+func (p *bytesProvider) Priority() int {
+	return p.priority
+}
+
+func (p *bytesProvider) Source() Source {
+	return cmp.Or(p.source, SourceFile)
+}
+
+func (p *bytesProvider) Read() (map[string]interface{}, error) {
+	return p.decode("", p.configType, p.data)
+}
+
+func (p *bytesProvider) Watch(context.Context, func()) error {
+	return nil
+}
+
+func (p *bytesProvider) Positions() map[string]Position {
+	if !isYAML(p.configType) {
+		return nil
+	}
+	return yamlPositions(p.name, p.data)
+}
+
+func (p *bytesProvider) duplicateKeys() []duplicateKey {
+	if !isYAML(p.configType) {
+		return nil
+	}
+	return yamlDuplicates(p.name, p.data)
+}
+
+type fileProvider struct {
+	configName string
+	// configType is empty to detect the type by extension
+	configType string
+	paths      []string
+	decode     decodeFunc
+	// missing files fail the read
+	required bool
+
+	mu sync.Mutex
+	// positions and duplicate keys of the last Read
+	positions  map[string]Position
+	duplicates []duplicateKey
+}
+
+func (p *fileProvider) Name() string {
+	if p.configType != "" {
+		return p.filename()
+	}
+	file, _, _ := p.path()
+	return filepath.Base(file)
+}
+
+func (p *fileProvider) Priority() int {
+	return PriorityFile
+}
+
+func (p *fileProvider) filename() string {
+	return p.configName + "." + cmp.Or(p.configType, defaultConfigType)
+}
+
+// types returns the config types the file is looked up with.
+func (p *fileProvider) types() []string {
+	if p.configType != "" {
+		return []string{p.configType}
+	}
+	return configTypes
+}
+
+// path returns the first existing config file within the search paths and
+// its type. When none exists the candidate of the first search path is returned.
+func (p *fileProvider) path() (string, string, bool) {
+	dirs := p.paths
+	if len(dirs) == 0 {
+		dirs = []string{""}
+	}
+
+	for _, dir := range dirs {
+		for _, configType := range p.types() {
+			file := filepath.Join(dir, p.configName+"."+configType)
+			if _, err := os.Stat(file); err == nil {
+				return file, configType, true
+			}
+		}
+	}
+	return filepath.Join(dirs[0], p.filename()), cmp.Or(p.configType, defaultConfigType), false
+}
+
+func (p *fileProvider) Source() Source {
+	return SourceFile
+}
+
+func (p *fileProvider) Read() (map[string]interface{}, error) {
+	file, configType, ok := p.path()
+	if !ok {
+		return nil, p.notFound()
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, p.notFound()
+		}
+		return nil, err
+	}
+
+	tree, err := p.decode(file, configType, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	if isYAML(configType) {
+		positions, duplicates := yamlPositions(file, data), yamlDuplicates(file, data)
+		p.mu.Lock()
+		p.positions, p.duplicates = positions, duplicates
+		p.mu.Unlock()
+	}
+	return tree, nil
+}
+
+// notFound returns ErrConfigNotFound for required files, listing the
+// directories searched.
+func (p *fileProvider) notFound() error {
+	if !p.required {
+		return nil
+	}
+	name := p.configName + "." + cmp.Or(p.configType, "*")
+	if len(p.paths) == 0 {
+		return fmt.Errorf("%w: %s", ErrConfigNotFound, name)
+	}
+	return fmt.Errorf("%w: %s in %s", ErrConfigNotFound, name, strings.Join(p.paths, ", "))
+}
+
+func (p *fileProvider) Positions() map[string]Position {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.positions
+}
+
+func (p *fileProvider) duplicateKeys() []duplicateKey {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.duplicates
+}
+
+func (p *fileProvider) Watch(ctx context.Context, notify func()) error {
+	file, _, _ := p.path()
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	if err = watcher.Add(filepath.Dir(file)); err != nil {
+		return err
+	}
+
+	const ops = fsnotify.Write | fsnotify.Create | fsnotify.Rename | fsnotify.Remove
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) == file && event.Op&ops != 0 {
+				notify()
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+type envProvider struct {
+	prefix   string
+	replacer *strings.Replacer
+	// variables of dotenv files, if any
+	dotEnv *dotEnv
+}
+
+func (p *envProvider) Name() string {
+	return "env"
+}
+
+func (p *envProvider) Priority() int {
+	return PriorityEnv
+}
+
+func (p *envProvider) Source() Source {
+	return SourceEnv
+}
+
+func (p *envProvider) Read() (map[string]interface{}, error) {
+	if p.dotEnv != nil {
+		return nil, p.dotEnv.load()
+	}
+	return nil, nil
+}
+
+func (p *envProvider) Watch(context.Context, func()) error {
+	return nil
+}
+
+func (p *envProvider) Lookup(key string) (interface{}, bool) {
+	name := p.variable(key)
+	val, ok := os.LookupEnv(name)
+	if !ok && p.dotEnv != nil {
+		val, ok = p.dotEnv.lookup(name)
+	}
+	return val, ok && val != ""
+}
+
+// variable returns the name of the environment variable of the key.
+func (p *envProvider) variable(key string) string {
+	name := key
+	if p.prefix != "" {
+		name = p.prefix + "_" + key
+	}
+	return p.replacer.Replace(strings.ToUpper(name))
+}
+
+type flagsProvider struct {
+	flags []string
+	lang  string
+}
+
+func (p *flagsProvider) Name() string {
+	return "flags"
+}
+
+func (p *flagsProvider) Priority() int {
+	return PriorityFlags
+}
+
+func (p *flagsProvider) Source() Source {
+	return SourceFlags
+}
+
+func (p *flagsProvider) Read() (map[string]interface{}, error) {
+	tree := make(map[string]interface{}, len(p.flags))
+	for _, f := range p.flags {
+		key, val, err := parseFlag(f, p.lang)
+		if err != nil {
+			return nil, err
+		}
+		setPath(tree, strings.ToLower(key), val)
+	}
+	return tree, nil
+}
+
+func (p *flagsProvider) Watch(context.Context, func()) error {
+	return nil
+}
@@ -0,0 +1,128 @@
+package configwise
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// PushVersion is the current version of the push protocol. Every push
+// declares the version it was written for, so that tooling built for a
+// different version is rejected instead of having its changes applied with
+// changed semantics.
+const PushVersion = 1
+
+const (
+	// VersionHeader announces the push protocol version of a request and,
+	// in responses, the versions supported by the server.
+	VersionHeader = "X-Configwise-Version"
+	// RequestIDHeader carries the request ID recorded in the audit log.
+	RequestIDHeader = "X-Request-ID"
+)
+
+// maxPushSize limits the size of a push document.
+const maxPushSize = 1 << 20
+
+// supportedPushVersions lists the protocol versions which can be applied.
+var supportedPushVersions = []int{PushVersion}
+
+// ErrUnsupportedVersion is reported for pushes of an unknown protocol version.
+var ErrUnsupportedVersion = errors.New("unsupported version")
+
+// Push is a runtime mutation sent over HTTP or a message bus.
+type Push struct {
+	// Version is the protocol version the push was written for.
+	Version int `json:"version"`
+	// Values are applied with OverwriteContext.
+	Values map[string]interface{} `json:"values"`
+}
+
+// ApplyPush decodes a push document, checks its version and applies it.
+// Consumers of message bus patches use it like the HTTP handler does.
+func ApplyPush(ctx context.Context, c Configurer, data []byte) error {
+	var push Push
+	if err := json.Unmarshal(data, &push); err != nil {
+		return fmt.Errorf("push: %w", err)
+	}
+	if err := checkPushVersion(push.Version); err != nil {
+		return err
+	}
+	return c.OverwriteContext(ctx, push.Values)
+}
+
+func checkPushVersion(version int) error {
+	for _, v := range supportedPushVersions {
+		if v == version {
+			return nil
+		}
+	}
+	return fmt.Errorf("push: %w %d, supported: %s", ErrUnsupportedVersion, version, pushVersions())
+}
+
+func pushVersions() string {
+	versions := make([]string, len(supportedPushVersions))
+	for i, v := range supportedPushVersions {
+		versions[i] = strconv.Itoa(v)
+	}
+	return strings.Join(versions, ",")
+}
+
+// PushHandler returns an HTTP handler for runtime mutations. A GET request
+// returns the supported protocol versions, a POST request applies a Push
+// document. Requests of an unsupported version fail with 400 Bad Request and
+// the supported versions in the X-Configwise-Version header. Authentication
+// is left to middleware.
+func PushHandler(c Configurer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(VersionHeader, pushVersions())
+
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, map[string]interface{}{"versions": supportedPushVersions})
+			return
+		case http.MethodPost:
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPushSize))
+		if err != nil {
+			writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": err.Error()})
+			return
+		}
+
+		// a version announced in the header must match the document
+		if header := r.Header.Get(VersionHeader); header != "" {
+			var push Push
+			_ = json.Unmarshal(data, &push)
+			if header != strconv.Itoa(push.Version) {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "version header does not match the document"})
+				return
+			}
+		}
+
+		ctx := r.Context()
+		if id := r.Header.Get(RequestIDHeader); id != "" {
+			ctx = ContextWithRequestID(ctx, id)
+		}
+
+		if err = ApplyPush(ctx, c, data); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,209 @@
+package configwise
+
+import (
+	"path"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RedactedValue replaces the values of secrets in dumps.
+const RedactedValue = "******"
+
+// secretTag marks a struct field registered with WithSchema as a secret,
+// e.g. Password string `secret:"true"`.
+const secretTag = "secret"
+
+// SecretPolicy defines how secrets are stored by everything the configurer
+// persists or exports, like the journal and dumps. Resolved plaintext is never stored.
+type SecretPolicy int
+
+const (
+	// SecretMask replaces secrets with RedactedValue.
+	SecretMask SecretPolicy = iota
+	// SecretReference keeps the reference a secret was resolved from, like
+	// vault-transit:<key>:<ciphertext> or file:<path>. Secrets without a
+	// reference, e.g. from secret directories, are masked.
+	SecretReference
+)
+
+// WithSecretPolicy sets the policy for storing secrets, SecretMask by default.
+func WithSecretPolicy(policy SecretPolicy) Option {
+	return func(c *configurer) {
+		c.secretPolicy = policy
+	}
+}
+
+// WithSecretKeys marks the keys matching one of the patterns as secrets, in
+// addition to values resolved from secret sources. A pattern is a dot-delimited
+// key in which * matches a single key segment, e.g. "*.password" or "db.dsn".
+func WithSecretKeys(patterns ...string) Option {
+	return func(c *configurer) {
+		for _, pattern := range patterns {
+			c.secretPatterns = append(c.secretPatterns, strings.ToLower(pattern))
+		}
+	}
+}
+
+// isSecretKey reports whether the key or one of its parents is a secret,
+// either because it matches a secret pattern or is part of one of the sets.
+func (cfg *configurer) isSecretKey(key string, secrets ...map[string]string) bool {
+	return isSecret(key, secrets...) || matchKeys(cfg.secretPatterns, key)
+}
+
+// matchKeys reports whether the key or one of its parents matches one of the patterns.
+func matchKeys(patterns []string, key string) bool {
+	for k := key; k != ""; {
+		for _, pattern := range patterns {
+			if matchKey(pattern, k) {
+				return true
+			}
+		}
+		idx := strings.LastIndexAny(k, ".[")
+		if idx == -1 {
+			break
+		}
+		k = k[:idx]
+	}
+	return false
+}
+
+// matchKey matches the key against the pattern segment by segment.
+func matchKey(pattern, key string) bool {
+	ok, _ := path.Match(strings.ReplaceAll(pattern, keyDelimiter, "/"), strings.ReplaceAll(key, keyDelimiter, "/"))
+	return ok
+}
+
+// redact returns a copy of the settings with secrets masked or replaced by
+// their references, according to the secret policy.
+func (cfg *configurer) redact(settings map[string]interface{}, secrets map[string]string) map[string]interface{} {
+	return cfg.redactValue("", settings, cfg.redactSecret(secrets), secrets).(map[string]interface{})
+}
+
+// redactSection returns a redacted copy of the section stored under root, or
+// of all settings for an empty root. Missing sections are empty.
+func (cfg *configurer) redactSection(root string) map[string]interface{} {
+	cfg.mu.RLock()
+	settings, secrets := cfg.settings, cfg.secrets
+	cfg.mu.RUnlock()
+
+	if root == "" {
+		return cfg.redact(settings, secrets)
+	}
+	value, _ := searchPath(settings, root)
+	section, ok := value.(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{}
+	}
+	// a secret section is replaced as a whole
+	redacted, ok := cfg.redactValue(root, section, cfg.redactSecret(secrets), secrets).(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{}
+	}
+	return redacted
+}
+
+// redactSecret returns the replacement of secrets according to the secret policy.
+func (cfg *configurer) redactSecret(secrets map[string]string) func(key string, value interface{}) interface{} {
+	return func(key string, _ interface{}) interface{} {
+		if ref := secrets[key]; ref != "" && cfg.secretPolicy == SecretReference {
+			return ref
+		}
+		return RedactedValue
+	}
+}
+
+// redactValues returns a copy of values passed to a mutation, keyed by full
+// keys, in which secrets are masked. With SecretReference, values which are
+// references themselves are kept.
+func (cfg *configurer) redactValues(values map[string]interface{}, secrets map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		out[key] = cfg.redactValue(key, value, func(_ string, value interface{}) interface{} {
+			if s, ok := value.(string); ok && cfg.secretPolicy == SecretReference && cfg.isReference(s) {
+				return s
+			}
+			return RedactedValue
+		}, secrets)
+	}
+	return out
+}
+
+// isReference reports whether the string is resolved by a secret scheme.
+func (cfg *configurer) isReference(s string) bool {
+	for _, scheme := range cfg.schemes {
+		if scheme.secret && scheme.match(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValue copies the value stored under key and replaces every secret
+// with the result of replace.
+func (cfg *configurer) redactValue(key string, value interface{}, replace func(key string, value interface{}) interface{}, secrets map[string]string) interface{} {
+	var walk func(key string, value interface{}) interface{}
+	walk = func(key string, value interface{}) interface{} {
+		if key != "" && cfg.isSecretKey(key, secrets) {
+			return replace(key, value)
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			m := make(map[string]interface{}, len(v))
+			for k, val := range v {
+				child := k
+				if key != "" {
+					child = key + keyDelimiter + k
+				}
+				m[k] = walk(child, val)
+			}
+			return m
+		case []interface{}:
+			s := make([]interface{}, len(v))
+			for i, val := range v {
+				s[i] = walk(key+"["+strconv.Itoa(i)+"]", val)
+			}
+			return s
+		default:
+			return deepCopy(v)
+		}
+	}
+	return walk(key, value)
+}
+
+// restoreRedacted replaces masked values of a mutation with the current
+// values in the settings, so replaying them does not replace secrets with the
+// placeholder. Masked values of keys missing in the settings are dropped.
+func restoreRedacted(settings, values map[string]interface{}, prefix string) map[string]interface{} {
+	out := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		path := key
+		if prefix != "" {
+			path = prefix + keyDelimiter + key
+		}
+
+		switch v := value.(type) {
+		case string:
+			if v == RedactedValue {
+				current, ok := searchPath(settings, path)
+				if !ok {
+					continue
+				}
+				value = current
+			}
+		case map[string]interface{}:
+			value = restoreRedacted(settings, v, path)
+		}
+		out[key] = value
+	}
+	return out
+}
+
+// DumpRedacted renders the effective configuration as YAML with secrets
+// stored according to the secret policy. The result is safe to print in logs
+// and support bundles.
+func (cfg *configurer) DumpRedacted() ([]byte, error) {
+	return yaml.Marshal(cfg.redactSection(""))
+}
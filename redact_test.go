@@ -0,0 +1,118 @@
+package configwise
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestMatchKeys(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		key      string
+		want     bool
+	}{
+		{patterns: []string{"db.password"}, key: "db.password", want: true},
+		{patterns: []string{"*.password"}, key: "db.password", want: true},
+		{patterns: []string{"*.password"}, key: "db.replica.password", want: false},
+		{patterns: []string{"*.*.password"}, key: "db.replica.password", want: true},
+		{patterns: []string{"*.password"}, key: "password", want: false},
+		{patterns: []string{"db.credentials"}, key: "db.credentials.user", want: true},
+		{patterns: []string{"db.dsns"}, key: "db.dsns[1]", want: true},
+		{patterns: []string{"db.user", "*.token"}, key: "api.token", want: true},
+		{patterns: nil, key: "db.password", want: false},
+	}
+	for _, tt := range tests {
+		if got := matchKeys(tt.patterns, tt.key); got != tt.want {
+			t.Errorf("matchKeys(%q, %q) = %t, want %t", tt.patterns, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestDumpRedacted(t *testing.T) {
+	token := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(token, []byte("t0ken"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	type db struct {
+		User     string
+		Password string `secret:"true"`
+	}
+	config := map[string]interface{}{
+		"db":   map[string]interface{}{"user": "app", "password": "s3cret"},
+		"api":  map[string]interface{}{"token": "file:" + token, "url": "https://example.com"},
+		"keys": []interface{}{"k1", "k2"},
+	}
+
+	tests := []struct {
+		name    string
+		options []Option
+		want    map[string]interface{}
+	}{
+		{
+			name:    "secret keys",
+			options: []Option{WithSecretKeys("DB.Password", "keys")},
+			want: map[string]interface{}{
+				"db":   map[string]interface{}{"user": "app", "password": RedactedValue},
+				"api":  map[string]interface{}{"token": "file:" + token, "url": "https://example.com"},
+				"keys": RedactedValue,
+			},
+		},
+		{
+			name:    "secret tags",
+			options: []Option{WithSchema("db", db{})},
+			want: map[string]interface{}{
+				"db":   map[string]interface{}{"user": "app", "password": RedactedValue},
+				"api":  map[string]interface{}{"token": "file:" + token, "url": "https://example.com"},
+				"keys": []interface{}{"k1", "k2"},
+			},
+		},
+		{
+			name:    "masked secret values",
+			options: []Option{WithFileValues(true)},
+			want: map[string]interface{}{
+				"db":   map[string]interface{}{"user": "app", "password": "s3cret"},
+				"api":  map[string]interface{}{"token": RedactedValue, "url": "https://example.com"},
+				"keys": []interface{}{"k1", "k2"},
+			},
+		},
+		{
+			name:    "secret references",
+			options: []Option{WithFileValues(true), WithSecretKeys("db.password"), WithSecretPolicy(SecretReference)},
+			want: map[string]interface{}{
+				"db":   map[string]interface{}{"user": "app", "password": RedactedValue},
+				"api":  map[string]interface{}{"token": "file:" + token, "url": "https://example.com"},
+				"keys": []interface{}{"k1", "k2"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewConfigurer(append(tt.options, WithConfigMap(config))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dump, err := c.DumpRedacted()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got map[string]interface{}
+			if err := yaml.Unmarshal(dump, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dump is %v, want %v", got, tt.want)
+			}
+
+			// redaction does not touch the values
+			if c.GetString("db.password") != "s3cret" {
+				t.Errorf("db.password is %q after the dump", c.GetString("db.password"))
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package configwise
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GenerateReference returns the reference documentation of the config struct
+// as a Markdown table listing every key with its type, default, whether it is
+// required and its description from the doc and enum tags. Fields of lists of
+// sections are listed below the list, e.g. "backends[].host". Defaults of
+// secrets are masked. Docs stay in sync when they are generated, e.g. by a
+// small program run with go:generate which writes the result to CONFIG.md.
+func GenerateReference(out interface{}) ([]byte, error) {
+	t := reflect.TypeOf(out)
+	if t == nil {
+		return nil, errors.New("reference: nil type")
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("reference: %s is not a struct", t)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("| Key | Type | Default | Required | Description |\n")
+	buf.WriteString("| --- | --- | --- | --- | --- |\n")
+	referenceRows(&buf, t, "", make(map[reflect.Type]bool))
+	return buf.Bytes(), nil
+}
+
+// referenceRows writes the rows of the fields of the struct type. Types already
+// on the path are skipped, so recursive types end.
+func referenceRows(buf *bytes.Buffer, t reflect.Type, prefix string, seen map[reflect.Type]bool) {
+	seen[t] = true
+	defer delete(seen, t)
+
+	walkStruct(t, prefix, func(field schemaField) bool {
+		var def string
+		if value, ok := field.Field.Tag.Lookup(defaultTag); ok {
+			def = "`" + value + "`"
+			if secret, _ := strconv.ParseBool(field.Field.Tag.Get(secretTag)); secret {
+				def = "`" + RedactedValue + "`"
+			}
+		}
+		required := "no"
+		if isRequired(field.Field) || hasRule(field.Field, "required") {
+			required = "yes"
+		}
+
+		description := strings.Join(strings.Fields(field.Field.Tag.Get(docTag)), " ")
+		if values, ok := enumValues(field.Field); ok {
+			description = strings.TrimSpace(description + " One of: " + strings.Join(values, ", ") + ".")
+		}
+
+		fmt.Fprintf(buf, "| `%s` | %s | %s | %s | %s |\n",
+			field.Path, typeName(field.Type), markdownCell(def), required, markdownCell(description))
+
+		elem := indirectType(field.Type)
+		if elem.Kind() == reflect.Slice || elem.Kind() == reflect.Array {
+			if elem = indirectType(elem.Elem()); isStruct(elem) && !seen[elem] {
+				referenceRows(buf, elem, field.Path+"[]", seen)
+			}
+			return false
+		}
+		return isStruct(elem) && !seen[elem]
+	})
+}
+
+// typeName returns the name of the type of values decoded into t, as shown
+// in the reference documentation.
+func typeName(t reflect.Type) string {
+	t = indirectType(t)
+
+	switch {
+	case t == reflect.TypeOf(time.Duration(0)):
+		return "duration"
+	case t == reflect.TypeOf(time.Time{}):
+		return "time"
+	case t == reflect.TypeOf(net.IPNet{}), reflect.PointerTo(t).Implements(textUnmarshalerType):
+		return "string"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "uint"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string"
+		}
+		return "list of " + typeName(t.Elem())
+	case reflect.Map:
+		return "map of " + typeName(t.Elem())
+	case reflect.Struct:
+		return "section"
+	default:
+		return "any"
+	}
+}
+
+// markdownCell escapes the text for a cell of a Markdown table.
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
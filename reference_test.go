@@ -0,0 +1,62 @@
+package configwise
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type referenceBackend struct {
+	Host   string `required:"true" doc:"host of the backend"`
+	Weight int    `default:"1"`
+}
+
+type referenceConfig struct {
+	Name     string             `default:"app" doc:"name of the service"`
+	Mode     string             `enum:"dev,prod" doc:"run mode"`
+	Timeout  time.Duration      `cfg:"request-timeout" default:"5s"`
+	Password string             `default:"changeme" secret:"true"`
+	Backends []referenceBackend `doc:"upstream | servers"`
+	Labels   map[string]string
+	Next     *referenceConfig
+}
+
+func TestGenerateReference(t *testing.T) {
+	got, err := GenerateReference(&referenceConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "| Key | Type | Default | Required | Description |\n" +
+		"| --- | --- | --- | --- | --- |\n" +
+		"| `name` | string | `app` | no | name of the service |\n" +
+		"| `mode` | string |  | no | run mode One of: dev, prod. |\n" +
+		"| `request-timeout` | duration | `5s` | no |  |\n" +
+		"| `password` | string | `******` | no |  |\n" +
+		"| `backends` | list of section |  | no | upstream \\| servers |\n" +
+		"| `backends[].host` | string |  | yes | host of the backend |\n" +
+		"| `backends[].weight` | int | `1` | no |  |\n" +
+		"| `labels` | map of string |  | no |  |\n" +
+		"| `next` | section |  | no |  |\n"
+	if string(got) != want {
+		t.Errorf("reference is\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGenerateReferenceErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		out  interface{}
+		err  string
+	}{
+		{name: "nil", err: "reference: nil type"},
+		{name: "no struct", out: map[string]interface{}{}, err: "reference: map[string]interface {} is not a struct"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateReference(tt.out)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error is %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package configwise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnreachable is reported when a remote source could not be fetched.
+	ErrUnreachable = errors.New("source unreachable")
+
+	// ErrInvalidContent is reported when a remote source was fetched but could not be parsed.
+	ErrInvalidContent = errors.New("invalid content")
+)
+
+// RemoteError describes a failed load of a remote source. It matches
+// ErrUnreachable or ErrInvalidContent with errors.Is.
+type RemoteError struct {
+	URL      string
+	Attempts int
+	Err      error
+
+	invalid bool
+}
+
+func (e *RemoteError) Error() string {
+	kind := ErrUnreachable
+	if e.invalid {
+		kind = ErrInvalidContent
+	}
+	return fmt.Sprintf("%s after %d attempt(s): %s", kind, e.Attempts, e.Err)
+}
+
+func (e *RemoteError) Unwrap() error {
+	return e.Err
+}
+
+func (e *RemoteError) Is(target error) bool {
+	if e.invalid {
+		return target == ErrInvalidContent
+	}
+	return target == ErrUnreachable
+}
+
+// Backoff returns the delay before the given retry attempt, starting at 1.
+type Backoff func(attempt int) time.Duration
+
+// ConstantBackoff waits the same delay before every retry.
+func ConstantBackoff(delay time.Duration) Backoff {
+	return func(int) time.Duration {
+		return delay
+	}
+}
+
+// ExponentialBackoff doubles the delay with every retry, up to limit.
+func ExponentialBackoff(base, limit time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		delay := base
+		for i := 1; i < attempt && delay < limit; i++ {
+			delay *= 2
+		}
+		if delay > limit {
+			delay = limit
+		}
+		return delay
+	}
+}
+
+// RetryPolicy controls how often and how long a remote source is tried.
+type RetryPolicy struct {
+	// Attempts is the total number of attempts, values below 1 mean one attempt.
+	Attempts int
+	// Backoff returns the delay between attempts.
+	Backoff Backoff
+	// Timeout limits every single attempt, zero means no limit.
+	Timeout time.Duration
+	// Deadline limits the total duration of all attempts including the
+	// delays between them, zero means no limit.
+	Deadline time.Duration
+}
+
+func (p RetryPolicy) attempts() int {
+	if p.Attempts < 1 {
+		return 1
+	}
+	return p.Attempts
+}
+
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	if p.Backoff == nil {
+		return 0
+	}
+	return p.Backoff(attempt)
+}
+
+// retry waits before the next attempt after the given failed attempt of a
+// series started at start. It returns false if no attempt is left or the
+// next one would start after the deadline.
+func (p RetryPolicy) retry(attempt int, start time.Time) bool {
+	if attempt >= p.attempts() {
+		return false
+	}
+	delay := p.delay(attempt)
+	if p.Deadline > 0 && time.Since(start)+delay >= p.Deadline {
+		return false
+	}
+	time.Sleep(delay)
+	return true
+}
+
+// WithInitialLoadRetry retries the initial load of NewConfigurer, so transient
+// failures like a remote source answering 503 during orchestration races do not
+// fail the startup immediately. The Timeout of the policy is not applied, as
+// provider reads cannot be cancelled; limit remote fetches with WithTimeout.
+func WithInitialLoadRetry(policy RetryPolicy) Option {
+	return func(c *configurer) {
+		c.loadRetry = policy
+	}
+}
+
+// WithRefreshInterval re-fetches remote sources with the given interval while
+// the configurer is watched. Changes flow through the same pipeline as file reloads.
+func WithRefreshInterval(interval time.Duration) Option {
+	return func(c *configurer) {
+		c.refreshInterval = interval
+	}
+}
+
+func (cfg *configurer) hasRemote() bool {
+	for _, p := range cfg.providers {
+		if sourceOf(p) == SourceRemote {
+			return true
+		}
+	}
+	return false
+}
+
+// refresh calls notify with the given interval until ctx is done.
+func refresh(ctx context.Context, interval time.Duration, notify func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			notify()
+		}
+	}
+}
+
+// RemoteOption configures a remote provider.
+type RemoteOption func(*remoteProvider)
+
+// WithRetry retries failed fetches of the remote source.
+func WithRetry(attempts int, backoff Backoff) RemoteOption {
+	return func(p *remoteProvider) {
+		p.retry.Attempts = attempts
+		p.retry.Backoff = backoff
+	}
+}
+
+// WithTimeout limits the duration of every fetch attempt.
+func WithTimeout(timeout time.Duration) RemoteOption {
+	return func(p *remoteProvider) {
+		p.retry.Timeout = timeout
+	}
+}
+
+// WithHTTPClient replaces the default HTTP client.
+func WithHTTPClient(client *http.Client) RemoteOption {
+	return func(p *remoteProvider) {
+		p.client = client
+	}
+}
+
+// WithHeader adds a header to every request, e.g. for authorization.
+func WithHeader(key, value string) RemoteOption {
+	return func(p *remoteProvider) {
+		p.header.Add(key, value)
+	}
+}
+
+// WithRemoteType sets the format of the remote document. By default it is
+// detected from the URL extension or the Content-Type of the response.
+func WithRemoteType(configType string) RemoteOption {
+	return func(p *remoteProvider) {
+		p.configType = configType
+	}
+}
+
+// WithRemotePriority sets the priority of the provider among remote sources.
+func WithRemotePriority(priority int) RemoteOption {
+	return func(p *remoteProvider) {
+		p.priority = priority
+	}
+}
+
+type remoteProvider struct {
+	url        string
+	configType string
+	priority   int
+	header     http.Header
+	client     *http.Client
+	retry      RetryPolicy
+	// decoders of the configurer the provider is passed to
+	decoders map[string]Decoder
+}
+
+// NewURLProvider returns a provider which fetches the configuration with
+// an HTTP GET request. The document is decoded with the decoders registered
+// with WithDecoder on the configurer the provider is passed to.
+func NewURLProvider(rawURL string, options ...RemoteOption) Provider {
+	p := &remoteProvider{
+		url:    rawURL,
+		header: make(http.Header),
+		client: http.DefaultClient,
+	}
+	for _, opt := range options {
+		opt(p)
+	}
+	return p
+}
+
+func (p *remoteProvider) Name() string {
+	return p.url
+}
+
+func (p *remoteProvider) Priority() int {
+	return p.priority
+}
+
+func (p *remoteProvider) Source() Source {
+	return SourceRemote
+}
+
+func (p *remoteProvider) Read() (map[string]interface{}, error) {
+	var err error
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		var (
+			data        []byte
+			contentType string
+			retry       bool
+		)
+		if data, contentType, retry, err = p.fetch(); err != nil {
+			if !retry || !p.retry.retry(attempt, start) {
+				return nil, &RemoteError{URL: p.url, Attempts: attempt, Err: err}
+			}
+			continue
+		}
+
+		tree, err := decodeConfig(p.decoders, p.format(contentType), data)
+		if err != nil {
+			return nil, &RemoteError{URL: p.url, Attempts: attempt, Err: err, invalid: true}
+		}
+		return tree, nil
+	}
+}
+
+// fetch downloads the document and reports whether a failure is worth a retry.
+func (p *remoteProvider) fetch() ([]byte, string, bool, error) {
+	ctx := context.Background()
+	if p.retry.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.retry.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, "", false, err
+	}
+	for key, values := range p.header {
+		req.Header[key] = values
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, "", true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return nil, "", retry, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", true, err
+	}
+	return data, resp.Header.Get("Content-Type"), false, nil
+}
+
+func (p *remoteProvider) format(contentType string) string {
+	if p.configType != "" {
+		return p.configType
+	}
+
+	if u, err := url.Parse(p.url); err == nil {
+		if ext := path.Ext(u.Path); ext != "" {
+			return ext[1:]
+		}
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch {
+		case strings.HasSuffix(mediaType, "json"):
+			return "json"
+		case strings.HasSuffix(mediaType, "toml"):
+			return "toml"
+		}
+	}
+	return "yaml"
+}
+
+func (p *remoteProvider) Watch(context.Context, func()) error {
+	return nil
+}
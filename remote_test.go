@@ -0,0 +1,239 @@
+package configwise
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// remoteServer answers the requests with the statuses in order, repeating
+// the last one, and serves the body with the content type when it answers 200.
+func remoteServer(t *testing.T, contentType, body string, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		status := statuses[min(n, len(statuses))-1]
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff Backoff
+		want    []time.Duration
+	}{
+		{
+			name:    "constant",
+			backoff: ConstantBackoff(time.Second),
+			want:    []time.Duration{time.Second, time.Second, time.Second},
+		},
+		{
+			name:    "exponential",
+			backoff: ExponentialBackoff(100*time.Millisecond, time.Second),
+			want:    []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second},
+		},
+		{
+			name:    "base above the limit",
+			backoff: ExponentialBackoff(time.Minute, time.Second),
+			want:    []time.Duration{time.Second, time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, want := range tt.want {
+				if got := tt.backoff(i + 1); got != want {
+					t.Errorf("delay before attempt %d is %v, want %v", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestURLProviderRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		body     string
+		options  []RemoteOption
+		requests int32
+		err      error
+		attempts int
+	}{
+		{
+			name:     "success",
+			statuses: []int{http.StatusOK},
+			requests: 1,
+		},
+		{
+			name:     "transient failures",
+			statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			options:  []RemoteOption{WithRetry(3, ConstantBackoff(time.Millisecond))},
+			requests: 3,
+		},
+		{
+			name:     "too many requests",
+			statuses: []int{http.StatusTooManyRequests, http.StatusOK},
+			options:  []RemoteOption{WithRetry(3, nil)},
+			requests: 2,
+		},
+		{
+			name:     "attempts exhausted",
+			statuses: []int{http.StatusServiceUnavailable},
+			options:  []RemoteOption{WithRetry(3, ConstantBackoff(time.Millisecond))},
+			requests: 3,
+			err:      ErrUnreachable,
+			attempts: 3,
+		},
+		{
+			name:     "without retry",
+			statuses: []int{http.StatusServiceUnavailable, http.StatusOK},
+			requests: 1,
+			err:      ErrUnreachable,
+			attempts: 1,
+		},
+		{
+			name:     "client error",
+			statuses: []int{http.StatusNotFound, http.StatusOK},
+			options:  []RemoteOption{WithRetry(3, nil)},
+			requests: 1,
+			err:      ErrUnreachable,
+			attempts: 1,
+		},
+		{
+			name:     "invalid content",
+			statuses: []int{http.StatusOK},
+			body:     `{"port": `,
+			options:  []RemoteOption{WithRetry(3, nil)},
+			requests: 1,
+			err:      ErrInvalidContent,
+			attempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := tt.body
+			if body == "" {
+				body = `{"port": 8080}`
+			}
+			server, requests := remoteServer(t, "application/json", body, tt.statuses...)
+
+			tree, err := NewURLProvider(server.URL, tt.options...).Read()
+			if n := requests.Load(); n != tt.requests {
+				t.Errorf("%d requests, want %d", n, tt.requests)
+			}
+			if tt.err != nil {
+				var re *RemoteError
+				if !errors.Is(err, tt.err) || !errors.As(err, &re) {
+					t.Fatalf("error is %v, want %v", err, tt.err)
+				}
+				if re.Attempts != tt.attempts || re.URL != server.URL {
+					t.Errorf("error is %+v, want %d attempts of %s", re, tt.attempts, server.URL)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tree["port"] != float64(8080) {
+				t.Errorf("tree is %v, want port 8080", tree)
+			}
+		})
+	}
+}
+
+func TestURLProviderTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	_, err := NewURLProvider(server.URL, WithTimeout(10*time.Millisecond), WithRetry(2, nil)).Read()
+	if !errors.Is(err, ErrUnreachable) || !strings.Contains(err.Error(), "after 2 attempt(s)") {
+		t.Fatalf("error is %v, want unreachable after 2 attempts", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("fetch took %v, want the attempts cut off by the timeout", elapsed)
+	}
+}
+
+func TestRetryPolicyDeadline(t *testing.T) {
+	policy := RetryPolicy{Attempts: 10, Backoff: ConstantBackoff(time.Hour), Deadline: time.Minute}
+	if policy.retry(1, time.Now()) {
+		t.Error("retry waits beyond the deadline")
+	}
+	if (RetryPolicy{}).retry(1, time.Now()) {
+		t.Error("the zero policy retries")
+	}
+	if !(RetryPolicy{Attempts: 2}).retry(1, time.Now()) || (RetryPolicy{Attempts: 2}).retry(2, time.Now()) {
+		t.Error("a policy of 2 attempts does not retry exactly once")
+	}
+}
+
+func TestURLProviderFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		options     []RemoteOption
+		body        string
+	}{
+		{name: "extension", path: "/config.toml", contentType: "text/plain", body: "port = 8080"},
+		{name: "json content type", path: "/config", contentType: "application/json; charset=utf-8", body: `{"port": 8080}`},
+		{name: "toml content type", path: "/config", contentType: "application/toml", body: "port = 8080"},
+		{name: "yaml by default", path: "/config", contentType: "text/plain", body: "port: 8080"},
+		{name: "remote type", path: "/config.txt", contentType: "text/plain", options: []RemoteOption{WithRemoteType("json")}, body: `{"port": 8080}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := remoteServer(t, tt.contentType, tt.body, http.StatusOK)
+			c, err := NewConfigurer(WithProvider(NewURLProvider(server.URL+tt.path, tt.options...)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.GetInt("port"); got != 8080 {
+				t.Errorf("port is %d, want 8080", got)
+			}
+		})
+	}
+}
+
+func TestInitialLoadRetry(t *testing.T) {
+	server, requests := remoteServer(t, "application/json", `{"port": 8080}`, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK)
+
+	c, err := NewConfigurer(
+		WithProvider(NewURLProvider(server.URL)),
+		WithInitialLoadRetry(RetryPolicy{Attempts: 3, Backoff: ConstantBackoff(time.Millisecond)}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.GetInt("port"); got != 8080 || requests.Load() != 3 {
+		t.Errorf("port is %d after %d requests, want 8080 after 3", got, requests.Load())
+	}
+
+	server, _ = remoteServer(t, "application/json", "", http.StatusServiceUnavailable)
+	_, err = NewConfigurer(
+		WithProvider(NewURLProvider(server.URL)),
+		WithInitialLoadRetry(RetryPolicy{Attempts: 2}),
+	)
+	if !errors.Is(err, ErrUnreachable) || !strings.Contains(err.Error(), "after 2 attempts") {
+		t.Errorf("error is %v, want unreachable after 2 attempts", err)
+	}
+}
@@ -0,0 +1,40 @@
+package configwise
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrRequired is reported for every required field which resolved to its zero value.
+var ErrRequired = errors.New("required field is not set")
+
+// requiredTag marks a field as required, as alternative to the required
+// option of the config tag, e.g. `cfg:"port,required"` or `required:"true"`.
+const requiredTag = "required"
+
+// isRequired reports whether the struct field is marked as required.
+func isRequired(field reflect.StructField) bool {
+	_, opts, _ := strings.Cut(field.Tag.Get(TagName), ",")
+	if strings.Contains(","+opts+",", ",required,") {
+		return true
+	}
+	required, _ := strconv.ParseBool(field.Tag.Get(requiredTag))
+	return required
+}
+
+// checkRequired returns an error listing every required field of the decoded
+// struct which holds its zero value. Keys are reported below prefix, messages
+// in the language.
+func checkRequired(lang, prefix string, out interface{}) error {
+	var errs []error
+	walkValue(reflect.ValueOf(out), prefix, func(path string, field reflect.StructField, value reflect.Value) bool {
+		if isRequired(field) && value.IsZero() {
+			errs = append(errs, &KeyError{Key: path, Err: &messageError{msg: translate(lang, MsgRequired), err: ErrRequired}})
+			return false
+		}
+		return true
+	})
+	return errors.Join(errs...)
+}
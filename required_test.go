@@ -0,0 +1,86 @@
+package configwise
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRequired(t *testing.T) {
+	type tls struct {
+		Cert string `cfg:"cert,required"`
+	}
+	type server struct {
+		Host string `required:"true"`
+		Port int    `cfg:"port,required"`
+		Name string `required:"false"`
+		TLS  *tls
+	}
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		key    string
+		err    []string
+	}{
+		{
+			name:   "set",
+			config: map[string]interface{}{"server": map[string]interface{}{"host": "localhost", "port": 80}},
+			key:    "server",
+		},
+		{
+			name:   "missing",
+			config: map[string]interface{}{"server": map[string]interface{}{"name": "app"}},
+			key:    "server",
+			err:    []string{"server.host: required field is not set", "server.port: required field is not set"},
+		},
+		{
+			name:   "zero value",
+			config: map[string]interface{}{"server": map[string]interface{}{"host": "localhost", "port": 0}},
+			key:    "server",
+			err:    []string{"server.port: required field is not set"},
+		},
+		{
+			name:   "nested",
+			config: map[string]interface{}{"server": map[string]interface{}{"host": "localhost", "port": 80, "tls": map[string]interface{}{}}},
+			key:    "server",
+			err:    []string{"server.tls.cert: required field is not set"},
+		},
+		{
+			name:   "whole config",
+			config: map[string]interface{}{"server": map[string]interface{}{"host": "localhost"}},
+			err:    []string{"server.port: required field is not set"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewConfigurer(WithConfigMap(tt.config))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.key != "" {
+				err = c.UnmarshalKey(tt.key, new(server))
+			} else {
+				err = c.Unmarshal(&struct{ Server server }{})
+			}
+			if len(tt.err) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrRequired) {
+				t.Fatalf("error is %v, want ErrRequired", err)
+			}
+			for _, want := range tt.err {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error is %v, want %q", err, want)
+				}
+			}
+			if n := strings.Count(err.Error(), "required field is not set"); n != len(tt.err) {
+				t.Errorf("error reports %d fields, want %d: %v", n, len(tt.err), err)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package configwise
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUndefinedVariable is returned by Expand for references without a value and without a default.
+var ErrUndefinedVariable = errors.New("undefined variable")
+
+// ErrUnknownResolver is returned by Expand for references of a prefix no resolver handles.
+var ErrUnknownResolver = errors.New("unknown resolver")
+
+// Resolver resolves the references of one kind within expanded strings,
+// written as ${<prefix>:<name>}, e.g. ${file:/run/secrets/token}.
+type Resolver interface {
+	// Prefix returns the prefix of the references the resolver handles.
+	Prefix() string
+
+	// Resolve returns the value of the reference. Empty values are treated as
+	// undefined, so the default of the reference applies.
+	Resolve(name string) (value string, err error)
+}
+
+type resolver struct {
+	prefix  string
+	resolve func(name string) (string, error)
+}
+
+func (r resolver) Prefix() string {
+	return r.prefix
+}
+
+func (r resolver) Resolve(name string) (string, error) {
+	return r.resolve(name)
+}
+
+// NewResolver returns a Resolver for references of the prefix.
+func NewResolver(prefix string, resolve func(name string) (string, error)) Resolver {
+	return resolver{prefix: prefix, resolve: resolve}
+}
+
+// envResolverPrefix is the prefix of environment variables, which are also
+// referenced without prefix, e.g. ${HOME}.
+const envResolverPrefix = "env"
+
+// EnvResolver resolves environment variables, referenced as ${NAME} or ${env:NAME}.
+func EnvResolver() Resolver {
+	return NewResolver(envResolverPrefix, func(name string) (string, error) {
+		return os.Getenv(name), nil
+	})
+}
+
+// VarsResolver resolves the variables, referenced as ${var:name}, like WithVars.
+func VarsResolver(vars map[string]string) Resolver {
+	return NewResolver(strings.TrimSuffix(varPrefix, ":"), func(name string) (string, error) {
+		return vars[name], nil
+	})
+}
+
+// FileResolver resolves the content of files, referenced as ${file:<path>}.
+// A single trailing line break is removed.
+func FileResolver() Resolver {
+	return NewResolver("file", func(name string) (string, error) {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return "", err
+		}
+		return trimNewline(string(data)), nil
+	})
+}
+
+// SecretResolver resolves the secrets stored as files of the directory,
+// usually /run/secrets, referenced as ${secret:<name>}.
+func SecretResolver(dir string) Resolver {
+	return NewResolver("secret", func(name string) (string, error) {
+		if name != filepath.Base(name) {
+			return "", fmt.Errorf("invalid secret name %q", name)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		return trimNewline(string(data)), nil
+	})
+}
+
+// ConfigResolver resolves the values of the configurer, referenced as ${cfg:<key>}.
+func ConfigResolver(c Configurer) Resolver {
+	return NewResolver("cfg", func(name string) (string, error) {
+		value := c.Get(name)
+		if value == nil {
+			return "", nil
+		}
+		return fmt.Sprint(value), nil
+	})
+}
+
+// WithResolvers adds resolvers to the interpolation of config values, next
+// to environment variables and the variables of WithVars, e.g.
+// WithResolvers(configwise.FileResolver()) expands ${file:/etc/app/token}.
+func WithResolvers(resolvers ...Resolver) Option {
+	return func(c *configurer) {
+		c.resolvers = append(c.resolvers, resolvers...)
+	}
+}
+
+// WithLazyExpansion expands the references of config values when they are
+// read by Get, Unmarshal and the other getters instead of when the config is
+// loaded, which saves the walk over every value of large configs on startup
+// and makes changes of environment variables visible without a reload. Dumps,
+// exports and Diff show the references instead of their values. As the
+// values are not expanded on load, undefined references are not reported as
+// warnings, and the schema and the references of value schemes like
+// vault-transit: see the values as written.
+func WithLazyExpansion() Option {
+	return func(c *configurer) {
+		c.lazyExpansion = true
+	}
+}
+
+// Expand expands the references of the string with the same semantics as
+// config values: ${NAME} and ${env:NAME} refer to environment variables,
+// ${<prefix>:<name>} to the resolvers of the prefix, asked in order, and
+// ${<ref>:-<default>} falls back to the default when the reference is
+// undefined or empty. Without resolvers environment variables are expanded.
+// Undefined references without default and unknown prefixes are reported as errors.
+func Expand(s string, resolvers ...Resolver) (string, error) {
+	if len(resolvers) == 0 {
+		resolvers = []Resolver{EnvResolver()}
+	}
+
+	var errs []error
+	expanded, err := expand(s, resolvers, func(name string, known bool) {
+		if known {
+			errs = append(errs, fmt.Errorf("%w %s", ErrUndefinedVariable, name))
+		} else {
+			errs = append(errs, fmt.Errorf("%w %s", ErrUnknownResolver, name))
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(errs) > 0 {
+		return "", errors.Join(errs...)
+	}
+	return expanded, nil
+}
+
+// expand expands the references of s with the resolvers. References without
+// value and without default are passed to missing, along with whether a
+// resolver handles them, and replaced by an empty string.
+func expand(s string, resolvers []Resolver, missing func(name string, known bool)) (string, error) {
+	var errs []error
+	expanded := expandVal(s, func(name string, hasDefault bool) string {
+		prefix, ref, ok := strings.Cut(name, ":")
+		if !ok {
+			prefix, ref = envResolverPrefix, name
+		}
+
+		var value string
+		known := false
+		for _, r := range resolvers {
+			if r.Prefix() != prefix {
+				continue
+			}
+			known = true
+			v, err := r.Resolve(ref)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				return ""
+			}
+			// the next resolver of the prefix is asked for undefined values
+			if v != "" {
+				value = v
+				break
+			}
+		}
+
+		if value == "" && !hasDefault {
+			missing(name, known)
+		}
+		return value
+	})
+	return expanded, errors.Join(errs...)
+}
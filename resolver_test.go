@@ -0,0 +1,127 @@
+package configwise
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	t.Setenv("CONFIGWISE_TEST_HOST", "db")
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "token"), []byte("t0ken\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	failing := NewResolver("fail", func(string) (string, error) {
+		return "", errors.New("broken")
+	})
+
+	tests := []struct {
+		name      string
+		s         string
+		resolvers []Resolver
+		want      string
+		err       string
+	}{
+		{
+			name: "environment",
+			s:    "tcp://${CONFIGWISE_TEST_HOST}:${env:CONFIGWISE_TEST_PORT:-5432}",
+			want: "tcp://db:5432",
+		},
+		{
+			name: "plain text",
+			s:    "no references, $",
+			want: "no references, $",
+		},
+		{
+			name:      "resolvers in order",
+			s:         "${var:a} ${var:b}",
+			resolvers: []Resolver{VarsResolver(map[string]string{"a": "1"}), VarsResolver(map[string]string{"a": "2", "b": "3"})},
+			want:      "1 3",
+		},
+		{
+			name:      "file",
+			s:         "${file:" + filepath.Join(dir, "token") + "}",
+			resolvers: []Resolver{FileResolver()},
+			want:      "t0ken",
+		},
+		{
+			name:      "secret",
+			s:         "${secret:token}/${secret:missing:-none}",
+			resolvers: []Resolver{SecretResolver(dir)},
+			want:      "t0ken/none",
+		},
+		{
+			name: "undefined",
+			s:    "${CONFIGWISE_TEST_MISSING}",
+			err:  "undefined variable CONFIGWISE_TEST_MISSING",
+		},
+		{
+			name: "undefined after a default",
+			s:    "${CONFIGWISE_TEST_MISSING:-x} ${CONFIGWISE_TEST_MISSING}",
+			err:  "undefined variable CONFIGWISE_TEST_MISSING",
+		},
+		{
+			name: "unknown resolver",
+			s:    "${cfg:db.host}",
+			err:  "unknown resolver cfg:db.host",
+		},
+		{
+			name:      "resolver error",
+			s:         "${fail:x}",
+			resolvers: []Resolver{failing},
+			err:       "fail:x: broken",
+		},
+		{
+			name:      "invalid secret name",
+			s:         "${secret:../token}",
+			resolvers: []Resolver{SecretResolver(dir)},
+			err:       `invalid secret name "../token"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Expand(tt.s, tt.resolvers...)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error is %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("expanded %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUndefinedVariableWarnings(t *testing.T) {
+	c, err := NewConfigurer(WithConfigMap(map[string]interface{}{
+		"a": "${CONFIGWISE_TEST_MISSING:-x} ${CONFIGWISE_TEST_MISSING}",
+		"b": "${CONFIGWISE_TEST_MISSING:-x}",
+		"c": "${cfg:a}",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Warning{
+		{Kind: WarningUndefinedVariable, Key: "a", Name: "CONFIGWISE_TEST_MISSING"},
+		{Kind: WarningUnresolvedReference, Key: "c", Name: "cfg:a"},
+	}
+	got := c.Warnings()
+	if len(got) != len(want) {
+		t.Fatalf("warnings are %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("warning %d is %v, want %v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package configwise
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// docTag documents a config struct field, e.g. Port int `doc:"port to listen on"`.
+const docTag = "doc"
+
+// GenerateSample returns a commented YAML skeleton of the config struct,
+// following the naming rules of the decoder. Every key is set to its default
+// or the zero value of its type and preceded by its doc tag, whether it is
+// required and the values allowed by its enum tag. Secrets are never filled
+// in. The result may be committed as config.example.yaml.
+func GenerateSample(out interface{}) ([]byte, error) {
+	t := reflect.TypeOf(out)
+	if t == nil {
+		return nil, errors.New("sample: nil type")
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("sample: %s is not a struct", t)
+	}
+
+	return yaml.Marshal(sampleStruct(t, make(map[reflect.Type]bool)))
+}
+
+// sampleStruct returns the mapping node of the struct type. Types already on
+// the path are skipped, so recursive types end.
+func sampleStruct(t reflect.Type, seen map[reflect.Type]bool) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	seen[t] = true
+	defer delete(seen, t)
+
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, squash := fieldName(field)
+			if name == "" {
+				continue
+			}
+			if squash {
+				walk(field.Type)
+				continue
+			}
+
+			key := &yaml.Node{Kind: yaml.ScalarNode, Value: name, HeadComment: sampleComment(field)}
+			node.Content = append(node.Content, key, sampleField(field, seen))
+		}
+	}
+	walk(t)
+	return node
+}
+
+// sampleComment returns the comment of a field, built from its doc, required
+// and enum tags.
+func sampleComment(field reflect.StructField) string {
+	var lines []string
+	if doc := strings.TrimSpace(field.Tag.Get(docTag)); doc != "" {
+		lines = append(lines, strings.Split(doc, "\n")...)
+	}
+	if isRequired(field) || hasRule(field, "required") {
+		lines = append(lines, "Required.")
+	}
+	if values, ok := enumValues(field); ok {
+		lines = append(lines, "One of: "+strings.Join(values, ", ")+".")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sampleField returns the value node of a field: its default, or the zero
+// value of its type. Secrets are left empty.
+func sampleField(field reflect.StructField, seen map[reflect.Type]bool) *yaml.Node {
+	if secret, _ := strconv.ParseBool(field.Tag.Get(secretTag)); secret {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: ""}
+	}
+	if value, ok := field.Tag.Lookup(defaultTag); ok {
+		var node yaml.Node
+		if err := node.Encode(defaultValue(field.Type, value)); err == nil {
+			return &node
+		}
+	}
+	return sampleValue(field.Type, seen)
+}
+
+// sampleValue returns the node of the zero value of t. Structs are expanded,
+// lists and maps of structs hold a single sample item.
+func sampleValue(t reflect.Type, seen map[reflect.Type]bool) *yaml.Node {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == reflect.TypeOf(time.Duration(0)):
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: "0s"}
+	case isLeafStruct(t), reflect.PointerTo(t).Implements(textUnmarshalerType):
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: ""}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: "false"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: "0"}
+	case reflect.String:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: ""}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: ""}
+		}
+		node := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		if elem := indirectType(t.Elem()); elem.Kind() == reflect.Struct && !isLeafStruct(elem) && !seen[elem] {
+			node.Style = 0
+			node.Content = append(node.Content, sampleStruct(elem, seen))
+		}
+		return node
+	case reflect.Map:
+		node := &yaml.Node{Kind: yaml.MappingNode, Style: yaml.FlowStyle}
+		if elem := indirectType(t.Elem()); elem.Kind() == reflect.Struct && !isLeafStruct(elem) && !seen[elem] {
+			node.Style = 0
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "name"}, sampleStruct(elem, seen))
+		}
+		return node
+	case reflect.Struct:
+		if seen[t] {
+			return &yaml.Node{Kind: yaml.MappingNode, Style: yaml.FlowStyle}
+		}
+		return sampleStruct(t, seen)
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: ""}
+	}
+}
+
+// indirectType returns the type pointed to by t.
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,68 @@
+package configwise
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type sampleBackend struct {
+	Host   string `required:"true" doc:"host of the backend"`
+	Weight int    `default:"1"`
+}
+
+type sampleConfig struct {
+	Name     string          `default:"app" doc:"name of the service"`
+	Mode     string          `enum:"dev,prod" doc:"run mode"`
+	Timeout  time.Duration   `cfg:"request-timeout" default:"5s"`
+	Password string          `default:"changeme" secret:"true"`
+	Backends []sampleBackend `doc:"upstream servers"`
+	Labels   map[string]string
+	Next     *sampleConfig
+	Ignored  string `cfg:"-"`
+}
+
+func TestGenerateSample(t *testing.T) {
+	got, err := GenerateSample(sampleConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `# name of the service
+name: app
+# run mode
+# One of: dev, prod.
+mode: ""
+request-timeout: 5s
+password: ""
+# upstream servers
+backends:
+    - # host of the backend
+      # Required.
+      host: ""
+      weight: 1
+labels: {}
+next: {}
+`
+	if string(got) != want {
+		t.Errorf("sample is\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGenerateSampleErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		out  interface{}
+		err  string
+	}{
+		{name: "nil", err: "sample: nil type"},
+		{name: "no struct", out: new(int), err: "sample: int is not a struct"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateSample(tt.out)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error is %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
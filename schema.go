@@ -0,0 +1,187 @@
+package configwise
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// schemaField describes a field of a registered config struct.
+type schemaField struct {
+	// Path is the full, lower-cased key of the field.
+	Path  string
+	Type  reflect.Type
+	Field reflect.StructField
+}
+
+// WithSchema registers the Go type of the section stored under key, or of the
+// whole config for an empty key, e.g. WithSchema("http", HTTPConfig{}).
+// Values passed to Overwrite are converted to and validated against the schema.
+// Fields tagged with secret:"true" are treated as secrets.
+func WithSchema(key string, schema interface{}) Option {
+	return func(c *configurer) {
+		t := reflect.TypeOf(schema)
+		for t != nil && t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t == nil {
+			c.optionErrs = append(c.optionErrs, fmt.Errorf("schema %q: nil type", key))
+			return
+		}
+
+		key = strings.ToLower(key)
+		if c.schema == nil {
+			c.schema = make(map[string]reflect.Type)
+		}
+		c.schema[key] = t
+		walkStruct(t, key, func(field schemaField) bool {
+			c.schema[field.Path] = field.Type
+			if secret, _ := strconv.ParseBool(field.Field.Tag.Get(secretTag)); secret {
+				c.secretPatterns = append(c.secretPatterns, field.Path)
+			}
+			return true
+		})
+	}
+}
+
+// fieldName returns the config key of a struct field and whether it is squashed.
+func fieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get(TagName)
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "-" {
+		return "", false
+	}
+
+	squash := field.Anonymous && field.Type.Kind() == reflect.Struct && strings.Contains(","+opts+",", ",squash,")
+	if name == "" {
+		name = field.Name
+	}
+	return strings.ToLower(name), squash
+}
+
+// walkStruct calls fn for every exported field of the struct type, following
+// the naming rules of the decoder. Nested structs are only walked when fn returns true.
+func walkStruct(t reflect.Type, prefix string, fn func(schemaField) bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, squash := fieldName(field)
+		if name == "" {
+			continue
+		}
+		if squash {
+			walkStruct(field.Type, prefix, fn)
+			continue
+		}
+
+		path := name
+		if prefix != "" {
+			path = prefix + keyDelimiter + name
+		}
+
+		if fn(schemaField{Path: path, Type: field.Type, Field: field}) && isStruct(field.Type) {
+			walkStruct(field.Type, path, fn)
+		}
+	}
+}
+
+// walkValue calls fn for every exported field of the struct value, like
+// walkStruct, with the value of the field. Nil pointers are not followed.
+func walkValue(v reflect.Value, prefix string, fn func(path string, field reflect.StructField, value reflect.Value) bool) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct || isLeafStruct(v.Type()) {
+		return
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, squash := fieldName(field)
+		if name == "" {
+			continue
+		}
+		if squash {
+			walkValue(v.Field(i), prefix, fn)
+			continue
+		}
+
+		path := name
+		if prefix != "" {
+			path = prefix + keyDelimiter + name
+		}
+
+		if fn(path, field, v.Field(i)) {
+			walkValue(v.Field(i), path, fn)
+		}
+	}
+}
+
+// isStruct reports whether t is a struct, or a pointer to one, which is decoded
+// from a section rather than a single value.
+func isStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !isLeafStruct(t)
+}
+
+// isLeafStruct reports whether values of the struct type are decoded from a
+// single value, like time.Time or netip.Addr.
+func isLeafStruct(t reflect.Type) bool {
+	switch t {
+	case reflect.TypeOf(time.Time{}), reflect.TypeOf(net.IPNet{}), reflect.TypeOf(netip.Addr{}), reflect.TypeOf(netip.Prefix{}):
+		return true
+	}
+	return false
+}
+
+// coerce converts the value to the type registered for key. Sections are
+// validated, but stored as maps. Values of unknown keys are returned as is.
+func (cfg *configurer) coerce(key string, value interface{}) (interface{}, error) {
+	t, ok := cfg.schema[key]
+	if !ok || value == nil {
+		return value, nil
+	}
+
+	out := reflect.New(t)
+	config := &mapstructure.DecoderConfig{Result: out.Interface()}
+	causes := make(map[string]error)
+	cfg.decoderConfig(config, causes)
+
+	decoder, err := mapstructure.NewDecoder(config)
+	if err != nil {
+		return nil, err
+	}
+	if err = decoder.Decode(value); err != nil {
+		return nil, joinDecodeErrors(decodeErrors(key, err, causes))
+	}
+
+	if isStruct(t) {
+		return value, nil
+	}
+	return out.Elem().Interface(), nil
+}
@@ -0,0 +1,192 @@
+package configwise
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// jsonSchemaDraft is the dialect of the schemas generated by Schema.
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// durationPattern matches durations as accepted by time.ParseDuration.
+const durationPattern = `^[-+]?(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|ms|s|m|h))+$|^0$`
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// Schema returns a JSON Schema of the config struct, following the naming
+// rules of the decoder. Default tags become defaults, enum tags enums and
+// required fields, marked with the required option, the required tag or the
+// required validation rule, are listed as required. The schema may be passed
+// to editors, CI or the jsonschema subpackage to validate configs without
+// running the service.
+func Schema(out interface{}) ([]byte, error) {
+	t := reflect.TypeOf(out)
+	if t == nil {
+		return nil, errors.New("schema: nil type")
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("schema: %s is not a struct", t)
+	}
+
+	schema := typeSchema(t)
+	schema["$schema"] = jsonSchemaDraft
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// typeSchema returns the JSON Schema of values decoded into t.
+func typeSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == reflect.TypeOf(time.Duration(0)):
+		return map[string]interface{}{"type": "string", "pattern": durationPattern}
+	case t == reflect.TypeOf(time.Time{}):
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == reflect.TypeOf(uuid.Nil):
+		return map[string]interface{}{"type": "string", "format": "uuid"}
+	case t == reflect.TypeOf(net.IPNet{}), reflect.PointerTo(t).Implements(textUnmarshalerType):
+		return map[string]interface{}{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string"}
+		}
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		// interfaces and other kinds accept any value
+		return map[string]interface{}{}
+	}
+}
+
+// structSchema returns the object schema of the struct type.
+func structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, squash := fieldName(field)
+			if name == "" {
+				continue
+			}
+			if squash {
+				walk(field.Type)
+				continue
+			}
+
+			property := typeSchema(field.Type)
+			if value, ok := field.Tag.Lookup(defaultTag); ok {
+				property["default"] = defaultValue(field.Type, value)
+			}
+			if values, ok := enumValues(field); ok {
+				enum := make([]interface{}, len(values))
+				for i, value := range values {
+					enum[i] = value
+				}
+				if items, ok := property["items"].(map[string]interface{}); ok {
+					items["enum"] = enum
+				} else {
+					property["enum"] = enum
+				}
+			}
+			if isRequired(field) || hasRule(field, "required") {
+				required = append(required, name)
+			}
+			properties[name] = property
+		}
+	}
+	walk(t)
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// hasRule reports whether the validate tag of the field contains the rule.
+func hasRule(field reflect.StructField, rule string) bool {
+	for _, r := range strings.Split(field.Tag.Get(validateTag), ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultValue converts the default tag to the JSON value of the field type.
+// Values which cannot be converted are kept as strings.
+func defaultValue(t reflect.Type, value string) interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return value
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, err := strconv.ParseInt(value, 0, 64); err == nil {
+			return i
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u, err := strconv.ParseUint(value, 0, 64); err == nil {
+			return u
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return value
+		}
+		items := []interface{}{}
+		if value != "" {
+			for _, item := range strings.Split(value, ",") {
+				items = append(items, defaultValue(t.Elem(), item))
+			}
+		}
+		return items
+	}
+	return value
+}
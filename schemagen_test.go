@@ -0,0 +1,83 @@
+package configwise
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type schemaLimits struct {
+	RPS uint `default:"100"`
+}
+
+type schemaConfig struct {
+	Name    string        `cfg:"service-name" default:"app" required:"true"`
+	Mode    string        `enum:"dev,prod" validate:"required"`
+	Scopes  []string      `enum:"read,write" default:"read"`
+	Timeout time.Duration `default:"5s"`
+	Ratio   float64       `default:"0.5"`
+	Debug   *bool         `default:"true"`
+	Started time.Time
+	Key     []byte
+	Labels  map[string]int
+	Extra   interface{}
+	Limits  schemaLimits
+	Ignored string `cfg:"-"`
+}
+
+func TestSchema(t *testing.T) {
+	got, err := Schema(&schemaConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const want = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "service-name": {"type": "string", "default": "app"},
+    "mode": {"type": "string", "enum": ["dev", "prod"]},
+    "scopes": {"type": "array", "items": {"type": "string", "enum": ["read", "write"]}, "default": ["read"]},
+    "timeout": {"type": "string", "pattern": "` + durationPattern + `", "default": "5s"},
+    "ratio": {"type": "number", "default": 0.5},
+    "debug": {"type": "boolean", "default": true},
+    "started": {"type": "string", "format": "date-time"},
+    "key": {"type": "string"},
+    "labels": {"type": "object", "additionalProperties": {"type": "integer"}},
+    "extra": {},
+    "limits": {"type": "object", "properties": {"rps": {"type": "integer", "minimum": 0, "default": 100}}}
+  },
+  "required": ["service-name", "mode"]
+}`
+	var gotSchema, wantSchema interface{}
+	if err := json.Unmarshal(got, &gotSchema); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(strings.ReplaceAll(want, `\`, `\\`)), &wantSchema); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotSchema, wantSchema) {
+		t.Errorf("schema is\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		out  interface{}
+		err  string
+	}{
+		{name: "nil", err: "schema: nil type"},
+		{name: "no struct", out: "config", err: "schema: string is not a struct"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Schema(tt.out)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error is %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package configwise
+
+// SchemaValidator validates the effective config tree against a schema, like
+// the JSON Schema validator of the jsonschema subpackage:
+//
+//	c, err := configwise.NewConfigurer(jsonschema.WithJSONSchema(data))
+//
+// Validate must not modify the tree, which shares its sections with the
+// configuration.
+type SchemaValidator interface {
+	Validate(tree map[string]interface{}) error
+}
+
+// SchemaValidatorFunc is an adapter to allow the use of ordinary functions as SchemaValidator.
+type SchemaValidatorFunc func(tree map[string]interface{}) error
+
+func (f SchemaValidatorFunc) Validate(tree map[string]interface{}) error {
+	return f(tree)
+}
+
+// WithSchemaValidator validates the effective config tree on load, on reload
+// and on Overwrite, ApplyPatch and Unset, before any value is unmarshalled. A
+// failing reload keeps the previous config, a failing change is not applied.
+// Sections declared with WithFreeForm are not validated. Values of
+// environment variables and flags are validated as the strings they are.
+func WithSchemaValidator(validator SchemaValidator) Option {
+	return func(c *configurer) {
+		c.schemaValidator = validator
+	}
+}
+
+// validateSchema validates the settings with the schema validator, if any.
+func (cfg *configurer) validateSchema(settings map[string]interface{}) error {
+	if cfg.schemaValidator == nil {
+		return nil
+	}
+	return cfg.schemaValidator.Validate(cfg.withoutFreeForm(settings))
+}
@@ -0,0 +1,50 @@
+package configwise
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithSchemaValidator(t *testing.T) {
+	errInvalid := errors.New("invalid")
+	var validated map[string]interface{}
+	validator := SchemaValidatorFunc(func(tree map[string]interface{}) error {
+		validated = tree
+		if _, ok := searchPath(tree, "db.port"); ok {
+			return errInvalid
+		}
+		return nil
+	})
+
+	c, err := NewConfigurer(
+		WithType("yaml"),
+		WithSchemaValidator(validator),
+		WithFreeForm("plugins"),
+		WithReadInConfig([]byte("db: {host: localhost}\nplugins: {a: {b: 1}}")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := validated["plugins"]; ok {
+		t.Errorf("validated tree is %v, want the free-form section left out", validated)
+	}
+	if _, ok := searchPath(validated, "db.host"); !ok {
+		t.Errorf("validated tree is %v, want db.host", validated)
+	}
+
+	if err := c.Overwrite(map[string]interface{}{"db.port": 5432}); !errors.Is(err, errInvalid) {
+		t.Fatalf("error is %v, want the validation error", err)
+	}
+	if c.Get("db.port") != nil {
+		t.Errorf("db.port is %v, want the overwrite rejected", c.Get("db.port"))
+	}
+
+	_, err = NewConfigurer(
+		WithType("yaml"),
+		WithSchemaValidator(validator),
+		WithReadInConfig([]byte("db: {port: 5432}")),
+	)
+	if !errors.Is(err, errInvalid) {
+		t.Fatalf("error is %v, want the validation error", err)
+	}
+}
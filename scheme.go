@@ -0,0 +1,238 @@
+package configwise
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// valueScheme resolves config strings written in a special notation,
+// e.g. encrypted values or references to files.
+type valueScheme struct {
+	name    string
+	match   func(string) bool
+	resolve func(string) (interface{}, error)
+	// secret marks resolved values as sensitive
+	secret bool
+	// lazy schemes are resolved on access instead of on load
+	lazy bool
+}
+
+// resolveSchemes replaces all strings matching a value scheme with their
+// resolved values. It returns the keys of resolved secrets with the strings
+// they were resolved from.
+func (cfg *configurer) resolveSchemes(settings map[string]interface{}) (map[string]interface{}, map[string]string, error) {
+	secrets := make(map[string]string)
+	if len(cfg.schemes) == 0 {
+		return settings, secrets, nil
+	}
+
+	resolved, err := cfg.resolveValue("", settings, secrets)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resolved.(map[string]interface{}), secrets, nil
+}
+
+// resolveValue replaces the strings of the value of the key which match
+// a value scheme, except lazy ones, and records the keys of secrets.
+func (cfg *configurer) resolveValue(path string, value interface{}, secrets map[string]string) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		for _, scheme := range cfg.schemes {
+			if !scheme.match(v) {
+				continue
+			}
+			if scheme.secret {
+				secrets[path] = v
+			}
+			if scheme.lazy {
+				return v, nil
+			}
+			resolved, err := scheme.resolve(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", path, scheme.name, err)
+			}
+			return resolved, nil
+		}
+		return v, nil
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			child := key
+			if path != "" {
+				child = path + keyDelimiter + key
+			}
+			resolved, err := cfg.resolveValue(child, val, secrets)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = resolved
+		}
+		return m, nil
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			resolved, err := cfg.resolveValue(path+"["+strconv.Itoa(i)+"]", val, secrets)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := secrets[path+"["+strconv.Itoa(i)+"]"]; ok {
+				secrets[path] = ""
+			}
+			s[i] = resolved
+		}
+		return s, nil
+	default:
+		return v, nil
+	}
+}
+
+// isSecret reports whether the key or one of its parents is a secret.
+func isSecret(key string, secrets ...map[string]string) bool {
+	for _, set := range secrets {
+		for k := key; k != ""; {
+			if _, ok := set[k]; ok {
+				return true
+			}
+			idx := strings.LastIndexAny(k, ".[")
+			if idx == -1 {
+				break
+			}
+			k = k[:idx]
+		}
+	}
+	return false
+}
+
+// resolveLazy resolves the strings of lazy schemes within the value, after
+// expanding their references with lazy expansion.
+func (cfg *configurer) resolveLazy(value interface{}) (interface{}, error) {
+	var lazy []valueScheme
+	for _, scheme := range cfg.schemes {
+		if scheme.lazy {
+			lazy = append(lazy, scheme)
+		}
+	}
+	if len(lazy) == 0 && !cfg.lazyExpansion {
+		return deepCopy(value), nil
+	}
+
+	var (
+		errs      []error
+		resolvers []Resolver
+	)
+	if cfg.lazyExpansion {
+		resolvers = cfg.resolverChain()
+	}
+	resolved := mapStrings(value, func(s string) string {
+		if cfg.lazyExpansion {
+			// undefined references are empty, like on load
+			expanded, err := expand(s, resolvers, func(string, bool) {})
+			if err != nil {
+				errs = append(errs, err)
+				return s
+			}
+			s = expanded
+		}
+		for _, scheme := range lazy {
+			if !scheme.match(s) {
+				continue
+			}
+			v, err := scheme.resolve(s)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", scheme.name, err))
+				return s
+			}
+			return fmt.Sprint(v)
+		}
+		return s
+	})
+	return resolved, errors.Join(errs...)
+}
+
+// ValueScheme replaces config values of a custom syntax with resolved
+// values, like the file: values of WithFileValues or the enc[...] values
+// decrypted by the age subpackage.
+type ValueScheme struct {
+	// Name prefixes the errors of Resolve, e.g. "db.password: age: ..."
+	Name string
+	// Match reports whether the string is a value of the scheme.
+	Match func(s string) bool
+	// Resolve returns the value the string stands for.
+	Resolve func(s string) (interface{}, error)
+	// Secret marks resolved values as secrets, which are redacted.
+	Secret bool
+}
+
+// WithValueScheme resolves the config values matching the scheme on load and
+// on reload, after variables are expanded. The first matching scheme of
+// WithValueScheme, WithFileValues, WithBase64Values and the like resolves
+// a value.
+func WithValueScheme(scheme ValueScheme) Option {
+	return func(c *configurer) {
+		c.schemes = append(c.schemes, valueScheme{
+			name:    scheme.Name,
+			match:   scheme.Match,
+			resolve: scheme.Resolve,
+			secret:  scheme.Secret,
+		})
+	}
+}
+
+const filePrefix = "file:"
+
+// WithFileValues replaces values written as file:<path>, e.g.
+// tls.key: file:/etc/certs/key.pem, with the content of the file, which is the
+// usual way of mounting secrets. With trim, surrounding whitespace is removed.
+func WithFileValues(trim bool) Option {
+	return func(c *configurer) {
+		c.schemes = append(c.schemes, valueScheme{
+			name:   "file",
+			secret: true,
+			match: func(s string) bool {
+				return strings.HasPrefix(s, filePrefix)
+			},
+			resolve: func(s string) (interface{}, error) {
+				data, err := os.ReadFile(strings.TrimPrefix(s, filePrefix))
+				if err != nil {
+					return nil, err
+				}
+				if trim {
+					return strings.TrimSpace(string(data)), nil
+				}
+				return string(data), nil
+			},
+		})
+	}
+}
+
+const base64Prefix = "base64:"
+
+// WithBase64Values replaces values written as base64:<data> with the decoded
+// data, so binary blobs like keystores can be passed through environment
+// variables. The data may be decoded into string or []byte fields.
+func WithBase64Values() Option {
+	return func(c *configurer) {
+		c.schemes = append(c.schemes, valueScheme{
+			name: "base64",
+			match: func(s string) bool {
+				return strings.HasPrefix(s, base64Prefix)
+			},
+			resolve: func(s string) (interface{}, error) {
+				s = strings.TrimSpace(strings.TrimPrefix(s, base64Prefix))
+				data, err := base64.StdEncoding.DecodeString(s)
+				if err != nil {
+					// accept unpadded data as well
+					if data, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(s, "=")); err != nil {
+						return nil, err
+					}
+				}
+				return data, nil
+			},
+		})
+	}
+}
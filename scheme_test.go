@@ -0,0 +1,200 @@
+package configwise
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFileValues(t *testing.T) {
+	dir := t.TempDir()
+	token := filepath.Join(dir, "token")
+	if err := os.WriteFile(token, []byte("t0ken\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		config string
+		env    map[string]string
+		trim   bool
+		want   interface{}
+		err    string
+	}{
+		{
+			name:   "trimmed",
+			config: "token: file:" + token,
+			trim:   true,
+			want:   "t0ken",
+		},
+		{
+			name:   "untrimmed",
+			config: "token: file:" + token,
+			want:   "t0ken\n",
+		},
+		{
+			name:   "environment override",
+			config: "token: plain",
+			env:    map[string]string{"CONFIGWISE_TEST_TOKEN": "file:" + token},
+			trim:   true,
+			want:   "t0ken",
+		},
+		{
+			name:   "other values",
+			config: "token: profile:" + token,
+			want:   "profile:" + token,
+		},
+		{
+			name:   "missing file",
+			config: "token: file:" + filepath.Join(dir, "missing"),
+			err:    "token: file: open " + filepath.Join(dir, "missing"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			c, err := NewConfigurer(
+				WithFileValues(tt.trim),
+				WithPrefix("configwise_test"),
+				WithType("yaml"),
+				WithReadInConfig([]byte(tt.config)),
+			)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error is %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.Get("token"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("token is %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileValuesAreSecrets(t *testing.T) {
+	token := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(token, []byte("t0ken"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewConfigurer(WithFileValues(true), WithConfigMap(map[string]interface{}{"token": "file:" + token}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dump, err := c.DumpRedacted()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(dump), "t0ken") || !strings.Contains(string(dump), RedactedValue) {
+		t.Errorf("redacted dump is\n%s\nwant the token redacted", dump)
+	}
+}
+
+func TestValueSchemeOrder(t *testing.T) {
+	upper := ValueScheme{
+		Name:    "upper",
+		Match:   func(s string) bool { return strings.HasPrefix(s, "file:") },
+		Resolve: func(s string) (interface{}, error) { return strings.ToUpper(s), nil },
+	}
+	c, err := NewConfigurer(WithValueScheme(upper), WithFileValues(true), WithConfigMap(map[string]interface{}{"a": "file:/missing"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.GetString("a"); got != "FILE:/MISSING" {
+		t.Errorf("a is %q, want the value of the first matching scheme", got)
+	}
+}
+
+func TestBase64Values(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		env    map[string]string
+		key    string
+		want   interface{}
+		err    string
+	}{
+		{
+			name:   "config value",
+			config: "blob: base64:aGVsbG8=",
+			key:    "blob",
+			want:   []byte("hello"),
+		},
+		{
+			name:   "unpadded",
+			config: "blob: base64:aGVsbG8",
+			key:    "blob",
+			want:   []byte("hello"),
+		},
+		{
+			name:   "environment override",
+			config: "blob: plain",
+			env:    map[string]string{"CONFIGWISE_TEST_BLOB": "base64:aGVsbG8="},
+			key:    "blob",
+			want:   []byte("hello"),
+		},
+		{
+			name: "environment only",
+			env:  map[string]string{"CONFIGWISE_TEST_BLOB": "base64:aGVsbG8="},
+			key:  "blob",
+			want: []byte("hello"),
+		},
+		{
+			name:   "other values",
+			config: "name: base64",
+			key:    "name",
+			want:   "base64",
+		},
+		{
+			name:   "invalid",
+			config: "blob: base64:%%%",
+			err:    "blob: base64: illegal base64 data",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			c, err := NewConfigurer(
+				WithBase64Values(),
+				WithPrefix("configwise_test"),
+				WithType("yaml"),
+				WithReadInConfig([]byte(tt.config)),
+			)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error is %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.Get(tt.key); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s is %#v, want %#v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBase64ValuesOfEnvironment(t *testing.T) {
+	t.Setenv("CONFIGWISE_TEST_BLOB", "base64:%%%")
+
+	c, err := NewConfigurer(WithBase64Values(), WithPrefix("configwise_test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var blob []byte
+	if err := c.UnmarshalKey("blob", &blob); err == nil || !strings.Contains(err.Error(), "blob: base64: illegal base64 data") {
+		t.Errorf("error is %v, want the error of the base64 scheme", err)
+	}
+}
@@ -0,0 +1,99 @@
+package configwise
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+// TB is the part of testing.TB used by Scoped.
+type TB interface {
+	Helper()
+	Cleanup(func())
+	Fatalf(format string, args ...interface{})
+}
+
+// Scoped returns a view of the configurer with the overrides applied, for
+// the lifetime of a test. The view starts with the current configuration
+// and is independent afterwards: overrides of parallel tests sharing a
+// configurer never see each other and are never journaled, sent to webhooks,
+// audited or passed to the OnChange and stale key callbacks of the
+// configurer. The view is released when the test finishes.
+//
+//	cfg := configwise.Scoped(t, shared, map[string]interface{}{"http.port": 0})
+func Scoped(t TB, c Configurer, overrides map[string]interface{}) Configurer {
+	t.Helper()
+
+	cfg, ok := c.(*configurer)
+	if !ok {
+		t.Fatalf("configwise: scoped: unsupported configurer %T", c)
+		return nil
+	}
+
+	view := cfg.clone()
+	view.detach()
+	t.Cleanup(func() {
+		view.bus.unsubscribe(view.events)
+	})
+
+	if err := view.Overwrite(overrides); err != nil {
+		t.Fatalf("configwise: scoped: %v", err)
+	}
+	return view
+}
+
+// clone returns an independent configurer with the configuration and the
+// current state of cfg. The clone shares the providers but not the journal.
+func (cfg *configurer) clone() *configurer {
+	c := &configurer{configuration: cfg.configuration, usage: new(usage)}
+	c.journal = nil
+	c.events = c.bus.subscribe(eventBufferSize, OverflowDropNewest)
+
+	cfg.mu.RLock()
+	c.settings, c.secrets, c.origins, c.warnings = cfg.settings, cfg.secrets, cfg.origins, cfg.warnings
+	c.base, c.positions = cfg.base, cfg.positions
+	c.overrides = append([]override(nil), cfg.overrides...)
+	// clipped, so appends of the clone never write into the backing array
+	// of the configurer
+	c.generation, c.history = cfg.generation, slices.Clip(cfg.history)
+	cfg.mu.RUnlock()
+
+	cfg.stateMu.Lock()
+	c.down = make(map[string]struct{}, len(cfg.down))
+	for name := range cfg.down {
+		c.down[name] = struct{}{}
+	}
+	c.refreshed = make(map[string]time.Time, len(cfg.refreshed))
+	for name, t := range cfg.refreshed {
+		c.refreshed[name] = t
+	}
+	c.stale = make(map[string]struct{})
+	cfg.stateMu.Unlock()
+
+	cfg.aliases.mu.RLock()
+	c.aliases.keys = maps.Clone(cfg.aliases.keys)
+	cfg.aliases.mu.RUnlock()
+
+	return c
+}
+
+// Clone returns an independent copy of the configurer with its current
+// settings, overrides and aliases, e.g. for tests or per-tenant pipelines
+// which overwrite values without affecting the shared configurer. The clone
+// shares the providers, but neither its changes nor its reads are journaled,
+// sent to webhooks, audited or passed to the callbacks of the configurer.
+func (cfg *configurer) Clone() Configurer {
+	c := cfg.clone()
+	c.detach()
+	return c
+}
+
+// detach removes the hooks and callbacks of the configuration, which belong
+// to the configurer a clone was made of.
+func (cfg *configurer) detach() {
+	cfg.changeHooks = nil
+	cfg.audit = nil
+	cfg.access = nil
+	cfg.onChange = nil
+	cfg.onStale = nil
+}
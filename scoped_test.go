@@ -0,0 +1,51 @@
+package configwise
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestScopedAndCloneAreDetached(t *testing.T) {
+	var audits, accesses atomic.Int32
+	shared, err := NewConfigurer(
+		WithType("yaml"),
+		WithReadInConfig([]byte("http: {port: 80}")),
+		WithAuditLog(func(AuditEntry) { audits.Add(1) }),
+		WithAccessLog(func(AccessEntry) { accesses.Add(1) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	views := map[string]func(t *testing.T) Configurer{
+		"scoped": func(t *testing.T) Configurer {
+			return Scoped(t, shared, map[string]interface{}{"http.port": 0})
+		},
+		"clone": func(t *testing.T) Configurer {
+			c := shared.Clone()
+			if err := c.Overwrite(map[string]interface{}{"http.port": 0}); err != nil {
+				t.Fatal(err)
+			}
+			return c
+		},
+	}
+	for name, view := range views {
+		t.Run(name, func(t *testing.T) {
+			c := view(t)
+			if got := c.GetInt("http.port"); got != 0 {
+				t.Fatalf("port is %d, want 0", got)
+			}
+			if got := shared.GetInt("http.port"); got != 80 {
+				t.Fatalf("shared port is %d, want 80", got)
+			}
+		})
+	}
+
+	// only the reads of the shared configurer are audited
+	if got := audits.Load(); got != 0 {
+		t.Fatalf("%d changes were audited, want 0", got)
+	}
+	if got := accesses.Load(); got != 2 {
+		t.Fatalf("%d reads were audited, want 2", got)
+	}
+}
@@ -0,0 +1,78 @@
+package configwise
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// defaultSearchPaths returns the directories the config file is looked up in
+// when no path is configured, in order: the working directory,
+// $XDG_CONFIG_HOME/<app>, ~/.config/<app>, the directory of the executable
+// and /etc/<app>. The app is the name set with WithAppName, or the name of
+// the executable.
+func defaultSearchPaths(app string) []string {
+	exe, err := os.Executable()
+	if err != nil {
+		exe = ""
+	}
+	if app == "" && exe != "" {
+		app = strings.TrimSuffix(filepath.Base(exe), filepath.Ext(exe))
+	}
+
+	paths := []string{"."}
+	if app != "" {
+		if xdg := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(xdg) {
+			paths = append(paths, filepath.Join(xdg, app))
+		}
+		if home, err := os.UserHomeDir(); err == nil {
+			paths = append(paths, filepath.Join(home, ".config", app))
+		}
+	}
+	if exe != "" {
+		paths = append(paths, filepath.Dir(exe))
+	}
+	if app != "" {
+		paths = append(paths, filepath.Join("/etc", app))
+	}
+
+	// XDG_CONFIG_HOME usually is ~/.config
+	unique := paths[:0]
+	for _, path := range paths {
+		if !containsPath(unique, path) {
+			unique = append(unique, path)
+		}
+	}
+	return unique
+}
+
+func containsPath(paths []string, path string) bool {
+	for _, p := range paths {
+		if filepath.Clean(p) == filepath.Clean(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandPath expands environment variables, ${VAR} or $VAR with ${VAR:-default}
+// for defaults, and a leading ~ to the home directory of the user.
+func expandPath(path string) (string, error) {
+	path = ExpandVal(path, os.Getenv)
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+// isConfigFile reports whether the path has the extension of a config type.
+func isConfigFile(path string) bool {
+	ext := filepath.Ext(path)
+	return ext != "" && slices.Contains(configTypes, strings.ToLower(ext[1:]))
+}
@@ -0,0 +1,89 @@
+package configwise
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDefaultSearchPaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exeDir := filepath.Dir(exe)
+
+	tests := []struct {
+		name string
+		app  string
+		xdg  string
+		want []string
+	}{
+		{
+			name: "app",
+			app:  "orders",
+			xdg:  "/xdg",
+			want: []string{".", "/xdg/orders", filepath.Join(home, ".config", "orders"), exeDir, "/etc/orders"},
+		},
+		{
+			name: "xdg is ~/.config",
+			app:  "orders",
+			xdg:  filepath.Join(home, ".config"),
+			want: []string{".", filepath.Join(home, ".config", "orders"), exeDir, "/etc/orders"},
+		},
+		{
+			name: "relative xdg",
+			app:  "orders",
+			xdg:  "xdg",
+			want: []string{".", filepath.Join(home, ".config", "orders"), exeDir, "/etc/orders"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", tt.xdg)
+			if got := defaultSearchPaths(tt.app); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("paths are %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// the app defaults to the name of the executable
+	app := strings.TrimSuffix(filepath.Base(exe), filepath.Ext(exe))
+	t.Setenv("XDG_CONFIG_HOME", "")
+	want := []string{".", filepath.Join(home, ".config", app), exeDir, filepath.Join("/etc", app)}
+	if got := defaultSearchPaths(""); !reflect.DeepEqual(got, want) {
+		t.Errorf("paths are %v, want %v", got, want)
+	}
+}
+
+func TestExpandPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CONFIGWISE_TEST_DIR", "/srv")
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "~", want: home},
+		{path: "~/.orders/config.yaml", want: filepath.Join(home, ".orders/config.yaml")},
+		{path: "$CONFIGWISE_TEST_DIR/orders", want: "/srv/orders"},
+		{path: "${CONFIGWISE_TEST_MISSING:-/etc}/orders", want: "/etc/orders"},
+		{path: "~orders", want: "~orders"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := expandPath(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("path is %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package configwise
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PrioritySecretsDir is the priority of directory based secret providers,
+// which override the config file.
+const PrioritySecretsDir = 350
+
+// SecretProvider is implemented by providers whose values are all secrets.
+type SecretProvider interface {
+	Secret() bool
+}
+
+// WithDockerSecrets maps every file of the directory, usually /run/secrets,
+// to the config key named like the file, e.g. the content of db.password
+// becomes the value of db.password. A single trailing newline is removed.
+// A missing directory is ignored.
+func WithDockerSecrets(dir string) Option {
+	return func(c *configurer) {
+		c.custom = append(c.custom, &dirProvider{name: "docker secrets", dir: dir})
+	}
+}
+
+// WithSystemdCredentials reads the credentials passed by systemd with
+// LoadCredential= or SetCredential= from $CREDENTIALS_DIRECTORY. Every credential
+// becomes a key below the prefix, e.g. with the prefix "secrets" the credential
+// db.password is available as secrets.db.password. A single trailing newline, as
+// written by most tools, is removed. Nothing is read when the service runs
+// without credentials.
+func WithSystemdCredentials(prefix string) Option {
+	return func(c *configurer) {
+		c.custom = append(c.custom, &dirProvider{
+			name:   "systemd credentials",
+			dir:    os.Getenv("CREDENTIALS_DIRECTORY"),
+			prefix: strings.ToLower(prefix),
+		})
+	}
+}
+
+// dirProvider reads one value per file of a directory.
+type dirProvider struct {
+	name   string
+	dir    string
+	prefix string
+}
+
+func (p *dirProvider) Name() string {
+	return p.name
+}
+
+func (p *dirProvider) Priority() int {
+	return PrioritySecretsDir
+}
+
+func (p *dirProvider) Source() Source {
+	return SourceFile
+}
+
+func (p *dirProvider) Secret() bool {
+	return true
+}
+
+func (p *dirProvider) Read() (map[string]interface{}, error) {
+	if p.dir == "" {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	tree := make(map[string]interface{}, len(entries))
+	for _, entry := range entries {
+		// skip hidden files as well as the ..data links of kubernetes volumes
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		file := filepath.Join(p.dir, entry.Name())
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		key := strings.ToLower(entry.Name())
+		if p.prefix != "" {
+			key = p.prefix + keyDelimiter + key
+		}
+		setPath(tree, key, trimNewline(string(data)))
+	}
+	return tree, nil
+}
+
+func (p *dirProvider) Watch(context.Context, func()) error {
+	return nil
+}
+
+// trimNewline removes a single trailing line break.
+func trimNewline(s string) string {
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r")
+}
@@ -0,0 +1,95 @@
+package configwise
+
+import (
+	"iter"
+	"time"
+)
+
+// ReadOnlyConfig is the read side of a Configurer, as returned by Snapshot.
+// It is safe for concurrent use by multiple goroutines.
+type ReadOnlyConfig interface {
+	// UnmarshalKey takes a single key and unmarshal it into a Struct.
+	UnmarshalKey(name string, out interface{}) error
+
+	// Unmarshal the config into a Struct. Make sure that the tags
+	// on the fields of the structure are properly set. Values missing in the
+	// config are taken from default struct tags or set by a Defaults method
+	// of the target. Validate methods of the target and of nested values
+	// are called after decoding.
+	Unmarshal(out interface{}) error
+
+	// Get used to get config section. Values which fail to resolve on access,
+	// like undecryptable vault-transit: values, are nil and reported by Warnings.
+	Get(name string) interface{}
+
+	// Has checks if config section exists.
+	Has(name string) bool
+
+	// GetString returns the value of the key as a string, or "" if it is not
+	// set or cannot be converted.
+	GetString(name string) string
+
+	// GetInt returns the value of the key as an int, or 0 if it is not set or
+	// cannot be converted.
+	GetInt(name string) int
+
+	// GetBool returns the value of the key as a bool, or false if it is not
+	// set or cannot be converted.
+	GetBool(name string) bool
+
+	// GetDuration returns the value of the key as a duration, e.g. from "5s",
+	// or 0 if it is not set or cannot be converted.
+	GetDuration(name string) time.Duration
+
+	// GetStringSlice returns the value of the key as a list of strings, or nil
+	// if it is not set or cannot be converted. Strings are split at commas.
+	GetStringSlice(name string) []string
+
+	// GetStringMap returns a copy of the section stored under the key, or nil
+	// if it is not set or not a section.
+	GetStringMap(name string) map[string]interface{}
+
+	// Keys returns an iterator over the sorted keys of all values below prefix.
+	Keys(prefix string) iter.Seq[string]
+
+	// Values returns an iterator over the keys and values of all values below prefix.
+	Values(prefix string) iter.Seq2[string, interface{}]
+
+	// AllKeys returns the sorted keys of all values of the effective configuration.
+	AllKeys() []string
+
+	// Settings returns a copy of the effective configuration as a tree of sections.
+	Settings() map[string]interface{}
+
+	// Origin returns the kind of source which produced the effective value of the key.
+	Origin(key string) Source
+
+	// DumpAnnotated renders the effective configuration as YAML with the
+	// origin of every value as comment.
+	DumpAnnotated() ([]byte, error)
+
+	// DumpRedacted renders the effective configuration as YAML without the
+	// plaintext of secrets.
+	DumpRedacted() ([]byte, error)
+
+	// Diff returns the changes from the configuration to other, ordered by
+	// key, with secrets redacted.
+	Diff(other ReadOnlyConfig) []Change
+}
+
+// Snapshot returns an immutable view of the configuration at the time of the
+// call, which may be handed to request handlers while the configurer keeps
+// reloading: all reads of the snapshot see the same settings. Keys read
+// through the snapshot count as used by the configurer.
+func (cfg *configurer) Snapshot() ReadOnlyConfig {
+	return cfg.snapshot()
+}
+
+// snapshot returns a frozen clone sharing the usage of the configurer.
+func (cfg *configurer) snapshot() *configurer {
+	c := cfg.clone()
+	c.changeHooks = nil
+	c.usage = cfg.usage
+	c.frozen.Store(true)
+	return c
+}
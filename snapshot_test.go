@@ -0,0 +1,45 @@
+package configwise
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	c, err := NewConfigurer(
+		WithUsageTracking(),
+		WithConfigMap(map[string]interface{}{"name": "app", "server": map[string]interface{}{"port": 80}}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := c.Snapshot()
+	if err := c.Overwrite(map[string]interface{}{"server.port": 8080, "debug": true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := snapshot.GetInt("server.port"); got != 80 {
+		t.Errorf("port of the snapshot is %d, want 80", got)
+	}
+	if snapshot.Has("debug") {
+		t.Error("the snapshot has a key added after it was taken")
+	}
+	if got := c.GetInt("server.port"); got != 8080 {
+		t.Errorf("port of the configurer is %d, want 8080", got)
+	}
+
+	// snapshots cannot be changed
+	if !snapshot.(*configurer).Frozen() {
+		t.Error("the snapshot is not frozen")
+	}
+
+	// reads of the snapshot count as usage of the configurer
+	var server struct{ Port int }
+	if err := snapshot.UnmarshalKey("server", &server); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := c.UnusedKeys(), []string{"debug", "name"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unused keys are %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,79 @@
+package configwise
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+const sopsKey = "sops"
+
+// ErrSOPSDecrypterMissing is returned for SOPS encrypted documents when no decrypter is configured.
+var ErrSOPSDecrypterMissing = errors.New("document is encrypted with SOPS, but no decrypter is configured")
+
+// SOPSDecrypter decrypts a SOPS document of the given format ("yaml",
+// "json", ...), like decrypt.Data of github.com/getsops/sops/v3/decrypt used
+// by the sops subpackage, which keeps SOPS and the clients of its key
+// services out of applications not reading encrypted files:
+//
+//	c, err := configwise.NewConfigurer(sops.Option())
+type SOPSDecrypter func(data []byte, format string) ([]byte, error)
+
+// WithSOPS hooks the decrypter into the reading of config files and documents
+// passed with WithReadInConfig, so SOPS encrypted documents are decrypted
+// transparently. Plain documents are read as is. Without decrypter, encrypted
+// documents fail with ErrSOPSDecrypterMissing.
+func WithSOPS(decrypter SOPSDecrypter) Option {
+	return func(c *configurer) {
+		c.sops = decrypter
+	}
+}
+
+// isSOPS reports whether the tree carries SOPS metadata.
+func isSOPS(tree map[string]interface{}) bool {
+	metadata, ok := tree[sopsKey].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = metadata["mac"]
+	return ok
+}
+
+// decodeDocument parses a config document, decrypting it first when it is
+// SOPS encrypted. Documents which include or import other files, HOCON and
+// Jsonnet, are evaluated relative to the file, unless a decoder is registered
+// for their type.
+func (cfg *configurer) decodeDocument(file, format string, data []byte) (map[string]interface{}, error) {
+	if _, ok := lookupDecoder(cfg.decoders, format); !ok {
+		switch {
+		case isHOCON(format):
+			return decodeHOCON(data, filepath.Dir(file))
+		case isJsonnet(format):
+			return cfg.evaluateJsonnet(file, data)
+		}
+	}
+
+	tree, err := cfg.decodeCached(format, data)
+	if err != nil || !isSOPS(tree) {
+		return tree, err
+	}
+
+	if cfg.sops == nil {
+		return nil, ErrSOPSDecrypterMissing
+	}
+
+	if format == "yml" {
+		format = "yaml"
+	}
+
+	plain, err := cfg.sops(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("sops: %w", err)
+	}
+
+	if tree, err = decodeConfig(cfg.decoders, format, plain); err != nil {
+		return nil, err
+	}
+	delete(tree, sopsKey)
+	return tree, nil
+}
@@ -0,0 +1,23 @@
+// Package sops decrypts SOPS encrypted config files for configwise with
+// github.com/getsops/sops/v3, which supports the age, KMS and PGP backends.
+// It is a separate package so that only applications reading encrypted
+// files link SOPS and the clients of its key services:
+//
+//	c, err := configwise.NewConfigurer(sops.Option())
+package sops
+
+import (
+	"github.com/getsops/sops/v3/decrypt"
+
+	"github.com/gowool/configwise"
+)
+
+// Option decrypts SOPS encrypted config files and documents passed with
+// configwise.WithReadInConfig. The keys are found like by the sops command,
+// e.g. age identities in the file of SOPS_AGE_KEY_FILE, or credentials of
+// the cloud KMS in the environment.
+func Option() configwise.Option {
+	return configwise.WithSOPS(decrypt.Data)
+}
+
+var _ configwise.SOPSDecrypter = decrypt.Data
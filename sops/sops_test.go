@@ -0,0 +1,85 @@
+package sops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gowool/configwise"
+)
+
+func TestOption(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []configwise.Option
+		keyFile string
+		err     string
+	}{
+		{
+			name:    "yaml file",
+			options: []configwise.Option{configwise.WithPath("testdata/config.enc.yaml")},
+			keyFile: "testdata/age.key",
+		},
+		{
+			name:    "json file",
+			options: []configwise.Option{configwise.WithPath("testdata/config.enc.json")},
+			keyFile: "testdata/age.key",
+		},
+		{
+			name:    "read in config",
+			options: []configwise.Option{configwise.WithType("yaml"), configwise.WithReadInConfig(readFile(t, "testdata/config.enc.yaml"))},
+			keyFile: "testdata/age.key",
+		},
+		{
+			name:    "plain document",
+			options: []configwise.Option{configwise.WithType("yaml"), configwise.WithReadInConfig([]byte("db: {host: localhost, password: s3cret}\nport: 8080"))},
+		},
+		{
+			name:    "missing key",
+			options: []configwise.Option{configwise.WithPath("testdata/config.enc.yaml")},
+			keyFile: filepath.Join(t.TempDir(), "missing.key"),
+			err:     "sops: ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.keyFile != "" {
+				t.Setenv("SOPS_AGE_KEY_FILE", tt.keyFile)
+			}
+
+			c, err := configwise.NewConfigurer(append([]configwise.Option{Option()}, tt.options...)...)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error is %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := c.GetString("db.host"); got != "localhost" {
+				t.Errorf("db.host is %q, want localhost", got)
+			}
+			if got := c.GetString("db.password"); got != "s3cret" {
+				t.Errorf("db.password is %q, want s3cret", got)
+			}
+			if got := c.GetInt("port"); got != 8080 {
+				t.Errorf("port is %d, want 8080", got)
+			}
+			if c.Has("sops") {
+				t.Error("the SOPS metadata is part of the settings")
+			}
+		})
+	}
+}
+
+func readFile(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -0,0 +1,77 @@
+package configwise
+
+import "sort"
+
+// Source identifies the kind of origin a configuration value comes from.
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceRemote  Source = "remote"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlags   Source = "flags"
+)
+
+// defaultPrecedence lists the source kinds from the highest precedence to the lowest.
+var defaultPrecedence = []Source{SourceFlags, SourceEnv, SourceFile, SourceRemote, SourceDefault}
+
+// Sourcer is implemented by providers which report the kind of source they read.
+// Providers which do not implement it are treated as SourceRemote.
+type Sourcer interface {
+	Source() Source
+}
+
+// WithPrecedence defines the precedence of source kinds, from the highest to the lowest,
+// e.g. WithPrecedence(SourceFlags, SourceFile, SourceEnv) lets config files beat
+// environment variables. Kinds which are not listed rank below the listed ones in
+// their default order. Priorities only order providers of the same kind.
+// Values set with Overwrite always take precedence.
+func WithPrecedence(sources ...Source) Option {
+	return func(c *configurer) {
+		precedence := append([]Source(nil), sources...)
+		for _, source := range defaultPrecedence {
+			if !containsSource(precedence, source) {
+				precedence = append(precedence, source)
+			}
+		}
+		c.precedence = precedence
+	}
+}
+
+func containsSource(sources []Source, source Source) bool {
+	for _, s := range sources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
+func sourceOf(p Provider) Source {
+	if s, ok := p.(Sourcer); ok {
+		return s.Source()
+	}
+	return SourceRemote
+}
+
+// sortProviders orders the chain from the lowest precedence to the highest,
+// so that later providers override earlier ones.
+func sortProviders(providers []Provider, precedence []Source) {
+	rank := func(p Provider) int {
+		source := sourceOf(p)
+		for i, s := range precedence {
+			if s == source {
+				return len(precedence) - i
+			}
+		}
+		return 0
+	}
+
+	sort.SliceStable(providers, func(i, j int) bool {
+		if ri, rj := rank(providers[i]), rank(providers[j]); ri != rj {
+			return ri < rj
+		}
+		return providers[i].Priority() < providers[j].Priority()
+	})
+}
@@ -0,0 +1,48 @@
+package configwise
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// WithStdin reads a config document of the type from the standard input,
+// e.g. for `cat config.yaml | app -`. The document overrides the config
+// file. Standard input is read once, on the first load, and its document is
+// kept for reloads.
+func WithStdin(configType string) Option {
+	return func(c *configurer) {
+		c.stdinType = configType
+	}
+}
+
+// stdinProvider provides the document read from stdin.
+type stdinProvider struct {
+	bytesProvider
+	stdin io.Reader
+
+	once sync.Once
+	err  error
+}
+
+func newStdinProvider(configType string, decode decodeFunc) *stdinProvider {
+	return &stdinProvider{
+		bytesProvider: bytesProvider{
+			name:       "stdin",
+			priority:   PriorityStdin,
+			configType: configType,
+			decode:     decode,
+		},
+		stdin: os.Stdin,
+	}
+}
+
+func (p *stdinProvider) Read() (map[string]interface{}, error) {
+	p.once.Do(func() {
+		p.data, p.err = io.ReadAll(p.stdin)
+	})
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.bytesProvider.Read()
+}
@@ -0,0 +1,49 @@
+package configwise
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStdin(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(file, []byte("name: file\nport: 80"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	stdin, err := os.CreateTemp(dir, "stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stdin.WriteString(`{"name": "stdin"}`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stdin.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	defer func(stdin *os.File) { os.Stdin = stdin }(os.Stdin)
+	os.Stdin = stdin
+
+	c, err := NewConfigurer(WithPath(file), WithStdin("json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.GetString("name"); got != "stdin" {
+		t.Errorf("name is %q, want the value of stdin", got)
+	}
+	if got := c.GetInt("port"); got != 80 {
+		t.Errorf("port is %d, want the value of the file", got)
+	}
+
+	// the document is kept for reloads
+	if err := c.(*configurer).reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.GetString("name"); got != "stdin" {
+		t.Errorf("name is %q after a reload, want the value of stdin", got)
+	}
+}
@@ -0,0 +1,312 @@
+package configwise
+
+import (
+	"context"
+	"io"
+	"iter"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sub returns a view of the section stored under the key, in which all keys
+// are relative to the section, e.g. Sub("plugins.auth").Get("issuer") reads
+// "plugins.auth.issuer". The view reads and writes the configurer, so it sees
+// reloads and its overwrites are visible to the configurer. Dumps, exports,
+// warnings, unused keys and key events are limited to the section. The
+// section does not have to exist.
+func (cfg *configurer) Sub(name string) Configurer {
+	return &subConfigurer{parent: cfg, root: cfg.canonicalKey(name)}
+}
+
+// subConfigurer is a view of the section stored under root.
+type subConfigurer struct {
+	parent *configurer
+	root   string
+}
+
+// key returns the full key of a key of the section.
+func (s *subConfigurer) key(name string) string {
+	if name == "" {
+		return s.root
+	}
+	return joinKey(s.root, name)
+}
+
+// relative returns the key of the section for a full key and whether the key
+// belongs to the section.
+func (s *subConfigurer) relative(key string) (string, bool) {
+	switch {
+	case s.root == "":
+		return key, true
+	case key == s.root:
+		return "", true
+	case strings.HasPrefix(key, s.root+keyDelimiter):
+		return key[len(s.root)+len(keyDelimiter):], true
+	case strings.HasPrefix(key, s.root+"["):
+		return key[len(s.root):], true
+	}
+	return "", false
+}
+
+func (s *subConfigurer) UnmarshalKey(name string, out interface{}) error {
+	return s.parent.UnmarshalKey(s.key(name), out)
+}
+
+func (s *subConfigurer) Unmarshal(out interface{}) error {
+	return s.parent.UnmarshalKey(s.root, out)
+}
+
+func (s *subConfigurer) Overwrite(values map[string]interface{}) error {
+	return s.OverwriteContext(context.Background(), values)
+}
+
+func (s *subConfigurer) OverwriteContext(ctx context.Context, values map[string]interface{}) error {
+	prefixed := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		prefixed[s.key(key)] = value
+	}
+	return s.parent.OverwriteContext(ctx, prefixed)
+}
+
+func (s *subConfigurer) OverwriteMerge(values map[string]interface{}) error {
+	prefixed := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		prefixed[s.key(key)] = value
+	}
+	return s.parent.OverwriteMerge(prefixed)
+}
+
+func (s *subConfigurer) ApplyPatch(patch []byte, kind PatchKind) error {
+	return s.ApplyPatchContext(context.Background(), patch, kind)
+}
+
+// ApplyPatchContext applies the patch to the section, paths of a JSON Patch
+// are relative to the section.
+func (s *subConfigurer) ApplyPatchContext(ctx context.Context, patch []byte, kind PatchKind) error {
+	return s.parent.applyPatch(ctx, s.root, patch, kind)
+}
+
+func (s *subConfigurer) Unset(name string) error {
+	return s.parent.Unset(s.key(name))
+}
+
+func (s *subConfigurer) UnsetContext(ctx context.Context, name string) error {
+	return s.parent.UnsetContext(ctx, s.key(name))
+}
+
+func (s *subConfigurer) Get(name string) interface{} {
+	return s.parent.Get(s.key(name))
+}
+
+func (s *subConfigurer) Has(name string) bool {
+	return s.parent.Has(s.key(name))
+}
+
+func (s *subConfigurer) GetString(name string) string {
+	return s.parent.GetString(s.key(name))
+}
+
+func (s *subConfigurer) GetInt(name string) int {
+	return s.parent.GetInt(s.key(name))
+}
+
+func (s *subConfigurer) GetBool(name string) bool {
+	return s.parent.GetBool(s.key(name))
+}
+
+func (s *subConfigurer) GetDuration(name string) time.Duration {
+	return s.parent.GetDuration(s.key(name))
+}
+
+func (s *subConfigurer) GetStringSlice(name string) []string {
+	return s.parent.GetStringSlice(s.key(name))
+}
+
+func (s *subConfigurer) GetStringMap(name string) map[string]interface{} {
+	return s.parent.GetStringMap(s.key(name))
+}
+
+func (s *subConfigurer) Sub(name string) Configurer {
+	return &subConfigurer{parent: s.parent, root: s.parent.canonicalKey(s.key(name))}
+}
+
+// Clone returns a view of the section of a clone of the configurer.
+func (s *subConfigurer) Clone() Configurer {
+	return &subConfigurer{parent: s.parent.Clone().(*configurer), root: s.root}
+}
+
+// Snapshot returns an immutable view of the section.
+func (s *subConfigurer) Snapshot() ReadOnlyConfig {
+	return &subConfigurer{parent: s.parent.snapshot(), root: s.root}
+}
+
+func (s *subConfigurer) Generation() uint64 {
+	return s.parent.Generation()
+}
+
+func (s *subConfigurer) History() []Revision {
+	return s.parent.History()
+}
+
+// Rollback rolls back the whole configuration, not only the section.
+func (s *subConfigurer) Rollback(generation uint64) error {
+	return s.parent.Rollback(generation)
+}
+
+func (s *subConfigurer) RollbackContext(ctx context.Context, generation uint64) error {
+	return s.parent.RollbackContext(ctx, generation)
+}
+
+// Freeze freezes the whole configuration, not only the section.
+func (s *subConfigurer) Freeze() {
+	s.parent.Freeze()
+}
+
+func (s *subConfigurer) Frozen() bool {
+	return s.parent.Frozen()
+}
+
+func (s *subConfigurer) RegisterAlias(alias, canonical string) {
+	s.parent.RegisterAlias(s.key(alias), s.key(canonical))
+}
+
+func (s *subConfigurer) Keys(prefix string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for key := range s.parent.Keys(s.key(prefix)) {
+			if rel, ok := s.relative(key); ok && !yield(rel) {
+				return
+			}
+		}
+	}
+}
+
+func (s *subConfigurer) Values(prefix string) iter.Seq2[string, interface{}] {
+	return func(yield func(string, interface{}) bool) {
+		for key, value := range s.parent.Values(s.key(prefix)) {
+			if rel, ok := s.relative(key); ok && !yield(rel, value) {
+				return
+			}
+		}
+	}
+}
+
+func (s *subConfigurer) AllKeys() []string {
+	return slices.Collect(s.Keys(""))
+}
+
+func (s *subConfigurer) Settings() map[string]interface{} {
+	key, tree, _ := s.parent.subtree(s.root)
+	s.parent.auditAccess("settings", key, tree)
+	return s.parent.resolveSection(tree)
+}
+
+func (s *subConfigurer) Watch(ctx context.Context) error {
+	return s.parent.Watch(ctx)
+}
+
+// Events returns a channel receiving the lifecycle events of the configurer,
+// with key events limited to the section. Like the channel of the configurer,
+// it is shared by all callers, here by all views of the section, and lives as
+// long as the configurer. Unlike it, it only receives events published after
+// the first call for the section; use Subscribe for channels which end.
+func (s *subConfigurer) Events() <-chan Event {
+	p := s.parent
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+
+	events, ok := p.sectionEvents[s.root]
+	if !ok {
+		sub := p.bus.subscribe(p.eventBuffer, p.overflow)
+		events = s.filter(sub.ch)
+		if p.sectionEvents == nil {
+			p.sectionEvents = make(map[string]<-chan Event)
+		}
+		p.sectionEvents[s.root] = events
+	}
+	return events
+}
+
+func (s *subConfigurer) Subscribe(ctx context.Context, types ...EventType) <-chan Event {
+	return s.filter(s.parent.Subscribe(ctx, types...))
+}
+
+// filter forwards the events, dropping key events of keys outside the section
+// and making the keys of the others relative.
+func (s *subConfigurer) filter(in <-chan Event) <-chan Event {
+	out := make(chan Event, cap(in))
+	go func() {
+		defer close(out)
+		for event := range in {
+			if event.Key != "" {
+				rel, ok := s.relative(event.Key)
+				if !ok {
+					continue
+				}
+				event.Key = rel
+			}
+			out <- event
+		}
+	}()
+	return out
+}
+
+func (s *subConfigurer) Degraded() []Feature {
+	return s.parent.Degraded()
+}
+
+func (s *subConfigurer) Health() Health {
+	return s.parent.Health()
+}
+
+func (s *subConfigurer) Origin(key string) Source {
+	return s.parent.Origin(s.key(key))
+}
+
+func (s *subConfigurer) DumpAnnotated() ([]byte, error) {
+	return s.parent.dumpAnnotated(s.root)
+}
+
+func (s *subConfigurer) UnusedKeys() []string {
+	var unused []string
+	for _, key := range s.parent.UnusedKeys() {
+		if rel, ok := s.relative(key); ok {
+			unused = append(unused, rel)
+		}
+	}
+	return unused
+}
+
+func (s *subConfigurer) Warnings() []Warning {
+	var warnings []Warning
+	for _, w := range s.parent.Warnings() {
+		if rel, ok := s.relative(w.Key); ok {
+			w.Key = rel
+			warnings = append(warnings, w)
+		}
+	}
+	return warnings
+}
+
+func (s *subConfigurer) DumpRedacted() ([]byte, error) {
+	return yaml.Marshal(s.parent.redactSection(s.root))
+}
+
+// Diff compares the section to other.
+func (s *subConfigurer) Diff(other ReadOnlyConfig) []Change {
+	return diffViews(s.parent.diffView(s.root), viewOf(other))
+}
+
+func (s *subConfigurer) WriteConfig(path, format string) error {
+	return s.parent.writeConfig(s.root, path, format)
+}
+
+func (s *subConfigurer) ExportEnv(prefix string, options ...ExportOption) map[string]string {
+	return s.parent.exportEnv(s.root, prefix, options)
+}
+
+func (s *subConfigurer) WriteEnv(w io.Writer, prefix string, options ...ExportOption) error {
+	return writeEnv(w, s.ExportEnv(prefix, options...))
+}
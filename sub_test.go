@@ -0,0 +1,133 @@
+package configwise
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+const subConfig = `
+plugins:
+  auth:
+    issuer: https://id.example.com
+    ttl: 5m
+    scopes: [read, write]
+  cache:
+    size: 10
+name: app
+`
+
+func TestSub(t *testing.T) {
+	c, err := NewConfigurer(WithType("yaml"), WithReadInConfig([]byte(subConfig)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth := c.Sub("Plugins.Auth")
+	if got := auth.GetString("issuer"); got != "https://id.example.com" {
+		t.Errorf("issuer is %q", got)
+	}
+	if got := auth.GetDuration("ttl"); got != 5*time.Minute {
+		t.Errorf("ttl is %v, want 5m", got)
+	}
+	if got, want := auth.AllKeys(), []string{"issuer", "scopes", "ttl"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("keys are %v, want %v", got, want)
+	}
+	if auth.Has("name") {
+		t.Error("the view has a key outside the section")
+	}
+
+	var out struct {
+		Issuer string
+		Scopes []string
+	}
+	if err := auth.Unmarshal(&out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Issuer != "https://id.example.com" || !reflect.DeepEqual(out.Scopes, []string{"read", "write"}) {
+		t.Errorf("section is %+v", out)
+	}
+
+	// nested views and writes through views
+	if got := c.Sub("plugins").Sub("cache").GetInt("size"); got != 10 {
+		t.Errorf("size is %d, want 10", got)
+	}
+	if err := auth.Overwrite(map[string]interface{}{"ttl": "1m"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.GetDuration("plugins.auth.ttl"); got != time.Minute {
+		t.Errorf("plugins.auth.ttl is %v after an overwrite of the view, want 1m", got)
+	}
+
+	// missing sections are empty views
+	missing := c.Sub("missing")
+	if missing.Get("key") != nil || len(missing.AllKeys()) != 0 {
+		t.Error("the view of a missing section is not empty")
+	}
+}
+
+func TestSubEvents(t *testing.T) {
+	c, err := NewConfigurer(WithType("yaml"), WithReadInConfig([]byte(subConfig)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := c.Sub("plugins.auth").Events()
+	if other := c.Sub("plugins.auth").Events(); other != events {
+		t.Error("views of the same section have different event channels")
+	}
+
+	if err := c.Overwrite(map[string]interface{}{"name": "other", "plugins.auth.ttl": "1m"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-events:
+		if event.Type != EventKeyChanged || event.Key != "ttl" {
+			t.Errorf("event is %+v, want a change of ttl", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no event of the section")
+	}
+	select {
+	case event := <-events:
+		t.Errorf("unexpected event %+v", event)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// views do not subscribe again
+	goroutines := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		c.Sub("plugins.auth").Events()
+	}
+	if n := runtime.NumGoroutine(); n > goroutines {
+		t.Errorf("%d goroutines after reading the events of 100 views, want %d", n, goroutines)
+	}
+}
+
+func TestSubSubscribe(t *testing.T) {
+	c, err := NewConfigurer(WithType("yaml"), WithReadInConfig([]byte(subConfig)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := c.Sub("plugins").Subscribe(ctx, EventKeyChanged)
+	if err := c.Overwrite(map[string]interface{}{"plugins.cache.size": 20}); err != nil {
+		t.Fatal(err)
+	}
+	if event := <-events; event.Key != "cache.size" {
+		t.Errorf("key is %q, want cache.size", event.Key)
+	}
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("event after the context is done")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the channel is not closed when the context is done")
+	}
+}
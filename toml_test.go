@@ -0,0 +1,164 @@
+package configwise
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTOMLDetection(t *testing.T) {
+	files := map[string]string{
+		"config.toml": "[server]\nhost = \"toml\"\n",
+		"config.yaml": "server:\n  host: yaml\n",
+		"config.json": `{"server": {"host": "json"}}`,
+		"app.toml":    "[server]\nhost = \"app\"\n",
+	}
+
+	tests := []struct {
+		name    string
+		files   []string
+		options func(dir string) []Option
+		want    string
+	}{
+		{
+			name:    "toml extension",
+			files:   []string{"config.toml"},
+			options: func(dir string) []Option { return []Option{WithPath(dir)} },
+			want:    "toml",
+		},
+		{
+			name:    "yaml before toml",
+			files:   []string{"config.toml", "config.yaml"},
+			options: func(dir string) []Option { return []Option{WithPath(dir)} },
+			want:    "yaml",
+		},
+		{
+			name:    "json before toml",
+			files:   []string{"config.toml", "config.json"},
+			options: func(dir string) []Option { return []Option{WithPath(dir)} },
+			want:    "json",
+		},
+		{
+			name:    "type",
+			files:   []string{"config.toml", "config.yaml"},
+			options: func(dir string) []Option { return []Option{WithPath(dir), WithType("toml")} },
+			want:    "toml",
+		},
+		{
+			name:    "file path",
+			files:   []string{"app.toml", "config.yaml"},
+			options: func(dir string) []Option { return []Option{WithPath(filepath.Join(dir, "app.toml"))} },
+			want:    "app",
+		},
+		{
+			name:    "name with extension",
+			files:   []string{"app.toml", "config.yaml"},
+			options: func(dir string) []Option { return []Option{WithPath(dir), WithName("app.toml")} },
+			want:    "app",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(files[name]), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			c, err := NewConfigurer(tt.options(dir)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.GetString("server.host"); got != tt.want {
+				t.Errorf("server.host is %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTOMLArrayOfTables(t *testing.T) {
+	type route struct {
+		Path string
+	}
+	type server struct {
+		Name   string
+		Port   int
+		Routes []route
+	}
+
+	const defaults = `
+[[servers]]
+name = "default"
+port = 80
+`
+
+	tests := []struct {
+		name   string
+		config string
+		want   []server
+	}{
+		{
+			name:   "defaults",
+			config: `title = "app"`,
+			want:   []server{{Name: "default", Port: 80}},
+		},
+		{
+			name: "tables",
+			config: `
+[[servers]]
+name = "public"
+port = 8080
+
+[[servers.routes]]
+path = "/"
+
+[[servers.routes]]
+path = "/api"
+
+[[servers]]
+name = "admin"
+port = 9090
+`,
+			want: []server{
+				{Name: "public", Port: 8080, Routes: []route{{Path: "/"}, {Path: "/api"}}},
+				{Name: "admin", Port: 9090},
+			},
+		},
+		{
+			name:   "inline tables",
+			config: `servers = [{name = "public", port = 8080}]`,
+			want:   []server{{Name: "public", Port: 8080}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewConfigurer(
+				WithDefaults([]byte(defaults), "toml"),
+				WithType("toml"),
+				WithReadInConfig([]byte(tt.config)),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []server
+			if err := c.UnmarshalKey("servers", &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("servers are %+v, want %+v", got, tt.want)
+			}
+			list, ok := c.Get("servers").([]interface{})
+			if !ok || len(list) != len(tt.want) {
+				t.Fatalf("servers are %#v, want a list of %d sections", c.Get("servers"), len(tt.want))
+			}
+			for i, section := range list {
+				if _, ok := section.(map[string]interface{}); !ok {
+					t.Errorf("servers[%d] is %T, want a section", i, section)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package configwise
+
+import "context"
+
+// Tracer records spans for the initial load, reloads, reads of every provider
+// and the resolution of secrets, like the OpenTelemetry tracer of the otel
+// subpackage. Reads of single values are not traced.
+type Tracer interface {
+	// Start starts the span with the attributes, returning the context
+	// holding the span.
+	Start(ctx context.Context, name string, attrs map[string]string) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	// End records the error, if any, and ends the span.
+	End(err error)
+}
+
+// WithTracer records the spans of loads, reloads, provider reads and secret
+// resolution with the tracer:
+//
+//	configwise.NewConfigurer(otel.WithTracerProvider(provider))
+func WithTracer(tracer Tracer) Option {
+	return func(c *configurer) {
+		c.tracer = tracer
+	}
+}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string, _ map[string]string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) End(error) {}
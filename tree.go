@@ -0,0 +1,259 @@
+package configwise
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const keyDelimiter = "."
+
+// normalizeTree returns a copy of the tree with lower-cased keys and every
+// nested map converted to map[string]interface{}, so that all providers
+// share a single case-insensitive key space.
+func normalizeTree(tree map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(tree))
+	for key, value := range tree {
+		out[strings.ToLower(key)] = normalizeValue(value)
+	}
+	return out
+}
+
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return normalizeTree(v)
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[strings.ToLower(fmt.Sprint(key))] = normalizeValue(val)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = normalizeValue(val)
+		}
+		return s
+	case []map[string]interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = normalizeTree(val)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+// deepCopy returns a copy of value in which maps and slices are not shared
+// with the original.
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[key] = deepCopy(val)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = deepCopy(val)
+		}
+		return s
+	case []string:
+		return append([]string(nil), v...)
+	case []byte:
+		return append([]byte(nil), v...)
+	default:
+		return v
+	}
+}
+
+// deepMerge merges src into dst. Nested maps are merged recursively, any
+// other value in src replaces the one in dst.
+func deepMerge(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcOk := value.(map[string]interface{})
+		dstMap, dstOk := dst[key].(map[string]interface{})
+		if srcOk && dstOk {
+			deepMerge(dstMap, srcMap)
+			continue
+		}
+		dst[key] = deepCopy(value)
+	}
+}
+
+// searchPath returns the value stored under the dot-delimited key.
+func searchPath(tree map[string]interface{}, key string) (interface{}, bool) {
+	var current interface{} = tree
+	for _, part := range strings.Split(key, keyDelimiter) {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// setPath stores value under the dot-delimited key, creating intermediate
+// maps as needed and replacing any non-map value in the way.
+func setPath(tree map[string]interface{}, key string, value interface{}) {
+	parts := strings.Split(key, keyDelimiter)
+	current := tree
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			current[part] = next
+		}
+		current = next
+	}
+	current[parts[len(parts)-1]] = value
+}
+
+// deletePath removes the value stored under the dot-delimited key, and
+// sections left empty by the removal.
+func deletePath(tree map[string]interface{}, key string) {
+	parts := strings.Split(key, keyDelimiter)
+	parents := make([]map[string]interface{}, 0, len(parts))
+	current := tree
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			return
+		}
+		parents = append(parents, current)
+		current = next
+	}
+	delete(current, parts[len(parts)-1])
+
+	for i := len(parents) - 1; i >= 0 && len(current) == 0; i-- {
+		delete(parents[i], parts[i])
+		current = parents[i]
+	}
+}
+
+// setOrigin records the provider of the leaf key, which replaced the value
+// previously stored under the key. Origins of the keys replaced by the leaf,
+// its parents which were single values and the keys of a replaced section,
+// are removed.
+func setOrigin(origins map[string]Provider, key string, replaced interface{}, p Provider) {
+	for parent := key; ; {
+		i := strings.LastIndex(parent, keyDelimiter)
+		if i == -1 {
+			break
+		}
+		parent = parent[:i]
+		delete(origins, parent)
+	}
+	if section, ok := replaced.(map[string]interface{}); ok {
+		for _, k := range leafKeys(section) {
+			delete(origins, key+keyDelimiter+k)
+		}
+	}
+	origins[key] = p
+}
+
+// leafKeys returns the sorted, dot-delimited paths of all non-map values.
+func leafKeys(tree map[string]interface{}) []string {
+	var keys []string
+	var walk func(prefix string, m map[string]interface{})
+	walk = func(prefix string, m map[string]interface{}) {
+		for key, value := range m {
+			path := key
+			if prefix != "" {
+				path = prefix + keyDelimiter + key
+			}
+			if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+				walk(path, nested)
+				continue
+			}
+			keys = append(keys, path)
+		}
+	}
+	walk("", tree)
+	sort.Strings(keys)
+	return keys
+}
+
+// mapStrings applies fn to every string found in the tree, including strings
+// nested in slices, and returns the rewritten tree.
+func mapStrings(value interface{}, fn func(string) string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return fn(v)
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[key] = mapStrings(val, fn)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = mapStrings(val, fn)
+		}
+		return s
+	case []string:
+		s := make([]string, len(v))
+		for i, val := range v {
+			s[i] = fn(val)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+const (
+	commonSection = "common"
+	appsSection   = "apps"
+)
+
+// selectApp merges the common section and the section of the given
+// application into the top level of the tree. Trees which contain neither
+// of the sections are returned unchanged.
+func selectApp(tree map[string]interface{}, app string) map[string]interface{} {
+	common, hasCommon := tree[commonSection].(map[string]interface{})
+	apps, hasApps := tree[appsSection].(map[string]interface{})
+	if !hasCommon && !hasApps {
+		return tree
+	}
+
+	out := make(map[string]interface{}, len(tree))
+	for key, value := range tree {
+		if key != commonSection && key != appsSection {
+			out[key] = value
+		}
+	}
+
+	deepMerge(out, common)
+	if section, ok := apps[app].(map[string]interface{}); ok {
+		deepMerge(out, section)
+	}
+	return out
+}
+
+// selectAppPositions renames the positions of keys in the common section and
+// in the section of the application like selectApp renames the keys.
+func selectAppPositions(positions map[string]Position, app string) map[string]Position {
+	out := make(map[string]Position, len(positions))
+	for key, pos := range positions {
+		if key != commonSection && key != appsSection && !strings.HasPrefix(key, commonSection+keyDelimiter) && !strings.HasPrefix(key, appsSection+keyDelimiter) {
+			out[key] = pos
+		}
+	}
+	for _, prefix := range []string{commonSection + keyDelimiter, appsSection + keyDelimiter + app + keyDelimiter} {
+		for key, pos := range positions {
+			if rest, ok := strings.CutPrefix(key, prefix); ok {
+				out[rest] = pos
+			}
+		}
+	}
+	return out
+}
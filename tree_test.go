@@ -0,0 +1,63 @@
+package configwise
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetOrigin(t *testing.T) {
+	file := &mapProvider{name: "file"}
+	env := &mapProvider{name: "env"}
+
+	tests := []struct {
+		name     string
+		origins  map[string]*mapProvider
+		key      string
+		replaced interface{}
+		want     map[string]*mapProvider
+	}{
+		{
+			name:    "new key",
+			origins: map[string]*mapProvider{"a.b": file},
+			key:     "a.c",
+			want:    map[string]*mapProvider{"a.b": file, "a.c": env},
+		},
+		{
+			name:     "replaced value",
+			origins:  map[string]*mapProvider{"a.b": file},
+			key:      "a.b",
+			replaced: 1,
+			want:     map[string]*mapProvider{"a.b": env},
+		},
+		{
+			name:     "section replaced by a value",
+			origins:  map[string]*mapProvider{"a.b.c": file, "a.b.d.e": file, "a.bc": file},
+			key:      "a.b",
+			replaced: map[string]interface{}{"c": 1, "d": map[string]interface{}{"e": 2}},
+			want:     map[string]*mapProvider{"a.b": env, "a.bc": file},
+		},
+		{
+			name:    "value replaced by a section",
+			origins: map[string]*mapProvider{"a": file, "x": file},
+			key:     "a.b.c",
+			want:    map[string]*mapProvider{"a.b.c": env, "x": file},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			origins := make(map[string]Provider)
+			for key, p := range tt.origins {
+				origins[key] = p
+			}
+			setOrigin(origins, tt.key, tt.replaced, env)
+
+			want := make(map[string]Provider)
+			for key, p := range tt.want {
+				want[key] = p
+			}
+			if !reflect.DeepEqual(origins, want) {
+				t.Errorf("origins are %v, want %v", origins, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package configwise
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Unset reverts the values of the key and below it which were set with
+// Overwrite to the values of the providers, e.g. to undo a runtime change
+// from an admin UI. A key which was not overwritten is removed from the
+// settings until it is overwritten again. Unsets are journaled like overwrites.
+func (cfg *configurer) Unset(name string) error {
+	return cfg.UnsetContext(context.Background(), name)
+}
+
+// UnsetContext is like Unset, recording actor and request ID of the context
+// in the audit log.
+func (cfg *configurer) UnsetContext(ctx context.Context, name string) error {
+	key := cfg.canonicalKey(name)
+
+	cfg.writeMu.Lock()
+	if cfg.frozen.Load() {
+		cfg.writeMu.Unlock()
+		return &Error{Op: OpUnset, Err: ErrFrozen}
+	}
+	cfg.mu.RLock()
+	old, base, secrets, overrides := cfg.settings, cfg.base, cfg.secrets, cfg.overrides
+	cfg.mu.RUnlock()
+
+	overrides = unsetOverride(overrides, key)
+	settings := withOverrides(base, overrides)
+	if err := cfg.validateSchema(settings); err != nil {
+		cfg.writeMu.Unlock()
+		return &Error{Op: OpUnset, Err: err}
+	}
+
+	if cfg.journal != nil {
+		err := cfg.journal.append(journalEntry{
+			Time:      time.Now().UTC(),
+			Op:        "unset",
+			Values:    map[string]interface{}{key: nil},
+			Actor:     ActorFromContext(ctx),
+			RequestID: RequestIDFromContext(ctx),
+		})
+		if err != nil {
+			cfg.writeMu.Unlock()
+			return &Error{Op: OpUnset, Err: fmt.Errorf("journal: %w", err)}
+		}
+	}
+
+	cfg.mu.Lock()
+	cfg.settings, cfg.overrides = settings, overrides
+	cfg.record("unset")
+	cfg.mu.Unlock()
+	cfg.writeMu.Unlock()
+
+	cfg.auditChange(ctx, "unset", map[string]interface{}{key: nil})
+	cfg.publishChange("unset", old, settings, secrets)
+	return nil
+}
+
+// unsetOverride returns the overrides without those of the key and below it.
+// The key is removed from overrides of its sections. If no override set the
+// key, an override removing it is added.
+func unsetOverride(overrides []override, key string) []override {
+	kept := make([]override, 0, len(overrides)+1)
+	reverted := false
+	for _, o := range overrides {
+		switch {
+		case covers(key, o.key):
+			reverted = true
+			continue
+		case !o.unset && strings.HasPrefix(key, o.key+keyDelimiter):
+			if section, ok := o.value.(map[string]interface{}); ok {
+				section = deepCopy(section).(map[string]interface{})
+				deletePath(section, strings.TrimPrefix(key, o.key+keyDelimiter))
+				o.value = section
+			}
+		}
+		kept = append(kept, o)
+	}
+
+	if !reverted && key != "" {
+		kept = append(kept, override{key: key, unset: true})
+	}
+	return kept
+}
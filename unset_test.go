@@ -0,0 +1,112 @@
+package configwise
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnset(t *testing.T) {
+	const config = "server: {host: localhost, port: 80}\nname: app"
+
+	tests := []struct {
+		name      string
+		overwrite map[string]interface{}
+		unset     string
+		want      map[string]interface{}
+	}{
+		{
+			name:      "overwritten key",
+			overwrite: map[string]interface{}{"server.port": 8080, "name": "other"},
+			unset:     "Server.Port",
+			want: map[string]interface{}{
+				"server": map[string]interface{}{"host": "localhost", "port": 80},
+				"name":   "other",
+			},
+		},
+		{
+			name:      "added key",
+			overwrite: map[string]interface{}{"server.tls": true},
+			unset:     "server.tls",
+			want: map[string]interface{}{
+				"server": map[string]interface{}{"host": "localhost", "port": 80},
+				"name":   "app",
+			},
+		},
+		{
+			name:  "key of a provider",
+			unset: "name",
+			want: map[string]interface{}{
+				"server": map[string]interface{}{"host": "localhost", "port": 80},
+			},
+		},
+		{
+			name:      "section",
+			overwrite: map[string]interface{}{"server.port": 8080, "server.host": "example.com"},
+			unset:     "server",
+			want: map[string]interface{}{
+				"server": map[string]interface{}{"host": "localhost", "port": 80},
+				"name":   "app",
+			},
+		},
+		{
+			name:      "key of an overwritten section",
+			overwrite: map[string]interface{}{"server": map[string]interface{}{"host": "example.com", "port": 8080}},
+			unset:     "server.port",
+			want: map[string]interface{}{
+				"server": map[string]interface{}{"host": "example.com"},
+				"name":   "app",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewConfigurer(WithType("yaml"), WithReadInConfig([]byte(config)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.overwrite != nil {
+				if err := c.Overwrite(tt.overwrite); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := c.Unset(tt.unset); err != nil {
+				t.Fatal(err)
+			}
+			if got := c.Settings(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("settings are %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnsetAndOverwrite(t *testing.T) {
+	c, err := NewConfigurer(WithConfigMap(map[string]interface{}{"name": "app"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Unset("name"); err != nil {
+		t.Fatal(err)
+	}
+	if c.Has("name") {
+		t.Fatal("name is set after Unset")
+	}
+	if err := c.Overwrite(map[string]interface{}{"name": "other"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.GetString("name"); got != "other" {
+		t.Errorf("name is %q after an overwrite of the unset key, want other", got)
+	}
+	if err := c.Unset("name"); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.GetString("name"); got != "app" {
+		t.Errorf("name is %q after the second unset, want app", got)
+	}
+
+	c.Freeze()
+	if err := c.Unset("name"); err == nil || !strings.Contains(err.Error(), "configurer: unset -> configuration is frozen") {
+		t.Errorf("error is %v, want %q", err, "configurer: unset -> configuration is frozen")
+	}
+}
@@ -0,0 +1,122 @@
+package configwise
+
+import (
+	"strings"
+	"sync"
+)
+
+// WithUsageTracking records the keys consumed by Get, UnmarshalKey and
+// Unmarshal, which UnusedKeys reports the others of. Recording serializes the
+// first access of every key, so it is off by default.
+func WithUsageTracking() Option {
+	return func(c *configurer) {
+		c.usageTracking = true
+	}
+}
+
+// usage records which keys were consumed.
+type usage struct {
+	mu sync.RWMutex
+	// keys consumed including all keys below them
+	used map[string]struct{}
+	// keys below a consumed key which the decoder did not use
+	ignored map[string]struct{}
+}
+
+// markUsed records the key as consumed. Keys reported as unused by the
+// decoder, relative to key, are excluded unless they were consumed directly.
+func (cfg *configurer) markUsed(key string, unused []string) {
+	if !cfg.usageTracking {
+		return
+	}
+
+	u := cfg.usage
+	// repeated reads of a key only share the read lock
+	u.mu.RLock()
+	_, used := u.used[key]
+	done := used && len(unused) == 0 && len(u.ignored) == 0
+	u.mu.RUnlock()
+	if done {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.used == nil {
+		u.used = make(map[string]struct{})
+		u.ignored = make(map[string]struct{})
+	}
+	u.used[key] = struct{}{}
+
+	if len(unused) == 0 {
+		for k := range u.ignored {
+			if covers(key, k) {
+				delete(u.ignored, k)
+			}
+		}
+		return
+	}
+
+	for _, k := range unused {
+		k = strings.ToLower(k)
+		if key != "" {
+			k = key + keyDelimiter + k
+		}
+		direct := false
+		for used := range u.used {
+			if len(used) > len(key) && covers(used, k) {
+				direct = true
+				break
+			}
+		}
+		if !direct {
+			u.ignored[k] = struct{}{}
+		}
+	}
+}
+
+// covers reports whether key equals parent or is below it. The empty key covers all keys.
+func covers(parent, key string) bool {
+	return parent == "" || key == parent || strings.HasPrefix(key, parent+keyDelimiter)
+}
+
+// UnusedKeys returns the sorted keys of the settings which were never
+// consumed by Get, UnmarshalKey or Unmarshal, to detect dead configuration.
+// Keys within free-form sections are never reported. Without
+// WithUsageTracking no keys are recorded and UnusedKeys returns nil.
+func (cfg *configurer) UnusedKeys() []string {
+	if !cfg.usageTracking {
+		return nil
+	}
+
+	cfg.mu.RLock()
+	settings := cfg.settings
+	cfg.mu.RUnlock()
+
+	u := cfg.usage
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	var unused []string
+	for _, key := range leafKeys(settings) {
+		if !u.isUsed(key) && !cfg.isFreeForm(key) {
+			unused = append(unused, key)
+		}
+	}
+	return unused
+}
+
+func (u *usage) isUsed(key string) bool {
+	for k := range u.ignored {
+		if covers(k, key) {
+			return false
+		}
+	}
+	for k := range u.used {
+		if covers(k, key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package configwise
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnusedKeys(t *testing.T) {
+	const config = `
+server:
+  host: localhost
+  port: 80
+  legacy: true
+db:
+  url: postgres://
+extra:
+  anything: 1
+name: app
+`
+	type server struct {
+		Host string
+		Port int
+	}
+
+	tests := []struct {
+		name    string
+		options []Option
+		consume func(t *testing.T, c Configurer)
+		want    []string
+	}{
+		{
+			name:    "without tracking",
+			options: nil,
+			consume: func(t *testing.T, c Configurer) {},
+			want:    nil,
+		},
+		{
+			name:    "nothing consumed",
+			options: []Option{WithUsageTracking()},
+			consume: func(t *testing.T, c Configurer) {},
+			want:    []string{"db.url", "extra.anything", "name", "server.host", "server.legacy", "server.port"},
+		},
+		{
+			name:    "get",
+			options: []Option{WithUsageTracking()},
+			consume: func(t *testing.T, c Configurer) {
+				c.GetString("name")
+				c.Get("db")
+			},
+			want: []string{"extra.anything", "server.host", "server.legacy", "server.port"},
+		},
+		{
+			name:    "fields unknown to the struct",
+			options: []Option{WithUsageTracking()},
+			consume: func(t *testing.T, c Configurer) {
+				var s server
+				if err := c.UnmarshalKey("server", &s); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: []string{"db.url", "extra.anything", "name", "server.legacy"},
+		},
+		{
+			name:    "read directly after the struct",
+			options: []Option{WithUsageTracking()},
+			consume: func(t *testing.T, c Configurer) {
+				var s server
+				if err := c.UnmarshalKey("server", &s); err != nil {
+					t.Fatal(err)
+				}
+				c.GetBool("server.legacy")
+			},
+			want: []string{"db.url", "extra.anything", "name"},
+		},
+		{
+			name:    "free-form sections",
+			options: []Option{WithUsageTracking(), WithFreeForm("extra")},
+			consume: func(t *testing.T, c Configurer) {
+				var all map[string]interface{}
+				if err := c.Unmarshal(&all); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]Option{WithType("yaml"), WithReadInConfig([]byte(config))}, tt.options...)
+			c, err := NewConfigurer(options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.consume(t, c)
+			if got := c.UnusedKeys(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("unused keys are %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnusedKeysOfSub(t *testing.T) {
+	c, err := NewConfigurer(
+		WithUsageTracking(),
+		WithType("yaml"),
+		WithReadInConfig([]byte("server: {host: localhost, port: 80}\nname: app")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := c.Sub("server")
+	server.GetString("host")
+	if got, want := server.UnusedKeys(), []string{"port"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unused keys of the sub configurer are %v, want %v", got, want)
+	}
+	if got, want := c.UnusedKeys(), []string{"name", "server.port"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unused keys are %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,202 @@
+package configwise
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// validateTag holds the validation rules of a field, e.g. `validate:"min=1"`.
+const validateTag = "validate"
+
+// ValidationError is a violation of a validate struct tag.
+type ValidationError struct {
+	// Key is the full config key of the field.
+	Key string
+	// Tag is the violated rule, e.g. "min".
+	Tag string
+	// Param is the parameter of the rule, e.g. "1s" of "min=1s".
+	Param string
+
+	// lang is the language of the message
+	lang string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Key + ": " + e.message()
+}
+
+func (e *ValidationError) message() string {
+	switch e.Tag {
+	case "required":
+		return translate(e.lang, MsgValidationRequired)
+	case "min", "gte":
+		return translate(e.lang, MsgValidationMin, e.Param)
+	case "max", "lte":
+		return translate(e.lang, MsgValidationMax, e.Param)
+	case "gt":
+		return translate(e.lang, MsgValidationGreater, e.Param)
+	case "lt":
+		return translate(e.lang, MsgValidationLess, e.Param)
+	case "len":
+		return translate(e.lang, MsgValidationLen, e.Param)
+	case "oneof":
+		return translate(e.lang, MsgValidationOneOf, strings.ReplaceAll(e.Param, " ", ", "))
+	default:
+		if e.Param != "" {
+			return translate(e.lang, MsgValidationRuleParam, e.Tag, e.Param)
+		}
+		return translate(e.lang, MsgValidationRule, e.Tag)
+	}
+}
+
+// squashedName names squashed structs in namespaces of validation errors.
+const squashedName = "\x00"
+
+// WithValidation validates the targets of Unmarshal and UnmarshalKey with
+// the validate struct tags of github.com/go-playground/validator after
+// decoding, e.g. Timeout time.Duration `validate:"min=1s"`. All violations
+// are reported at once as ValidationError with the full config key.
+func WithValidation() Option {
+	return func(c *configurer) {
+		v := validator.New(validator.WithRequiredStructEnabled())
+		v.SetTagName(validateTag)
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, squash := fieldName(field)
+			switch {
+			case name == "":
+				return "-"
+			case squash:
+				return squashedName
+			}
+			return name
+		})
+		c.validator = v
+	}
+}
+
+// validate validates the decoded target, reporting keys below prefix.
+func (cfg *configurer) validate(prefix string, out interface{}) error {
+	if cfg.validator == nil {
+		return nil
+	}
+
+	t := reflect.TypeOf(out)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	err := cfg.validator.Struct(out)
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return err
+	}
+
+	errs := make([]error, len(fieldErrs))
+	for i, fe := range fieldErrs {
+		segments := strings.Split(fe.Namespace(), ".")
+		// the namespace starts with the name of named struct types
+		if t.Name() != "" {
+			segments = segments[1:]
+		}
+
+		key := prefix
+		for _, segment := range segments {
+			if segment == squashedName {
+				continue
+			}
+			if key != "" {
+				key += keyDelimiter
+			}
+			key += segment
+		}
+		errs[i] = &ValidationError{Key: key, Tag: fe.Tag(), Param: fe.Param(), lang: cfg.lang}
+	}
+	return errors.Join(errs...)
+}
+
+// validatable is implemented by config structs which validate themselves.
+type validatable interface {
+	Validate() error
+}
+
+// callValidate calls Validate on the decoded target and on every nested
+// value implementing it, nested values first. Failures are wrapped with
+// their config key below prefix.
+func callValidate(prefix string, out interface{}) error {
+	var errs []error
+	var walk func(key string, v reflect.Value)
+	walk = func(key string, v reflect.Value) {
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return
+			}
+			if v.Kind() == reflect.Pointer && v.Elem().Kind() != reflect.Struct {
+				break
+			}
+			v = v.Elem()
+		}
+
+		switch v.Kind() {
+		case reflect.Struct:
+			if isLeafStruct(v.Type()) {
+				break
+			}
+			for i := 0; i < v.NumField(); i++ {
+				field := v.Type().Field(i)
+				if !field.IsExported() {
+					continue
+				}
+				name, squash := fieldName(field)
+				switch {
+				case name == "":
+					continue
+				case squash:
+					walk(key, v.Field(i))
+				default:
+					walk(joinKey(key, name), v.Field(i))
+				}
+			}
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < v.Len(); i++ {
+				walk(fmt.Sprintf("%s[%d]", key, i), v.Index(i))
+			}
+		case reflect.Map:
+			iter := v.MapRange()
+			for iter.Next() {
+				walk(joinKey(key, strings.ToLower(fmt.Sprint(iter.Key().Interface()))), iter.Value())
+			}
+		}
+
+		var target interface{}
+		switch {
+		case v.CanAddr() && v.Addr().CanInterface():
+			target = v.Addr().Interface()
+		case v.CanInterface():
+			target = v.Interface()
+		}
+		if val, ok := target.(validatable); ok {
+			if err := val.Validate(); err != nil {
+				if key != "" {
+					err = &KeyError{Key: key, Err: err}
+				}
+				errs = append(errs, err)
+			}
+		}
+	}
+	walk(prefix, reflect.ValueOf(out))
+	return errors.Join(errs...)
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + keyDelimiter + key
+}
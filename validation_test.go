@@ -0,0 +1,142 @@
+package configwise
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type validatedLimits struct {
+	Burst int `validate:"lte=100"`
+}
+
+type validatedServer struct {
+	validatedLimits `cfg:",squash"`
+
+	Host    string        `validate:"required,hostname"`
+	Port    int           `cfg:"listen-port" validate:"gt=0,lt=65536"`
+	Timeout time.Duration `validate:"min=1s,max=1m"`
+	Mode    string        `validate:"oneof=dev prod"`
+	Tags    []string      `validate:"len=2"`
+}
+
+func TestValidation(t *testing.T) {
+	valid := map[string]interface{}{
+		"host":        "localhost",
+		"listen-port": 80,
+		"timeout":     "5s",
+		"mode":        "dev",
+		"tags":        []interface{}{"a", "b"},
+		"burst":       10,
+	}
+
+	tests := []struct {
+		name    string
+		changes map[string]interface{}
+		err     []ValidationError
+	}{
+		{name: "valid"},
+		{
+			name:    "required",
+			changes: map[string]interface{}{"host": ""},
+			err:     []ValidationError{{Key: "server.host", Tag: "required"}},
+		},
+		{
+			name:    "bounds",
+			changes: map[string]interface{}{"listen-port": 0, "timeout": "2m"},
+			err:     []ValidationError{{Key: "server.listen-port", Tag: "gt", Param: "0"}, {Key: "server.timeout", Tag: "max", Param: "1m"}},
+		},
+		{
+			name:    "oneof and len",
+			changes: map[string]interface{}{"mode": "test", "tags": []interface{}{"a"}},
+			err:     []ValidationError{{Key: "server.mode", Tag: "oneof", Param: "dev prod"}, {Key: "server.tags", Tag: "len", Param: "2"}},
+		},
+		{
+			name:    "squashed struct",
+			changes: map[string]interface{}{"burst": 1000},
+			err:     []ValidationError{{Key: "server.burst", Tag: "lte", Param: "100"}},
+		},
+		{
+			name:    "other rule",
+			changes: map[string]interface{}{"host": "not a host"},
+			err:     []ValidationError{{Key: "server.host", Tag: "hostname"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := make(map[string]interface{})
+			for key, value := range valid {
+				server[key] = value
+			}
+			for key, value := range tt.changes {
+				server[key] = value
+			}
+			c, err := NewConfigurer(WithValidation(), WithConfigMap(map[string]interface{}{"server": server}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = c.UnmarshalKey("server", new(validatedServer))
+			var got []ValidationError
+			for _, e := range unwrapJoined(err) {
+				var ve *ValidationError
+				if !errors.As(e, &ve) {
+					t.Fatalf("error %v is no ValidationError", e)
+				}
+				got = append(got, ValidationError{Key: ve.Key, Tag: ve.Tag, Param: ve.Param})
+			}
+			if len(got) != len(tt.err) {
+				t.Fatalf("errors are %v, want %v", got, tt.err)
+			}
+			for i := range tt.err {
+				if got[i] != tt.err[i] {
+					t.Errorf("error %d is %+v, want %+v", i, got[i], tt.err[i])
+				}
+			}
+		})
+	}
+}
+
+// unwrapJoined returns the errors joined in the error of an operation.
+func unwrapJoined(err error) []error {
+	for err != nil {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			return joined.Unwrap()
+		}
+		err = errors.Unwrap(err)
+	}
+	return nil
+}
+
+func TestValidationMessages(t *testing.T) {
+	c, err := NewConfigurer(WithValidation(), WithConfigMap(map[string]interface{}{
+		"host": "not a host", "listen-port": 70000, "timeout": "1ms", "mode": "test", "tags": []interface{}{},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Unmarshal(new(validatedServer))
+	for _, want := range []string{
+		"host: must satisfy hostname",
+		"listen-port: must be < 65536",
+		"timeout: must be >= 1s",
+		"mode: must be one of [dev, prod]",
+		"tags: must have length 2",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error is %v, want %q", err, want)
+		}
+	}
+}
+
+func TestWithoutValidation(t *testing.T) {
+	c, err := NewConfigurer(WithConfigMap(map[string]interface{}{"listen-port": -1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Unmarshal(new(validatedServer)); err != nil {
+		t.Errorf("error is %v without WithValidation", err)
+	}
+}
@@ -0,0 +1,201 @@
+package configwise
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const vaultTransitPrefix = "vault-transit:"
+
+// VaultTransitConfig configures the decryption of vault-transit: values.
+type VaultTransitConfig struct {
+	// Address of the Vault server, e.g. https://vault:8200.
+	Address string
+	Token   string
+	// Mount is the path of the transit engine, "transit" by default.
+	Mount     string
+	Namespace string
+	Client    *http.Client
+	// Timeout of a single decryption, 10 seconds by default.
+	Timeout time.Duration
+	// CacheTTL is how long plaintexts are cached, 5 minutes by default, so
+	// that rotated or revoked keys take effect. A negative TTL disables
+	// the cache.
+	CacheTTL time.Duration
+	// CacheSize is the maximum number of cached plaintexts, 1024 by default.
+	// The least recently used plaintexts are evicted first.
+	CacheSize int
+}
+
+// WithVaultTransit decrypts values written as vault-transit:<key>:<ciphertext>,
+// e.g. vault-transit:payments:vault:v1:AbC..., with the transit engine of Vault.
+// Decryption is lazy: the settings only hold the ciphertext and the plaintext is
+// fetched on access by Get or Unmarshal and cached for the CacheTTL. Failed
+// decryptions fail Unmarshal and UnmarshalKey; Get returns nil for them and
+// reports a WarningUnresolvedValue.
+func WithVaultTransit(config VaultTransitConfig) Option {
+	if config.Mount == "" {
+		config.Mount = "transit"
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.CacheTTL == 0 {
+		config.CacheTTL = 5 * time.Minute
+	}
+	if config.CacheSize <= 0 {
+		config.CacheSize = 1024
+	}
+	transit := &vaultTransit{config: config, cache: newPlaintextCache(config.CacheSize, config.CacheTTL)}
+
+	return func(c *configurer) {
+		c.schemes = append(c.schemes, valueScheme{
+			name:    "vault-transit",
+			secret:  true,
+			lazy:    true,
+			match:   transit.match,
+			resolve: transit.decrypt,
+		})
+	}
+}
+
+type vaultTransit struct {
+	config VaultTransitConfig
+	// cache of plaintexts by value, ciphertexts of Vault are unique
+	cache *plaintextCache
+}
+
+func (v *vaultTransit) match(s string) bool {
+	return strings.HasPrefix(s, vaultTransitPrefix)
+}
+
+func (v *vaultTransit) decrypt(s string) (interface{}, error) {
+	if plaintext, ok := v.cache.get(s); ok {
+		return plaintext, nil
+	}
+
+	key, ciphertext, ok := strings.Cut(strings.TrimPrefix(s, vaultTransitPrefix), ":")
+	if !ok || key == "" || ciphertext == "" {
+		return nil, errors.New("expected vault-transit:<key>:<ciphertext>")
+	}
+
+	body, err := json.Marshal(map[string]string{"ciphertext": ciphertext})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), v.config.Timeout)
+	defer cancel()
+
+	endpoint := strings.TrimSuffix(v.config.Address, "/") + "/v1/" + strings.Trim(v.config.Mount, "/") + "/decrypt/" + url.PathEscape(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", v.config.Token)
+	if v.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.config.Namespace)
+	}
+
+	resp, err := v.config.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var result struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	plaintext, err := base64.StdEncoding.DecodeString(result.Data.Plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	value := string(plaintext)
+	v.cache.put(s, value)
+	return value, nil
+}
+
+// plaintextCache is a least recently used cache of plaintexts which expire
+// after the ttl.
+type plaintextCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type plaintextEntry struct {
+	value   string
+	plain   string
+	expires time.Time
+}
+
+func newPlaintextCache(size int, ttl time.Duration) *plaintextCache {
+	return &plaintextCache{size: size, ttl: ttl, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (c *plaintextCache) get(value string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[value]
+	if !ok {
+		return "", false
+	}
+	entry := elem.Value.(*plaintextEntry)
+	if !time.Now().Before(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, value)
+		return "", false
+	}
+	c.order.MoveToFront(elem)
+	return entry.plain, true
+}
+
+func (c *plaintextCache) put(value, plain string) {
+	if c.ttl < 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &plaintextEntry{value: value, plain: plain, expires: time.Now().Add(c.ttl)}
+	if elem, ok := c.entries[value]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[value] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*plaintextEntry).value)
+	}
+}
@@ -0,0 +1,174 @@
+package configwise
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// vaultServer serves the decrypt endpoint of the transit engine, decrypting
+// ciphertexts "vault:v1:<base64>" of the key "payments".
+func vaultServer(t *testing.T, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("X-Vault-Token") != "token" || r.Header.Get("X-Vault-Namespace") != "team" {
+			http.Error(w, "permission denied", http.StatusForbidden)
+			return
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/secrets/transit/decrypt/payments" {
+			http.NotFound(w, r)
+			return
+		}
+		var body struct {
+			Ciphertext string `json:"ciphertext"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || !strings.HasPrefix(body.Ciphertext, "vault:v1:") {
+			http.Error(w, "invalid ciphertext", http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]string{"plaintext": strings.TrimPrefix(body.Ciphertext, "vault:v1:")},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVaultTransit(t *testing.T) {
+	var requests atomic.Int32
+	server := vaultServer(t, &requests)
+	secret := base64.StdEncoding.EncodeToString([]byte("s3cret"))
+
+	tests := []struct {
+		name   string
+		config VaultTransitConfig
+		value  string
+		want   interface{}
+		err    string
+	}{
+		{
+			name:   "decrypt",
+			config: VaultTransitConfig{Token: "token", Namespace: "team", Mount: "/secrets/transit/"},
+			value:  "vault-transit:payments:vault:v1:" + secret,
+			want:   "s3cret",
+		},
+		{
+			name:   "denied",
+			config: VaultTransitConfig{Token: "other", Namespace: "team", Mount: "secrets/transit"},
+			value:  "vault-transit:payments:vault:v1:" + secret,
+			err:    "vault-transit: unexpected status 403 Forbidden",
+		},
+		{
+			name:   "invalid ciphertext",
+			config: VaultTransitConfig{Token: "token", Namespace: "team", Mount: "secrets/transit"},
+			value:  "vault-transit:payments:" + secret,
+			err:    "vault-transit: unexpected status 400 Bad Request",
+		},
+		{
+			name:   "missing key",
+			config: VaultTransitConfig{Token: "token", Namespace: "team", Mount: "secrets/transit"},
+			value:  "vault-transit:payments",
+			err:    "expected vault-transit:<key>:<ciphertext>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Address = server.URL
+			c, err := NewConfigurer(
+				WithVaultTransit(tt.config),
+				WithConfigMap(map[string]interface{}{"db": map[string]interface{}{"password": tt.value}}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var db struct{ Password string }
+			err = c.UnmarshalKey("db", &db)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error is %v, want %q", err, tt.err)
+				}
+				// the ciphertext is never returned in place of the plaintext
+				if got := c.Get("db.password"); got != nil {
+					t.Errorf("db.password is %#v, want nil", got)
+				}
+				warnings := c.Warnings()
+				if len(warnings) != 1 || warnings[0].Kind != WarningUnresolvedValue || !strings.Contains(warnings[0].Name, tt.err) {
+					t.Errorf("warnings are %v, want a warning of the unresolved value", warnings)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if db.Password != tt.want {
+				t.Errorf("db.password is %q, want %q", db.Password, tt.want)
+			}
+			if got := c.Get("db.password"); got != tt.want {
+				t.Errorf("db.password is %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVaultTransitIsLazyAndCached(t *testing.T) {
+	var requests atomic.Int32
+	server := vaultServer(t, &requests)
+	value := "vault-transit:payments:vault:v1:" + base64.StdEncoding.EncodeToString([]byte("s3cret"))
+
+	c, err := NewConfigurer(
+		WithVaultTransit(VaultTransitConfig{Address: server.URL, Token: "token", Namespace: "team", Mount: "secrets/transit"}),
+		WithConfigMap(map[string]interface{}{"password": value}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Fatalf("%d requests on load, want none before the first access", n)
+	}
+
+	for i := 0; i < 3; i++ {
+		if got := c.GetString("password"); got != "s3cret" {
+			t.Fatalf("password is %q, want s3cret", got)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("%d requests for 3 reads, want 1", n)
+	}
+}
+
+func TestPlaintextCache(t *testing.T) {
+	cache := newPlaintextCache(2, time.Hour)
+	cache.put("a", "1")
+	cache.put("b", "2")
+	cache.get("a")
+	cache.put("c", "3")
+
+	if _, ok := cache.get("b"); ok {
+		t.Error("b is cached, want the least recently used value evicted")
+	}
+	for value, want := range map[string]string{"a": "1", "c": "3"} {
+		if got, ok := cache.get(value); !ok || got != want {
+			t.Errorf("%s is %q, %t, want %q", value, got, ok, want)
+		}
+	}
+
+	expired := newPlaintextCache(2, time.Millisecond)
+	expired.put("a", "1")
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := expired.get("a"); ok {
+		t.Error("a is cached after its ttl")
+	}
+
+	disabled := newPlaintextCache(2, -1)
+	disabled.put("a", "1")
+	if _, ok := disabled.get("a"); ok {
+		t.Error("a is cached with a negative ttl")
+	}
+}
@@ -0,0 +1,207 @@
+package configwise
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WarningKind classifies a warning.
+type WarningKind string
+
+const (
+	// WarningUndefinedVariable is reported for ${NAME} or ${var:name} without
+	// a value and without a default.
+	WarningUndefinedVariable WarningKind = "undefined_variable"
+	// WarningUnresolvedReference is reported for references of an unknown
+	// kind, like ${cfg:db.host}, which are replaced by an empty string.
+	WarningUnresolvedReference WarningKind = "unresolved_reference"
+	// WarningUnexpanded is reported for values which still contain ${ after expansion.
+	WarningUnexpanded WarningKind = "unexpanded"
+	// WarningDecodeFallback is reported by Unmarshal and UnmarshalKey for
+	// values a lenient decode hook failed to convert, the hook is the name.
+	WarningDecodeFallback WarningKind = "decode_fallback"
+	// WarningDeprecatedKey is reported for deprecated keys which are set or
+	// read, the new key is the name.
+	WarningDeprecatedKey WarningKind = "deprecated_key"
+	// WarningDuplicateKey is reported with WithLenientDuplicateKeys for keys
+	// defined twice in a mapping, the position of the ignored definition is the name.
+	WarningDuplicateKey WarningKind = "duplicate_key"
+	// WarningTornJournalEntry is reported for an incomplete last entry of the
+	// journal, left by a crash while appending, which was dropped. The
+	// position of the entry is the name.
+	WarningTornJournalEntry WarningKind = "torn_journal_entry"
+	// WarningUnresolvedValue is reported by Get and the typed getters for
+	// values which failed to resolve on access, like vault-transit: values
+	// Vault did not decrypt, which read as nil. The error is the name.
+	WarningUnresolvedValue WarningKind = "unresolved_value"
+)
+
+// Warning describes a misconfiguration which did not fail the load.
+type Warning struct {
+	Kind WarningKind
+	// Key is the key of the affected value.
+	Key string
+	// Name is the variable or reference, if any.
+	Name string
+}
+
+func (w Warning) String() string {
+	return w.Message(DefaultLanguage)
+}
+
+// Message returns the description of the warning in the language, e.g. to
+// show it in an admin UI, falling back to English.
+func (w Warning) Message(lang string) string {
+	lang = normalizeLanguage(lang)
+	switch w.Kind {
+	case WarningUndefinedVariable:
+		return translate(lang, MsgUndefinedVariable, w.Key, w.Name)
+	case WarningUnresolvedReference:
+		return translate(lang, MsgUnresolvedReference, w.Key, w.Name)
+	case WarningDeprecatedKey:
+		return translate(lang, MsgDeprecatedKey, w.Key, w.Name)
+	case WarningDuplicateKey:
+		return translate(lang, MsgDuplicateKey, w.Key, w.Name)
+	case WarningDecodeFallback:
+		return translate(lang, MsgDecodeFallback, w.Key, w.Name)
+	case WarningTornJournalEntry:
+		return translate(lang, MsgTornJournalEntry, w.Name)
+	case WarningUnresolvedValue:
+		return translate(lang, MsgUnresolvedValue, w.Key, w.Name)
+	default:
+		return translate(lang, MsgUnexpanded, w.Key)
+	}
+}
+
+// WithFailOnWarnings makes NewConfigurer fail when the initial load reports
+// warnings, e.g. undefined variables, so misconfigurations are caught on
+// deploy. Warnings of reloads are reported by Warnings only.
+func WithFailOnWarnings() Option {
+	return func(c *configurer) {
+		c.failOnWarnings = true
+	}
+}
+
+func (cfg *configurer) Warnings() []Warning {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+
+	return append([]Warning(nil), cfg.warnings...)
+}
+
+// addWarnings records warnings raised after the load. They are kept until
+// the next reload.
+func (cfg *configurer) addWarnings(warnings ...Warning) {
+	if len(warnings) == 0 {
+		return
+	}
+
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+
+	for _, w := range warnings {
+		if !slices.Contains(cfg.warnings, w) {
+			cfg.warnings = append(cfg.warnings, w)
+		}
+	}
+}
+
+func warningsError(lang string, warnings []Warning) error {
+	errs := make([]error, len(warnings))
+	for i, w := range warnings {
+		errs[i] = errors.New(w.Message(lang))
+	}
+	return errors.Join(errs...)
+}
+
+// expandTree expands the variables of every string in the tree and reports
+// undefined variables, unresolved references and values left unexpanded.
+// Errors of resolvers fail the expansion.
+func (cfg *configurer) expandTree(tree map[string]interface{}) (map[string]interface{}, []Warning, error) {
+	var (
+		warnings []Warning
+		errs     []error
+	)
+	var walk func(key string, value interface{}) interface{}
+	walk = func(key string, value interface{}) interface{} {
+		switch v := value.(type) {
+		case string:
+			expanded, w, err := cfg.expandString(key, v)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			warnings = append(warnings, w...)
+			return expanded
+		case map[string]interface{}:
+			m := make(map[string]interface{}, len(v))
+			for k, val := range v {
+				child := k
+				if key != "" {
+					child = key + keyDelimiter + k
+				}
+				m[k] = walk(child, val)
+			}
+			return m
+		case []interface{}:
+			s := make([]interface{}, len(v))
+			for i, val := range v {
+				s[i] = walk(key+"["+strconv.Itoa(i)+"]", val)
+			}
+			return s
+		case []string:
+			s := make([]string, len(v))
+			for i, val := range v {
+				s[i] = walk(key+"["+strconv.Itoa(i)+"]", val).(string)
+			}
+			return s
+		default:
+			return v
+		}
+	}
+	expanded := walk("", tree).(map[string]interface{})
+	if len(errs) > 0 {
+		return nil, nil, errors.Join(errs...)
+	}
+	sort.Slice(warnings, func(i, j int) bool {
+		if warnings[i].Key != warnings[j].Key {
+			return warnings[i].Key < warnings[j].Key
+		}
+		return warnings[i].Name < warnings[j].Name
+	})
+	return expanded, warnings, nil
+}
+
+// resolverChain returns the resolvers of config values.
+func (cfg *configurer) resolverChain() []Resolver {
+	chain := []Resolver{EnvResolver()}
+	if cfg.dotEnv != nil {
+		chain = append(chain, cfg.dotEnv.resolver())
+	}
+	return append(append(chain, VarsResolver(cfg.vars)), cfg.resolvers...)
+}
+
+// expandString expands the variables of a single value of the key.
+func (cfg *configurer) expandString(key, val string) (string, []Warning, error) {
+	var warnings []Warning
+	// tcp://127.0.0.1:${RPC_PORT:-36643}
+	// for envs like this, part would be tcp://127.0.0.1:
+	expanded, err := expand(val, cfg.resolverChain(), func(name string, known bool) {
+		kind := WarningUndefinedVariable
+		if !known {
+			kind = WarningUnresolvedReference
+		}
+		warnings = append(warnings, Warning{Kind: kind, Key: key, Name: name})
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", key, err)
+	}
+
+	if strings.Contains(expanded, "${") {
+		warnings = append(warnings, Warning{Kind: WarningUnexpanded, Key: key})
+	}
+	return expanded, warnings, nil
+}
@@ -0,0 +1,53 @@
+package configwise
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFailOnWarnings(t *testing.T) {
+	const config = "db: {host: '${CONFIGWISE_TEST_UNDEFINED}'}"
+
+	tests := []struct {
+		name    string
+		options []Option
+		fail    bool
+	}{
+		{name: "default"},
+		{name: "strict", options: []Option{WithStrict()}},
+		{name: "fail on warnings", options: []Option{WithFailOnWarnings()}, fail: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]Option{WithType("yaml"), WithReadInConfig([]byte(config))}, tt.options...)
+			c, err := NewConfigurer(options...)
+			if tt.fail {
+				if err == nil || !strings.Contains(err.Error(), "db.host: undefined variable CONFIGWISE_TEST_UNDEFINED") {
+					t.Fatalf("error is %v, want the undefined variable", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.Warnings(); len(got) != 1 || got[0].Kind != WarningUndefinedVariable {
+				t.Fatalf("warnings are %v, want the undefined variable", got)
+			}
+		})
+	}
+}
+
+func TestStrictKeepsLenientDuplicates(t *testing.T) {
+	c, err := NewConfigurer(
+		WithType("yaml"),
+		WithStrict(),
+		WithLenientDuplicateKeys(),
+		WithReadInConfig([]byte("port: 1\nport: 2\n")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Warnings(); len(got) != 1 || got[0].Kind != WarningDuplicateKey {
+		t.Fatalf("warnings are %v, want the duplicate key", got)
+	}
+}
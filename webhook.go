@@ -0,0 +1,158 @@
+package configwise
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SignatureHeader carries the HMAC-SHA256 signature of a webhook payload.
+const SignatureHeader = "X-Configwise-Signature"
+
+// WebhookPayload is the JSON document posted to webhooks after a change.
+type WebhookPayload struct {
+	// Source is the trigger of the change:
+	//   - "reload": the providers were read again, by Watch or a refresh
+	//   - "overwrite": Overwrite, OverwriteContext or OverwriteMerge
+	//   - "patch": ApplyPatch or ApplyPatchContext
+	//   - "unset": Unset or UnsetContext
+	//   - "rollback": Rollback or RollbackContext
+	Source      string        `json:"source"`
+	Fingerprint string        `json:"fingerprint"`
+	Timestamp   time.Time     `json:"timestamp"`
+	Changes     ChangeSummary `json:"changes"`
+}
+
+// ChangeSummary lists the modified keys without exposing their values.
+type ChangeSummary struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Updated []string `json:"updated"`
+}
+
+// WebhookOption configures a webhook.
+type WebhookOption func(*webhook)
+
+// WithWebhookSecret signs payloads with HMAC-SHA256; the signature is sent
+// as "sha256=<hex>" in the X-Configwise-Signature header.
+func WithWebhookSecret(secret []byte) WebhookOption {
+	return func(w *webhook) {
+		w.secret = secret
+	}
+}
+
+// WithWebhookClient replaces the default HTTP client.
+func WithWebhookClient(client *http.Client) WebhookOption {
+	return func(w *webhook) {
+		w.client = client
+	}
+}
+
+// WithWebhookTimeout limits the duration of a delivery, 10 seconds by default.
+func WithWebhookTimeout(timeout time.Duration) WebhookOption {
+	return func(w *webhook) {
+		w.timeout = timeout
+	}
+}
+
+// WithWebhookErrorHandler receives failed deliveries.
+func WithWebhookErrorHandler(fn func(error)) WebhookOption {
+	return func(w *webhook) {
+		w.onError = fn
+	}
+}
+
+// WithWebhook posts a signed JSON payload to the URL after every change of
+// the configuration, by a reload or by one of the mutations listed for
+// WebhookPayload.Source. Deliveries are asynchronous and never block the
+// change itself.
+func WithWebhook(url string, options ...WebhookOption) Option {
+	w := &webhook{
+		url:     url,
+		client:  http.DefaultClient,
+		timeout: 10 * time.Second,
+	}
+	for _, opt := range options {
+		opt(w)
+	}
+
+	return func(c *configurer) {
+		c.changeHooks = append(c.changeHooks, w.handle)
+	}
+}
+
+type webhook struct {
+	url     string
+	secret  []byte
+	client  *http.Client
+	timeout time.Duration
+	onError func(error)
+}
+
+func (w *webhook) handle(event changeEvent) {
+	if len(event.changes) == 0 {
+		return
+	}
+
+	payload := WebhookPayload{
+		Source:      event.trigger,
+		Fingerprint: fingerprint(event.new),
+		Timestamp:   time.Now().UTC(),
+		Changes:     ChangeSummary{Added: []string{}, Removed: []string{}, Updated: []string{}},
+	}
+	for _, change := range event.changes {
+		switch {
+		case change.added:
+			payload.Changes.Added = append(payload.Changes.Added, change.key)
+		case change.removed:
+			payload.Changes.Removed = append(payload.Changes.Removed, change.key)
+		default:
+			payload.Changes.Updated = append(payload.Changes.Updated, change.key)
+		}
+	}
+
+	go func() {
+		if err := w.send(payload); err != nil && w.onError != nil {
+			w.onError(err)
+		}
+	}()
+}
+
+func (w *webhook) send(payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), w.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if len(w.secret) > 0 {
+		mac := hmac.New(sha256.New, w.secret)
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", w.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s: unexpected status %s", w.url, resp.Status)
+	}
+	return nil
+}
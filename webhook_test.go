@@ -0,0 +1,167 @@
+package configwise
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type delivery struct {
+	payload   WebhookPayload
+	signature string
+	body      []byte
+}
+
+// webhookServer records the deliveries of a webhook.
+func webhookServer(t *testing.T, status int) (*httptest.Server, <-chan delivery) {
+	t.Helper()
+	deliveries := make(chan delivery, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		var d delivery
+		if err := json.Unmarshal(body, &d.payload); err != nil {
+			t.Error(err)
+		}
+		d.signature, d.body = r.Header.Get(SignatureHeader), body
+		deliveries <- d
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, deliveries
+}
+
+func receive(t *testing.T, deliveries <-chan delivery) delivery {
+	t.Helper()
+	select {
+	case d := <-deliveries:
+		return d
+	case <-time.After(time.Second):
+		t.Fatal("no delivery")
+		return delivery{}
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	server, deliveries := webhookServer(t, http.StatusNoContent)
+	secret := []byte("webhook secret")
+
+	c, err := NewConfigurer(
+		WithWebhook(server.URL, WithWebhookSecret(secret)),
+		WithType("yaml"),
+		WithReadInConfig([]byte("server: {host: localhost, port: 80}\ndebug: true")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		change func() error
+		source string
+		want   ChangeSummary
+	}{
+		{
+			name: "overwrite",
+			change: func() error {
+				return c.Overwrite(map[string]interface{}{"server.port": 8080, "server.tls": true})
+			},
+			source: "overwrite",
+			want:   ChangeSummary{Added: []string{"server.tls"}, Removed: []string{}, Updated: []string{"server.port"}},
+		},
+		{
+			name:   "patch",
+			change: func() error { return c.ApplyPatch([]byte(`{"debug": null}`), PatchMerge) },
+			source: "patch",
+			want:   ChangeSummary{Added: []string{}, Removed: []string{"debug"}, Updated: []string{}},
+		},
+		{
+			name:   "unset",
+			change: func() error { return c.Unset("server.port") },
+			source: "unset",
+			want:   ChangeSummary{Added: []string{}, Removed: []string{}, Updated: []string{"server.port"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.change(); err != nil {
+				t.Fatal(err)
+			}
+			d := receive(t, deliveries)
+			if d.payload.Source != tt.source {
+				t.Errorf("source is %q, want %q", d.payload.Source, tt.source)
+			}
+			if !reflect.DeepEqual(d.payload.Changes, tt.want) {
+				t.Errorf("changes are %+v, want %+v", d.payload.Changes, tt.want)
+			}
+			if d.payload.Fingerprint == "" || d.payload.Timestamp.IsZero() {
+				t.Errorf("payload without fingerprint or timestamp: %+v", d.payload)
+			}
+
+			mac := hmac.New(sha256.New, secret)
+			mac.Write(d.body)
+			if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); d.signature != want {
+				t.Errorf("signature is %q, want %q", d.signature, want)
+			}
+		})
+	}
+
+	// unchanged values are not delivered
+	if err := c.Overwrite(map[string]interface{}{"server.host": "localhost"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case d := <-deliveries:
+		t.Errorf("delivery without changes: %+v", d.payload)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestWebhookWithoutSecret(t *testing.T) {
+	server, deliveries := webhookServer(t, http.StatusOK)
+	c, err := NewConfigurer(WithWebhook(server.URL), WithConfigMap(map[string]interface{}{"a": 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Overwrite(map[string]interface{}{"a": 2}); err != nil {
+		t.Fatal(err)
+	}
+	if d := receive(t, deliveries); d.signature != "" {
+		t.Errorf("unsigned payload has the signature %q", d.signature)
+	}
+}
+
+func TestWebhookErrorHandler(t *testing.T) {
+	server, deliveries := webhookServer(t, http.StatusInternalServerError)
+	errs := make(chan error, 1)
+	c, err := NewConfigurer(
+		WithWebhook(server.URL, WithWebhookErrorHandler(func(err error) { errs <- err })),
+		WithConfigMap(map[string]interface{}{"a": 1}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Overwrite(map[string]interface{}{"a": 2}); err != nil {
+		t.Fatal(err)
+	}
+	receive(t, deliveries)
+
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "500") {
+			t.Errorf("error is %v, want the status of the response", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the error handler was not called")
+	}
+}
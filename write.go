@@ -0,0 +1,106 @@
+package configwise
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// WriteConfig writes the effective configuration, merged from all sources
+// with variables expanded and overrides applied, to the file as "yaml",
+// "json" or "toml". Without format the extension of the path is used.
+// Secrets are stored according to the secret policy. The file is replaced
+// atomically and readable by the owner only.
+func (cfg *configurer) WriteConfig(path, format string) error {
+	return cfg.writeConfig("", path, format)
+}
+
+// writeConfig writes the section stored under root, or all settings for an empty root.
+func (cfg *configurer) writeConfig(root, path, format string) error {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+
+	data, err := encodeConfig(format, cfg.redactSection(root))
+	if err != nil {
+		return &Error{Op: OpWriteConfig, Err: err}
+	}
+	if err = writeFile(path, data, 0o600); err != nil {
+		return &Error{Op: OpWriteConfig, Err: err}
+	}
+	return nil
+}
+
+// encodeConfig renders a configuration tree in the format.
+func encodeConfig(format string, tree map[string]interface{}) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case "yaml", "yml":
+		return yaml.Marshal(tree)
+	case "json":
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(tree); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case "toml":
+		// TOML has no null
+		return toml.Marshal(withoutNil(tree))
+	default:
+		return nil, fmt.Errorf("unsupported config type %q", format)
+	}
+}
+
+// withoutNil returns a copy of the tree without nil values of sections.
+func withoutNil(tree map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(tree))
+	for key, value := range tree {
+		if value != nil {
+			out[key] = withoutNilValue(value)
+		}
+	}
+	return out
+}
+
+func withoutNilValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return withoutNil(v)
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = withoutNilValue(val)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+// writeFile replaces the file atomically with the data.
+func writeFile(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(perm)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
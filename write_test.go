@@ -0,0 +1,121 @@
+package configwise
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const writeConfig = `
+server: {host: localhost, port: "${CONFIGWISE_TEST_PORT:-80}"}
+db: {password: s3cret}
+empty: null
+`
+
+func TestWriteConfig(t *testing.T) {
+	c, err := NewConfigurer(WithSecretKeys("db.password"), WithType("yaml"), WithReadInConfig([]byte(writeConfig)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Overwrite(map[string]interface{}{"server.tls": true}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		file   string
+		format string
+		want   map[string]interface{}
+	}{
+		{
+			name: "yaml",
+			file: "config.yaml",
+			want: map[string]interface{}{
+				"server": map[string]interface{}{"host": "localhost", "port": "80", "tls": true},
+				"db":     map[string]interface{}{"password": RedactedValue},
+				"empty":  nil,
+			},
+		},
+		{
+			name:   "json with a format",
+			file:   "config.conf",
+			format: "json",
+			want: map[string]interface{}{
+				"server": map[string]interface{}{"host": "localhost", "port": "80", "tls": true},
+				"db":     map[string]interface{}{"password": RedactedValue},
+				"empty":  nil,
+			},
+		},
+		{
+			name: "toml without nulls",
+			file: "config.toml",
+			want: map[string]interface{}{
+				"server": map[string]interface{}{"host": "localhost", "port": "80", "tls": true},
+				"db":     map[string]interface{}{"password": RedactedValue},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), tt.file)
+			if err := c.WriteConfig(file, tt.format); err != nil {
+				t.Fatal(err)
+			}
+
+			info, err := os.Stat(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if perm := info.Mode().Perm(); perm != 0o600 {
+				t.Errorf("permissions are %v, want 0600", perm)
+			}
+
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			format := tt.format
+			if format == "" {
+				format = strings.TrimPrefix(filepath.Ext(file), ".")
+			}
+			written, err := NewConfigurer(WithType(format), WithReadInConfig(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := written.Settings(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("written settings are %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteConfigErrors(t *testing.T) {
+	c, err := NewConfigurer(WithConfigMap(map[string]interface{}{"a": 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	tests := []struct {
+		name   string
+		path   string
+		format string
+		err    string
+	}{
+		{name: "unsupported format", path: filepath.Join(dir, "config.ini"), err: `configurer: write config -> unsupported config type "ini"`},
+		{name: "missing directory", path: filepath.Join(dir, "missing", "config.yaml"), err: "configurer: write config -> "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.WriteConfig(tt.path, tt.format)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error is %v, want %q", err, tt.err)
+			}
+		})
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("files %v are left behind", entries)
+	}
+}
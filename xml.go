@@ -0,0 +1,106 @@
+package configwise
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// xmlTextKey is the key of the text of elements with attributes or children,
+// which is no valid XML name, so it never collides with them.
+const xmlTextKey = "#text"
+
+// decodeXML parses an XML document into a configuration tree. The children
+// of the root element are the top-level keys. Attributes and child elements
+// become keys of their element, repeated elements become lists and the text
+// of elements without attributes and children becomes their value, e.g.
+//
+//	<config>
+//	  <http port="8080"><host>a</host><host>b</host></http>
+//	</config>
+//
+// sets http.port to "8080" and http.host to ["a", "b"]. The text of elements
+// with attributes or children is stored under the key "#text", e.g.
+// `mapstructure:"#text"`. Namespaces are ignored and values are kept as strings.
+func decodeXML(data []byte) (map[string]interface{}, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return make(map[string]interface{}), nil
+	}
+
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("xml: missing root element")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			root, err := decodeXMLElement(d, start)
+			if err != nil {
+				return nil, err
+			}
+			if tree, ok := root.(map[string]interface{}); ok {
+				return tree, nil
+			}
+			return make(map[string]interface{}), nil
+		}
+	}
+}
+
+// decodeXMLElement decodes the element up to its end into a section, or a
+// string for elements with text only.
+func decodeXMLElement(d *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	section := make(map[string]interface{})
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		section[attr.Name.Local] = attr.Value
+	}
+
+	var text strings.Builder
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			// the decoder reports the end of open elements as syntax error
+			var syntax *xml.SyntaxError
+			if errors.Is(err, io.EOF) || errors.As(err, &syntax) && syntax.Msg == "unexpected EOF" {
+				err = fmt.Errorf("xml: element <%s> is not closed", start.Name.Local)
+			}
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			child, err := decodeXMLElement(d, t)
+			if err != nil {
+				return nil, err
+			}
+			name := t.Name.Local
+			switch existing := section[name].(type) {
+			case nil:
+				section[name] = child
+			case []interface{}:
+				section[name] = append(existing, child)
+			default:
+				section[name] = []interface{}{existing, child}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			value := strings.TrimSpace(text.String())
+			if len(section) == 0 {
+				return value, nil
+			}
+			if value != "" {
+				section[xmlTextKey] = value
+			}
+			return section, nil
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package configwise
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeXML(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want map[string]interface{}
+		err  string
+	}{
+		{
+			name: "elements and attributes",
+			doc: `<?xml version="1.0"?>
+<config>
+  <!-- comment -->
+  <http port="8080"><host>a</host><host>b</host></http>
+  <name> app </name>
+</config>`,
+			want: map[string]interface{}{
+				"http": map[string]interface{}{"port": "8080", "host": []interface{}{"a", "b"}},
+				"name": "app",
+			},
+		},
+		{
+			name: "text of elements with attributes",
+			doc:  `<config><timeout unit="s">30</timeout></config>`,
+			want: map[string]interface{}{
+				"timeout": map[string]interface{}{"unit": "s", "#text": "30"},
+			},
+		},
+		{
+			name: "text next to a value attribute and child",
+			doc:  `<config><limit value="10">soft<value>20</value></limit></config>`,
+			want: map[string]interface{}{
+				"limit": map[string]interface{}{"value": []interface{}{"10", "20"}, "#text": "soft"},
+			},
+		},
+		{
+			name: "repeated sections",
+			doc:  `<config><server name="a"/><server name="b"/><server name="c"/></config>`,
+			want: map[string]interface{}{
+				"server": []interface{}{
+					map[string]interface{}{"name": "a"},
+					map[string]interface{}{"name": "b"},
+					map[string]interface{}{"name": "c"},
+				},
+			},
+		},
+		{
+			name: "namespaces",
+			doc:  `<config xmlns="urn:app" xmlns:x="urn:x"><x:port>80</x:port></config>`,
+			want: map[string]interface{}{"port": "80"},
+		},
+		{
+			name: "cdata",
+			doc:  `<config><script><![CDATA[a < b]]></script></config>`,
+			want: map[string]interface{}{"script": "a < b"},
+		},
+		{
+			name: "empty root",
+			doc:  `<config/>`,
+			want: map[string]interface{}{},
+		},
+		{
+			name: "empty document",
+			doc:  " \n",
+			want: map[string]interface{}{},
+		},
+		{
+			name: "missing root",
+			doc:  `<?xml version="1.0"?>`,
+			err:  "xml: missing root element",
+		},
+		{
+			name: "unclosed element",
+			doc:  `<config><port>80</port>`,
+			err:  "xml: element <config> is not closed",
+		},
+		{
+			name: "mismatched element",
+			doc:  `<config><port>80</host></config>`,
+			err:  "element <port> closed by </host>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeXML([]byte(tt.doc))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error is %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tree is %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}